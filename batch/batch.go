@@ -0,0 +1,145 @@
+package batch
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/output"
+	"io"
+	"sync"
+	"text/tabwriter"
+)
+
+// DefaultWorkers limits the number of users which are processed at the same time.
+const DefaultWorkers = 4
+
+// Summary contains the totals of a processed user.
+type Summary struct {
+	Days     int
+	Hours    float64
+	Booked   float64
+	Target   float64
+	Overtime float64
+	// Output contains the file which the time slots were written to.
+	Output string
+}
+
+// Result contains the summary of a user or the reason why processing failed.
+type Result struct {
+	User    string
+	Summary Summary
+	Err     error
+}
+
+// ProcessFunc runs the whole pipeline for a single user.
+type ProcessFunc func(user User) (Summary, error)
+
+// Run processes the users with at most the given number of workers at the same time and returns their results in the
+// order of the users. A failing user does not stop the others.
+func Run(users []User, workers int, process ProcessFunc) ([]Result, error) {
+	if workers < 1 {
+		return nil, errors.Errorf("invalid number of %d workers (expected at least 1)", workers)
+	}
+
+	results := make([]Result, len(users))
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+	for worker := 0; worker < workers && worker < len(users); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indices {
+				results[index] = runUser(users[index], process)
+			}
+		}()
+	}
+
+	for index := range users {
+		indices <- index
+	}
+	close(indices)
+	wg.Wait()
+
+	return results, nil
+}
+
+// runUser turns a panic of a single user into an error so that the batch continues.
+func runUser(user User, process ProcessFunc) (result Result) {
+	result.User = user.Name
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			result.Summary = Summary{}
+			result.Err = errors.Errorf("unexpected error: %v", recovered)
+		}
+	}()
+
+	result.Summary, result.Err = process(user)
+
+	return result
+}
+
+// Failed returns the number of users which could not be processed.
+func Failed(results []Result) int {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+
+	return failed
+}
+
+// WriteSummary writes a line per user and the totals of all successful users. The output column of a failed user
+// contains the error.
+//
+// Example:
+//  User   Days  Hours  Booked  Target  Overtime  Output
+//  alice  5     40.00  40.00   40.00   0.00      out/alice.txt
+//  bob    -     -      -       -       -         failed: could not read Redmine data
+//  Total  5     40.00  40.00   40.00   0.00      1 of 2 users failed
+func WriteSummary(w io.Writer, results []Result) error {
+	hours := func(value float64) string {
+		return output.FormatHours(value, ".")
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, err := fmt.Fprintln(table, "User\tDays\tHours\tBooked\tTarget\tOvertime\tOutput")
+	if err != nil {
+		return err
+	}
+
+	total := Summary{}
+	for _, result := range results {
+		if result.Err != nil {
+			_, err = fmt.Fprintf(table, "%s\t-\t-\t-\t-\t-\tfailed: %v\n", result.User, result.Err)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		summary := result.Summary
+		total.Days += summary.Days
+		total.Hours += summary.Hours
+		total.Booked += summary.Booked
+		total.Target += summary.Target
+		total.Overtime += summary.Overtime
+		_, err = fmt.Fprintf(table, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", result.User, summary.Days, hours(summary.Hours),
+			hours(summary.Booked), hours(summary.Target), hours(summary.Overtime), summary.Output)
+		if err != nil {
+			return err
+		}
+	}
+
+	status := "all users processed"
+	if failed := Failed(results); failed > 0 {
+		status = fmt.Sprintf("%d of %d users failed", failed, len(results))
+	}
+	_, err = fmt.Fprintf(table, "Total\t%d\t%s\t%s\t%s\t%s\t%s\n", total.Days, hours(total.Hours), hours(total.Booked),
+		hours(total.Target), hours(total.Overtime), status)
+	if err != nil {
+		return err
+	}
+
+	return table.Flush()
+}
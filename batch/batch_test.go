@@ -0,0 +1,96 @@
+package batch
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	users := []User{{Name: "alice"}, {Name: "bob"}, {Name: "carol"}, {Name: "dave"}, {Name: "eve"}}
+
+	t.Run("should keep the order of the users and report failures without stopping", func(t *testing.T) {
+		process := func(user User) (Summary, error) {
+			switch user.Name {
+			case "bob":
+				return Summary{}, errors.New("could not read Redmine data")
+			case "dave":
+				panic("out of range")
+			}
+			return Summary{Days: 1, Hours: 8, Output: user.Name + ".txt"}, nil
+		}
+
+		// when
+		actual, err := Run(users, 2, process)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, actual, 5)
+		assert.Equal(t, "alice", actual[0].User)
+		assert.Equal(t, "alice.txt", actual[0].Summary.Output)
+		assert.EqualError(t, actual[1].Err, "could not read Redmine data")
+		assert.EqualError(t, actual[3].Err, "unexpected error: out of range")
+		assert.Equal(t, "eve.txt", actual[4].Summary.Output)
+		assert.Equal(t, 2, Failed(actual))
+	})
+	t.Run("should not run more users at the same time than workers", func(t *testing.T) {
+		mutex := sync.Mutex{}
+		running, maximum := 0, 0
+		process := func(user User) (Summary, error) {
+			mutex.Lock()
+			running++
+			if running > maximum {
+				maximum = running
+			}
+			mutex.Unlock()
+			defer func() {
+				mutex.Lock()
+				running--
+				mutex.Unlock()
+			}()
+			return Summary{}, nil
+		}
+
+		// when
+		_, err := Run(users, 3, process)
+
+		// then
+		require.NoError(t, err)
+		assert.LessOrEqual(t, maximum, 3)
+	})
+	t.Run("should fail without workers", func(t *testing.T) {
+		// when
+		_, err := Run(users, 0, nil)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid number of 0 workers")
+	})
+}
+
+func TestWriteSummary(t *testing.T) {
+	t.Run("should write a line per user and the totals", func(t *testing.T) {
+		results := []Result{
+			{User: "alice", Summary: Summary{Days: 5, Hours: 40, Booked: 40, Target: 40, Output: "out/alice.txt"}},
+			{User: "bob", Err: errors.New("could not read Redmine data")},
+			{User: "carol", Summary: Summary{Days: 2, Hours: 10, Booked: 9.5, Target: 16, Overtime: -6, Output: "out/carol.txt"}},
+		}
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := WriteSummary(buffer, results)
+
+		// then
+		require.NoError(t, err)
+		expected := `User   Days  Hours  Booked  Target  Overtime  Output
+alice  5     40.00  40.00   40.00   0.00      out/alice.txt
+bob    -     -      -       -       -         failed: could not read Redmine data
+carol  2     10.00  9.50    16.00   -6.00     out/carol.txt
+Total  7     50.00  49.50   56.00   -6.00     1 of 3 users failed
+`
+		assert.Equal(t, expected, buffer.String())
+	})
+}
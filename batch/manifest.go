@@ -0,0 +1,174 @@
+package batch
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/config"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SummaryName names the combined summary in the output directory. No user may have this name.
+const SummaryName = "summary"
+
+// inputExtensions contains the file extensions which are read when scanning a directory.
+var inputExtensions = map[string]bool{".csv": true, ".xlsx": true, ".ods": true}
+
+// Manifest lists the team members whose timesheets are processed together.
+//
+// Example:
+//  users:
+//    - name: alice
+//      files: [alice/*.csv]
+//      cruncher:
+//        lunchStart: "12:30"
+//    - name: bob
+//      redmineUserId: "42"
+//      targetHoursPerDay: 6
+type Manifest struct {
+	Users []User `yaml:"users"`
+}
+
+// User contains the inputs and the schedule of a team member. Options left empty fall back to the options of the
+// batch command.
+type User struct {
+	// Name names the output file of the user and must be unique.
+	Name string `yaml:"name"`
+	// Files contains Redmine exports or glob patterns, relative to the manifest.
+	Files []string `yaml:"files,omitempty"`
+	// RedmineUserID reads the time entries of this Redmine user from the REST API.
+	RedmineUserID     string          `yaml:"redmineUserId,omitempty"`
+	Cruncher          config.Cruncher `yaml:"cruncher,omitempty"`
+	Calendar          config.Calendar `yaml:"calendar,omitempty"`
+	TargetHoursPerDay *float64        `yaml:"targetHoursPerDay,omitempty"`
+}
+
+// Load reads the users of a manifest file or scans a directory for them.
+func Load(path string) ([]User, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read batch input %s", path)
+	}
+	if info.IsDir() {
+		return ScanDir(path)
+	}
+
+	return LoadManifest(path)
+}
+
+// LoadManifest reads the users of a YAML manifest. Relative file names and holiday files are resolved against the
+// directory of the manifest.
+func LoadManifest(path string) ([]User, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read manifest %s", path)
+	}
+
+	manifest := Manifest{}
+	err = yaml.UnmarshalStrict(content, &manifest)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse manifest %s", path)
+	}
+
+	dir := filepath.Dir(path)
+	for index := range manifest.Users {
+		user := &manifest.Users[index]
+		for fileIndex, file := range user.Files {
+			user.Files[fileIndex] = resolvePath(dir, file)
+		}
+		user.Calendar.HolidayFile = resolvePath(dir, user.Calendar.HolidayFile)
+	}
+
+	err = validate(manifest.Users)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid manifest %s", path)
+	}
+
+	return manifest.Users, nil
+}
+
+// resolvePath returns relative paths joined to the directory and keeps empty and absolute paths.
+func resolvePath(dir string, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
+
+// ScanDir returns a user per Redmine export in the directory, named after the file, and a user per subdirectory with
+// all exports in it, named after the subdirectory.
+func ScanDir(dir string) ([]User, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read batch directory %s", dir)
+	}
+
+	users := []User{}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !entry.IsDir() {
+			if isInput(entry.Name()) {
+				users = append(users, User{Name: strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name())), Files: []string{path}})
+			}
+			continue
+		}
+
+		files, err := ioutil.ReadDir(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read batch directory %s", path)
+		}
+		user := User{Name: entry.Name()}
+		for _, file := range files {
+			if !file.IsDir() && isInput(file.Name()) {
+				user.Files = append(user.Files, filepath.Join(path, file.Name()))
+			}
+		}
+		if len(user.Files) > 0 {
+			users = append(users, user)
+		}
+	}
+	sort.SliceStable(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+
+	err = validate(users)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid batch directory %s", dir)
+	}
+
+	return users, nil
+}
+
+func isInput(filename string) bool {
+	return inputExtensions[strings.ToLower(filepath.Ext(filename))]
+}
+
+// validate checks that the users have unique names which can be used as file names, do not collide with the combined
+// summary and have at least one input.
+func validate(users []User) error {
+	if len(users) == 0 {
+		return errors.New("no users found")
+	}
+
+	seen := map[string]bool{}
+	for _, user := range users {
+		if user.Name == "" || user.Name == "." || user.Name == ".." || strings.ContainsAny(user.Name, `/\`) {
+			return errors.Errorf("invalid user name '%s' (expected a name without path separators)", user.Name)
+		}
+		if strings.EqualFold(user.Name, SummaryName) {
+			return errors.Errorf("invalid user name '%s' (reserved for the combined summary)", user.Name)
+		}
+		if seen[user.Name] {
+			return errors.Errorf("user %s is listed more than once", user.Name)
+		}
+		seen[user.Name] = true
+
+		if len(user.Files) == 0 && user.RedmineUserID == "" {
+			return errors.Errorf("user %s has neither files nor a Redmine user ID", user.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package batch
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "batch-")
+	defer os.RemoveAll(dir)
+
+	t.Run("should read the users of a manifest", func(t *testing.T) {
+		path := filepath.Join(dir, "team.yaml")
+		_ = ioutil.WriteFile(path, []byte(`users:
+  - name: alice
+    files: [alice/*.csv, /tmp/extra.csv]
+    cruncher:
+      lunchStart: "12:30"
+    calendar:
+      holidayFile: holidays/berlin.txt
+  - name: bob
+    redmineUserId: "42"
+    targetHoursPerDay: 6
+    calendar:
+      holidayFile: /etc/holidays.txt
+`), 0600)
+
+		// when
+		actual, err := Load(path)
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, actual, 2)
+		assert.Equal(t, []string{filepath.Join(dir, "alice/*.csv"), "/tmp/extra.csv"}, actual[0].Files)
+		assert.Equal(t, "12:30", actual[0].Cruncher.LunchStart)
+		assert.Equal(t, filepath.Join(dir, "holidays/berlin.txt"), actual[0].Calendar.HolidayFile)
+		assert.Equal(t, "/etc/holidays.txt", actual[1].Calendar.HolidayFile)
+		assert.Equal(t, "42", actual[1].RedmineUserID)
+		assert.Equal(t, 6.0, *actual[1].TargetHoursPerDay)
+	})
+	t.Run("should fail for users without input", func(t *testing.T) {
+		path := filepath.Join(dir, "empty.yaml")
+		_ = ioutil.WriteFile(path, []byte("users:\n  - name: alice\n"), 0600)
+
+		// when
+		_, err := Load(path)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "user alice has neither files nor a Redmine user ID")
+	})
+	t.Run("should fail for duplicate users", func(t *testing.T) {
+		path := filepath.Join(dir, "duplicate.yaml")
+		_ = ioutil.WriteFile(path, []byte("users:\n  - name: alice\n    redmineUserId: '1'\n  - name: alice\n    redmineUserId: '2'\n"), 0600)
+
+		// when
+		_, err := Load(path)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "user alice is listed more than once")
+	})
+	t.Run("should fail for names with path separators", func(t *testing.T) {
+		path := filepath.Join(dir, "path.yaml")
+		_ = ioutil.WriteFile(path, []byte("users:\n  - name: ../alice\n    redmineUserId: '1'\n"), 0600)
+
+		// when
+		_, err := Load(path)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid user name '../alice'")
+	})
+	t.Run("should fail for the name of the combined summary", func(t *testing.T) {
+		path := filepath.Join(dir, "summary.yaml")
+		_ = ioutil.WriteFile(path, []byte("users:\n  - name: Summary\n    redmineUserId: '1'\n"), 0600)
+
+		// when
+		_, err := Load(path)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid user name 'Summary' (reserved for the combined summary)")
+	})
+	t.Run("should fail for unknown options", func(t *testing.T) {
+		path := filepath.Join(dir, "unknown.yaml")
+		_ = ioutil.WriteFile(path, []byte("users:\n  - name: alice\n    lunch: 30\n"), 0600)
+
+		// when
+		_, err := Load(path)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not parse manifest")
+	})
+}
+
+func TestScanDir(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "batch-")
+	defer os.RemoveAll(dir)
+	_ = os.Mkdir(filepath.Join(dir, "carol"), 0700)
+	_ = os.Mkdir(filepath.Join(dir, "empty"), 0700)
+	for _, name := range []string{"bob.csv", "alice.XLSX", "notes.txt", "carol/week-18.csv", "carol/week-19.ods"} {
+		_ = ioutil.WriteFile(filepath.Join(dir, name), []byte{}, 0600)
+	}
+
+	t.Run("should find a user per file and per subdirectory", func(t *testing.T) {
+		// when
+		actual, err := Load(dir)
+
+		// then
+		require.NoError(t, err)
+		expected := []User{
+			{Name: "alice", Files: []string{filepath.Join(dir, "alice.XLSX")}},
+			{Name: "bob", Files: []string{filepath.Join(dir, "bob.csv")}},
+			{Name: "carol", Files: []string{filepath.Join(dir, "carol", "week-18.csv"), filepath.Join(dir, "carol", "week-19.ods")}},
+		}
+		assert.Equal(t, expected, actual)
+	})
+	t.Run("should fail for directories without exports", func(t *testing.T) {
+		// when
+		_, err := ScanDir(filepath.Join(dir, "empty"))
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no users found")
+	})
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/batch"
+	"github.com/ppxl/sagemine/output"
+	"github.com/ppxl/sagemine/report"
+	"github.com/urfave/cli/v2"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	flagOutDirLong  = "out-dir"
+	flagWorkersLong = "workers"
+)
+
+// batchSummaryFile contains the combined summary in the output directory.
+const batchSummaryFile = batch.SummaryName + ".txt"
+
+func batchCommand() *cli.Command {
+	flags := append(runFlags(),
+		&cli.StringFlag{
+			Name:  flagOutDirLong,
+			Usage: "directory which receives one output file per user and the combined " + batchSummaryFile,
+		},
+		&cli.IntFlag{
+			Name:  flagWorkersLong,
+			Usage: "number of users which are processed at the same time (optional)",
+			Value: batch.DefaultWorkers,
+		},
+	)
+
+	return &cli.Command{
+		Name: "batch",
+		Usage: "process the timesheets of several users: a directory with one export or subdirectory per user, or a " +
+			"YAML manifest with the inputs and schedule of each user",
+		Action:    doCliBatch,
+		ArgsUsage: "directory or manifest",
+		Flags:     flags,
+	}
+}
+
+func doCliBatch(cliCtx *cli.Context) error {
+	if cliCtx.Args().Len() != 1 {
+		return errors.New("batch needs exactly one directory or manifest")
+	}
+	outDir := cliCtx.String(flagOutDirLong)
+	if outDir == "" {
+		return errors.Errorf("batch needs the directory for the results, f. i. --%s out", flagOutDirLong)
+	}
+
+	args, err := buildRunArgs(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	return doBatch(os.Stdout, args, cliCtx.Args().First(), outDir, cliCtx.Int(flagWorkersLong))
+}
+
+// doBatch runs the whole pipeline for each user of the directory or manifest, writes the time slots of each user into
+// the output directory and the combined summary to w and the output directory. It fails if a user failed, but only
+// after all users were processed.
+func doBatch(w io.Writer, args runArgs, source string, outDir string, workers int) error {
+	users, err := batch.Load(source)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(outDir, 0700)
+	if err != nil {
+		return errors.Wrapf(err, "could not create output directory %s", outDir)
+	}
+
+	results, err := batch.Run(users, workers, func(user batch.User) (batch.Summary, error) {
+		return processUser(args, user, outDir)
+	})
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			log.Errorf("could not process user %s: %v", result.User, result.Err)
+		}
+	}
+
+	summaryPath := filepath.Join(outDir, batchSummaryFile)
+	summaryFile, err := os.Create(summaryPath)
+	if err != nil {
+		return errors.Wrapf(err, "could not create summary %s", summaryPath)
+	}
+	defer summaryFile.Close()
+
+	err = batch.WriteSummary(io.MultiWriter(w, summaryFile), results)
+	if err != nil {
+		return errors.Wrap(err, "error while writing batch summary")
+	}
+
+	if failed := batch.Failed(results); failed > 0 {
+		return errors.Errorf("%d of %d users failed", failed, len(results))
+	}
+
+	return nil
+}
+
+// processUser crunches the work time of a user and writes it into a file named after the user.
+func processUser(args runArgs, user batch.User, outDir string) (batch.Summary, error) {
+	userArgs, err := userRunArgs(args, user)
+	if err != nil {
+		return batch.Summary{}, err
+	}
+
+	pipeline, result, err := processRedmineData(userArgs)
+	if err != nil {
+		return batch.Summary{}, err
+	}
+
+	path := filepath.Join(outDir, user.Name+outputExtension(userArgs.outputFormat))
+	file, err := os.Create(path)
+	if err != nil {
+		return batch.Summary{}, errors.Wrapf(err, "could not create output file %s", path)
+	}
+	defer file.Close()
+
+	err = pipeline.Write(context.Background(), file, result.Crunched)
+	if err != nil {
+		return batch.Summary{}, err
+	}
+
+	summary, err := report.New(result.Data, result.Crunched, report.Options{TargetHoursPerDay: userArgs.targetHours, Days: pipeline.Calendar()})
+	if err != nil {
+		return batch.Summary{}, err
+	}
+
+	return batch.Summary{
+		Days:     len(summary.Days),
+		Hours:    summary.Total.Total,
+		Booked:   summary.Total.Booked,
+		Target:   summary.Total.Target,
+		Overtime: summary.Total.Overtime,
+		Output:   path,
+	}, nil
+}
+
+// userRunArgs returns the run arguments with the inputs and the schedule of the user. The Redmine REST API is only
+// read for users with a Redmine user ID. Explanations are not written because the users run at the same time.
+func userRunArgs(args runArgs, user batch.User) (runArgs, error) {
+	result := args
+	result.explain = false
+
+	filenames, err := expandFilenames(user.Files)
+	if err != nil {
+		return runArgs{}, err
+	}
+	result.filenames = filenames
+
+	if user.RedmineUserID != "" {
+		if args.redmine.url == "" {
+			return runArgs{}, errors.Errorf("reading Redmine user %s needs --%s", user.RedmineUserID, flagRedmineURLLong)
+		}
+		result.redmine.userID = user.RedmineUserID
+	} else {
+		result.redmine.url = ""
+	}
+
+	schedule := user.Cruncher
+	if schedule.Name != "" {
+		result.cruncherName = schedule.Name
+	}
+	if schedule.LunchBreakInMin != nil {
+		result.lunchBreakInMin = *schedule.LunchBreakInMin
+	}
+	if schedule.LunchStart != "" {
+		result.lunchStartTime = schedule.LunchStart
+	}
+	if schedule.ChunkMinutes != nil {
+		result.chunkMinutes = *schedule.ChunkMinutes
+	}
+	if schedule.Interleave != "" {
+		result.interleaveMode = schedule.Interleave
+	}
+	if len(schedule.Anchors) > 0 {
+		result.anchors = schedule.Anchors
+	}
+	if schedule.MinSlotMinutes != nil {
+		result.minSlotMinutes = *schedule.MinSlotMinutes
+	}
+	if schedule.MergeInto != "" {
+		result.mergeInto = schedule.MergeInto
+	}
+	if schedule.Consolidate != nil {
+		result.consolidate = *schedule.Consolidate
+	}
+
+	if user.Calendar.HolidayState != "" {
+		result.holidayState = user.Calendar.HolidayState
+	}
+	if user.Calendar.HolidayFile != "" {
+		result.holidayFile = user.Calendar.HolidayFile
+	}
+	if user.Calendar.NonWorkingDays != "" {
+		result.nonWorkingDays = user.Calendar.NonWorkingDays
+	}
+	if user.TargetHoursPerDay != nil {
+		result.targetHours = *user.TargetHoursPerDay
+	}
+
+	return result, nil
+}
+
+// outputExtension returns the file extension of the output format.
+func outputExtension(format string) string {
+	switch strings.ToLower(format) {
+	case output.FormatCSV:
+		return ".csv"
+	case output.FormatJSON:
+		return ".json"
+	default:
+		return ".txt"
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"github.com/ppxl/sagemine/batch"
+	"github.com/ppxl/sagemine/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_doBatch(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "batch-")
+	defer os.RemoveAll(dir)
+	team := filepath.Join(dir, "team")
+	_ = os.Mkdir(team, 0700)
+	_ = ioutil.WriteFile(filepath.Join(team, "alice.csv"), []byte(`Anforderungspipeline;2021-05-03;2021-05-04
+Pipeline A;7,50;6,00
+`), 0600)
+	_ = ioutil.WriteFile(filepath.Join(team, "bob.csv"), []byte(`Anforderungspipeline;2021-05-03
+Pipeline A;8,00
+`), 0600)
+	args := runArgs{
+		lunchBreakInMin:  60,
+		csvDelimiter:     ";",
+		decimalDelimiter: ",",
+		targetHours:      8,
+		outputFormat:     "csv",
+	}
+
+	t.Run("should write an output per user and the summary", func(t *testing.T) {
+		outDir := filepath.Join(dir, "out")
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := doBatch(buffer, args, team, outDir, 2)
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, buffer.String(), "alice  2     13.50  13.50   16.00   -2.50     "+filepath.Join(outDir, "alice.csv")+"\n")
+		assert.Contains(t, buffer.String(), "bob    1     8.00   8.00    8.00    0.00      "+filepath.Join(outDir, "bob.csv")+"\n")
+		assert.Contains(t, buffer.String(), "all users processed\n")
+		aliceOutput, err := ioutil.ReadFile(filepath.Join(outDir, "alice.csv"))
+		require.NoError(t, err)
+		assert.Contains(t, string(aliceOutput), "Pipeline A-joined;2021-05-04;13:00;15:00;2.00;\n")
+		summary, err := ioutil.ReadFile(filepath.Join(outDir, batchSummaryFile))
+		require.NoError(t, err)
+		assert.Equal(t, buffer.String(), string(summary))
+	})
+	t.Run("should process the other users if one fails", func(t *testing.T) {
+		_ = ioutil.WriteFile(filepath.Join(team, "carol.csv"), []byte(`Anforderungspipeline;3rd of May
+Pipeline A;8,00
+`), 0600)
+		defer os.Remove(filepath.Join(team, "carol.csv"))
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := doBatch(buffer, args, team, filepath.Join(dir, "failed"), 3)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "1 of 3 users failed")
+		assert.Contains(t, buffer.String(), "carol  -     -      -       -       -         failed: ")
+		assert.FileExists(t, filepath.Join(dir, "failed", "alice.csv"))
+		assert.FileExists(t, filepath.Join(dir, "failed", "bob.csv"))
+	})
+}
+
+func Test_userRunArgs(t *testing.T) {
+	args := runArgs{
+		lunchBreakInMin: 60,
+		targetHours:     8,
+		filenames:       []string{"team"},
+		explain:         true,
+		redmine:         redmineArgs{url: "https://redmine.example.com", userID: "me"},
+	}
+
+	t.Run("should apply the schedule of the user", func(t *testing.T) {
+		lunchBreak := 30
+		targetHours := 6.0
+		user := batch.User{
+			Name:              "bob",
+			RedmineUserID:     "42",
+			Cruncher:          config.Cruncher{LunchBreakInMin: &lunchBreak, LunchStart: "12:30", Name: "interleaved"},
+			Calendar:          config.Calendar{HolidayState: "BY"},
+			TargetHoursPerDay: &targetHours,
+		}
+
+		// when
+		actual, err := userRunArgs(args, user)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 30, actual.lunchBreakInMin)
+		assert.Equal(t, "12:30", actual.lunchStartTime)
+		assert.Equal(t, "interleaved", actual.cruncherName)
+		assert.Equal(t, "BY", actual.holidayState)
+		assert.Equal(t, 6.0, actual.targetHours)
+		assert.Equal(t, "42", actual.redmine.userID)
+		assert.Empty(t, actual.filenames)
+		assert.False(t, actual.explain)
+	})
+	t.Run("should not read the Redmine API for users with files only", func(t *testing.T) {
+		// when
+		actual, err := userRunArgs(args, batch.User{Name: "alice", Files: []string{"alice.csv"}})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alice.csv"}, actual.filenames)
+		assert.Empty(t, actual.redmine.url)
+		assert.Equal(t, 60, actual.lunchBreakInMin)
+	})
+	t.Run("should fail for Redmine users without URL", func(t *testing.T) {
+		// when
+		_, err := userRunArgs(runArgs{}, batch.User{Name: "bob", RedmineUserID: "42"})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "reading Redmine user 42 needs --redmine-url")
+	})
+}
@@ -0,0 +1,127 @@
+package calendar
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"strings"
+	"sync"
+	"time"
+)
+
+const recurringDateLayout = "01-02"
+
+// Calendar decides whether a date is a working day. Weekends, the public holidays of a German state and custom
+// holidays are considered as non-working days. Once all custom holidays are added, a calendar may be used
+// concurrently, f. i. by the requests of a server.
+type Calendar struct {
+	state string
+	// customHolidays maps either a date (2021-12-24) or a recurring date (12-24) to the name of the holiday
+	customHolidays map[string]string
+	// holidaysByYear caches the public holidays and is guarded by the mutex
+	holidaysByYear map[int]map[string]string
+	mutex          sync.Mutex
+}
+
+// New creates a calendar with the public holidays of the given German state (f. i. "BY"). An empty state only
+// considers nationwide holidays.
+func New(state string) (*Calendar, error) {
+	state = strings.ToUpper(state)
+	if state != "" && !isKnownState(state) {
+		return nil, errors.Errorf("unknown German state '%s' (known states: %s)", state, strings.Join(states, ", "))
+	}
+
+	return &Calendar{
+		state:          state,
+		customHolidays: map[string]string{},
+		holidaysByYear: map[int]map[string]string{},
+	}, nil
+}
+
+// AddHoliday adds a custom holiday. The date is either a single date (2021-12-24) or a date recurring every year
+// (12-24).
+func (c *Calendar) AddHoliday(date, name string) error {
+	_, errDate := time.Parse(core.DateLayout, date)
+	_, errRecurring := time.Parse(recurringDateLayout, date)
+	if errDate != nil && errRecurring != nil {
+		return errors.Errorf("holiday date '%s' must look like YYYY-MM-DD or MM-DD", date)
+	}
+
+	c.customHolidays[date] = name
+	return nil
+}
+
+// Holiday returns the name of the holiday at the given date, if any.
+func (c *Calendar) Holiday(date time.Time) (string, bool) {
+	if name, ok := c.customHolidays[date.Format(core.DateLayout)]; ok {
+		return name, true
+	}
+	if name, ok := c.customHolidays[date.Format(recurringDateLayout)]; ok {
+		return name, true
+	}
+
+	name, ok := c.publicHolidays(date.Year())[date.Format(core.DateLayout)]
+	return name, ok
+}
+
+// IsWorkingDay returns false for weekends and holidays.
+func (c *Calendar) IsWorkingDay(date time.Time) bool {
+	_, reason := c.nonWorkingReason(date)
+	return !reason
+}
+
+// Describe returns why the given date of the format YYYY-MM-DD is no working day, f. i. "Saturday" or "Pfingstmontag".
+// Keys which are no dates are always working days.
+func (c *Calendar) Describe(date string) (string, bool) {
+	parsed, err := time.Parse(core.DateLayout, date)
+	if err != nil {
+		return "", false
+	}
+
+	return c.nonWorkingReason(parsed)
+}
+
+func (c *Calendar) nonWorkingReason(date time.Time) (string, bool) {
+	if name, ok := c.Holiday(date); ok {
+		return name, true
+	}
+
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return date.Weekday().String(), true
+	}
+
+	return "", false
+}
+
+func (c *Calendar) publicHolidays(year int) map[string]string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	holidays, ok := c.holidaysByYear[year]
+	if ok {
+		return holidays
+	}
+
+	holidays = map[string]string{}
+	for _, rule := range holidayRules {
+		if !rule.appliesTo(c.state) {
+			continue
+		}
+		date, takesPlace := rule.date(year)
+		if takesPlace {
+			holidays[date.Format(core.DateLayout)] = rule.name
+		}
+	}
+	c.holidaysByYear[year] = holidays
+
+	return holidays
+}
+
+func isKnownState(state string) bool {
+	for _, candidate := range states {
+		if candidate == state {
+			return true
+		}
+	}
+
+	return false
+}
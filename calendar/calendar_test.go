@@ -0,0 +1,89 @@
+package calendar
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func date(value string) time.Time {
+	parsed, _ := time.Parse("2006-01-02", value)
+	return parsed
+}
+
+func TestNew(t *testing.T) {
+	t.Run("should accept lower case state", func(t *testing.T) {
+		_, err := New("by")
+
+		require.NoError(t, err)
+	})
+	t.Run("should fail for unknown state", func(t *testing.T) {
+		_, err := New("XX")
+
+		require.Error(t, err)
+	})
+}
+
+func TestCalendar_Holiday(t *testing.T) {
+	bavaria, _ := New(Bavaria)
+	berlin, _ := New(Berlin)
+	nationwide, _ := New("")
+
+	t.Run("should find nationwide Easter-based holiday", func(t *testing.T) {
+		name, ok := nationwide.Holiday(date("2021-05-24"))
+
+		assert.True(t, ok)
+		assert.Equal(t, "Pfingstmontag", name)
+	})
+	t.Run("should find state holiday only in that state", func(t *testing.T) {
+		name, ok := bavaria.Holiday(date("2021-06-03"))
+		assert.True(t, ok)
+		assert.Equal(t, "Fronleichnam", name)
+
+		_, ok = berlin.Holiday(date("2021-06-03"))
+		assert.False(t, ok)
+	})
+	t.Run("should respect first year of holiday", func(t *testing.T) {
+		_, ok := berlin.Holiday(date("2018-03-08"))
+		assert.False(t, ok)
+
+		_, ok = berlin.Holiday(date("2019-03-08"))
+		assert.True(t, ok)
+	})
+	t.Run("should find custom holidays", func(t *testing.T) {
+		sut, _ := New("")
+		require.NoError(t, sut.AddHoliday("12-24", "Heiligabend"))
+		require.NoError(t, sut.AddHoliday("2021-05-14", "Brückentag"))
+
+		name, ok := sut.Holiday(date("2022-12-24"))
+		assert.True(t, ok)
+		assert.Equal(t, "Heiligabend", name)
+		name, ok = sut.Holiday(date("2021-05-14"))
+		assert.True(t, ok)
+		assert.Equal(t, "Brückentag", name)
+	})
+	t.Run("should fail for malformed custom holiday", func(t *testing.T) {
+		sut, _ := New("")
+
+		require.Error(t, sut.AddHoliday("24.12.", "Heiligabend"))
+	})
+}
+
+func TestCalendar_Describe(t *testing.T) {
+	sut, _ := New("")
+
+	reason, nonWorking := sut.Describe("2021-05-08")
+	assert.True(t, nonWorking)
+	assert.Equal(t, "Saturday", reason)
+
+	reason, nonWorking = sut.Describe("2021-05-13")
+	assert.True(t, nonWorking)
+	assert.Equal(t, "Christi Himmelfahrt", reason)
+
+	_, nonWorking = sut.Describe("2021-05-12")
+	assert.False(t, nonWorking)
+
+	_, nonWorking = sut.Describe("Gesamtzeit")
+	assert.False(t, nonWorking)
+}
@@ -0,0 +1,55 @@
+package calendar
+
+import (
+	"bufio"
+	"github.com/pkg/errors"
+	"os"
+	"strings"
+	"unicode"
+)
+
+const holidayFileComment = "#"
+
+// LoadHolidayFile adds the custom holidays of a text file. Each line contains a date (2021-12-24) or a recurring
+// date (12-24), optionally followed by whitespace and the holiday's name. Empty lines and lines starting with # are
+// ignored.
+//
+// Example:
+//  # company holidays
+//  12-24 Heiligabend
+//  2021-05-14 Brückentag
+func (c *Calendar) LoadHolidayFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "could not open holiday file %s", path)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, holidayFileComment) {
+			continue
+		}
+
+		date := line
+		name := "custom holiday"
+		if separator := strings.IndexFunc(line, unicode.IsSpace); separator >= 0 {
+			date = line[:separator]
+			name = strings.TrimSpace(line[separator:])
+		}
+
+		err = c.AddHoliday(date, name)
+		if err != nil {
+			return errors.Wrapf(err, "error in holiday file %s, line %d", path, lineNumber)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return errors.Wrapf(err, "could not read holiday file %s", path)
+	}
+
+	return nil
+}
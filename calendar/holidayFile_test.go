@@ -0,0 +1,73 @@
+package calendar
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestCalendar_LoadHolidayFile(t *testing.T) {
+	t.Run("should load holidays and skip comments", func(t *testing.T) {
+		file, _ := ioutil.TempFile(os.TempDir(), "holidays-")
+		path := file.Name()
+		defer os.Remove(path)
+		_, _ = file.WriteString(`# company holidays
+12-24 Heiligabend
+
+2021-05-14
+`)
+		sut, _ := New("")
+
+		// when
+		err := sut.LoadHolidayFile(path)
+
+		// then
+		require.NoError(t, err)
+		name, ok := sut.Holiday(date("2021-12-24"))
+		assert.True(t, ok)
+		assert.Equal(t, "Heiligabend", name)
+		name, ok = sut.Holiday(date("2021-05-14"))
+		assert.True(t, ok)
+		assert.Equal(t, "custom holiday", name)
+	})
+	t.Run("should split date and name at tabs", func(t *testing.T) {
+		file, _ := ioutil.TempFile(os.TempDir(), "holidays-")
+		path := file.Name()
+		defer os.Remove(path)
+		_, _ = file.WriteString("2021-12-24\tHeiligabend\n12-31\t \tSilvester\n")
+		sut, _ := New("")
+
+		// when
+		err := sut.LoadHolidayFile(path)
+
+		// then
+		require.NoError(t, err)
+		name, ok := sut.Holiday(date("2021-12-24"))
+		assert.True(t, ok)
+		assert.Equal(t, "Heiligabend", name)
+		name, ok = sut.Holiday(date("2021-12-31"))
+		assert.True(t, ok)
+		assert.Equal(t, "Silvester", name)
+	})
+	t.Run("should report line of malformed date", func(t *testing.T) {
+		file, _ := ioutil.TempFile(os.TempDir(), "holidays-")
+		path := file.Name()
+		defer os.Remove(path)
+		_, _ = file.WriteString("12-24 Heiligabend\n31.12. Silvester\n")
+		sut, _ := New("")
+
+		// when
+		err := sut.LoadHolidayFile(path)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "line 2")
+	})
+	t.Run("should fail for missing file", func(t *testing.T) {
+		sut, _ := New("")
+
+		require.Error(t, sut.LoadHolidayFile("/does/not/exist"))
+	})
+}
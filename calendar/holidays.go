@@ -0,0 +1,136 @@
+package calendar
+
+import (
+	"time"
+)
+
+// German state codes according to ISO 3166-2:DE.
+const (
+	BadenWuerttemberg     = "BW"
+	Bavaria               = "BY"
+	Berlin                = "BE"
+	Brandenburg           = "BB"
+	Bremen                = "HB"
+	Hamburg               = "HH"
+	Hesse                 = "HE"
+	MecklenburgVorpommern = "MV"
+	LowerSaxony           = "NI"
+	NorthRhineWestphalia  = "NW"
+	RhinelandPalatinate   = "RP"
+	Saarland              = "SL"
+	Saxony                = "SN"
+	SaxonyAnhalt          = "ST"
+	SchleswigHolstein     = "SH"
+	Thuringia             = "TH"
+)
+
+var states = []string{
+	BadenWuerttemberg, Bavaria, Berlin, Brandenburg, Bremen, Hamburg, Hesse, MecklenburgVorpommern, LowerSaxony,
+	NorthRhineWestphalia, RhinelandPalatinate, Saarland, Saxony, SaxonyAnhalt, SchleswigHolstein, Thuringia,
+}
+
+// holidayRule computes the date of a holiday in a given year. Rules return false if the holiday does not take place
+// in that year.
+type holidayRule struct {
+	name string
+	// states contains the states which observe the holiday. Empty means nationwide.
+	states []string
+	date   func(year int) (time.Time, bool)
+}
+
+var holidayRules = []holidayRule{
+	{name: "Neujahr", date: fixed(time.January, 1)},
+	{name: "Heilige Drei Könige", states: []string{BadenWuerttemberg, Bavaria, SaxonyAnhalt}, date: fixed(time.January, 6)},
+	{name: "Internationaler Frauentag", states: []string{Berlin}, date: since(2019, fixed(time.March, 8))},
+	{name: "Internationaler Frauentag", states: []string{MecklenburgVorpommern}, date: since(2023, fixed(time.March, 8))},
+	{name: "Karfreitag", date: easterOffset(-2)},
+	{name: "Ostersonntag", states: []string{Brandenburg}, date: easterOffset(0)},
+	{name: "Ostermontag", date: easterOffset(1)},
+	{name: "Tag der Arbeit", date: fixed(time.May, 1)},
+	{name: "Christi Himmelfahrt", date: easterOffset(39)},
+	{name: "Pfingstsonntag", states: []string{Brandenburg}, date: easterOffset(49)},
+	{name: "Pfingstmontag", date: easterOffset(50)},
+	{name: "Fronleichnam", states: []string{BadenWuerttemberg, Bavaria, Hesse, NorthRhineWestphalia, RhinelandPalatinate, Saarland}, date: easterOffset(60)},
+	{name: "Mariä Himmelfahrt", states: []string{Saarland}, date: fixed(time.August, 15)},
+	{name: "Weltkindertag", states: []string{Thuringia}, date: since(2019, fixed(time.September, 20))},
+	{name: "Tag der Deutschen Einheit", date: fixed(time.October, 3)},
+	{name: "Reformationstag", states: []string{Brandenburg, MecklenburgVorpommern, Saxony, SaxonyAnhalt, Thuringia}, date: fixed(time.October, 31)},
+	{name: "Reformationstag", states: []string{Bremen, Hamburg, LowerSaxony, SchleswigHolstein}, date: since(2018, fixed(time.October, 31))},
+	{name: "Reformationstag", states: []string{BadenWuerttemberg, Bavaria, Berlin, Hesse, NorthRhineWestphalia, RhinelandPalatinate, Saarland}, date: only(2017, fixed(time.October, 31))},
+	{name: "Allerheiligen", states: []string{BadenWuerttemberg, Bavaria, NorthRhineWestphalia, RhinelandPalatinate, Saarland}, date: fixed(time.November, 1)},
+	{name: "Buß- und Bettag", states: []string{Saxony}, date: repentanceDay},
+	{name: "1. Weihnachtstag", date: fixed(time.December, 25)},
+	{name: "2. Weihnachtstag", date: fixed(time.December, 26)},
+}
+
+func (hr holidayRule) appliesTo(state string) bool {
+	if len(hr.states) == 0 {
+		return true
+	}
+
+	for _, candidate := range hr.states {
+		if candidate == state {
+			return true
+		}
+	}
+
+	return false
+}
+
+func fixed(month time.Month, day int) func(year int) (time.Time, bool) {
+	return func(year int) (time.Time, bool) {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC), true
+	}
+}
+
+func easterOffset(days int) func(year int) (time.Time, bool) {
+	return func(year int) (time.Time, bool) {
+		return EasterSunday(year).AddDate(0, 0, days), true
+	}
+}
+
+func since(firstYear int, date func(year int) (time.Time, bool)) func(year int) (time.Time, bool) {
+	return func(year int) (time.Time, bool) {
+		if year < firstYear {
+			return time.Time{}, false
+		}
+		return date(year)
+	}
+}
+
+func only(onlyYear int, date func(year int) (time.Time, bool)) func(year int) (time.Time, bool) {
+	return func(year int) (time.Time, bool) {
+		if year != onlyYear {
+			return time.Time{}, false
+		}
+		return date(year)
+	}
+}
+
+// repentanceDay returns the Wednesday before November 23rd.
+func repentanceDay(year int) (time.Time, bool) {
+	november22nd := time.Date(year, time.November, 22, 0, 0, 0, 0, time.UTC)
+	daysSinceWednesday := (int(november22nd.Weekday()) - int(time.Wednesday) + 7) % 7
+
+	return november22nd.AddDate(0, 0, -daysSinceWednesday), true
+}
+
+// EasterSunday computes the date of Easter Sunday in the Gregorian calendar with the anonymous Gregorian algorithm.
+func EasterSunday(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
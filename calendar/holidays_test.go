@@ -0,0 +1,33 @@
+package calendar
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestEasterSunday(t *testing.T) {
+	tests := []struct {
+		year int
+		want string
+	}{
+		{year: 2019, want: "2019-04-21"},
+		{year: 2020, want: "2020-04-12"},
+		{year: 2021, want: "2021-04-04"},
+		{year: 2024, want: "2024-03-31"},
+		{year: 2038, want: "2038-04-25"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, EasterSunday(tt.year).Format("2006-01-02"))
+		})
+	}
+}
+
+func Test_repentanceDay(t *testing.T) {
+	actual2021, _ := repentanceDay(2021)
+	actual2022, _ := repentanceDay(2022)
+
+	assert.Equal(t, time.Date(2021, time.November, 17, 0, 0, 0, 0, time.UTC), actual2021)
+	assert.Equal(t, time.Date(2022, time.November, 16, 0, 0, 0, 0, time.UTC), actual2022)
+}
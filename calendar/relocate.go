@@ -0,0 +1,97 @@
+package calendar
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"time"
+)
+
+// maxRelocationDays limits the search for a previous working day, f. i. for Christmas followed by a weekend.
+const maxRelocationDays = 14
+
+// NonWorkingDay describes work time that was booked on a weekend or holiday.
+type NonWorkingDay struct {
+	Date     string
+	Reason   string
+	WorkTime float64
+}
+
+// Relocation describes work time that was moved from a non-working day to a working day.
+type Relocation struct {
+	NonWorkingDay
+	TargetDate string
+}
+
+// FindNonWorkingDays returns all non-working days with booked work time, ordered by date.
+func (c *Calendar) FindNonWorkingDays(pdata *core.PipelineData) []NonWorkingDay {
+	workTimePerDate := map[string]float64{}
+	for _, workPerDay := range pdata.NamedDayRedmineValues {
+		for date, workTime := range workPerDay.WorkPerDay {
+			workTimePerDate[date] += workTime
+		}
+	}
+
+	result := []NonWorkingDay{}
+	for _, date := range pdata.SortedDates() {
+		reason, nonWorking := c.Describe(date)
+		if nonWorking && workTimePerDate[date] > 0 {
+			result = append(result, NonWorkingDay{Date: date, Reason: reason, WorkTime: workTimePerDate[date]})
+		}
+	}
+
+	return result
+}
+
+// Relocate returns a copy of the pipeline data where the work time of non-working days is moved to the previous
+// working day of the same pipeline.
+func (c *Calendar) Relocate(pdata *core.PipelineData) (*core.PipelineData, []Relocation, error) {
+	result := core.NewPipelineData()
+	relocations := []Relocation{}
+
+	nonWorkingDays := c.FindNonWorkingDays(pdata)
+	targets := map[string]string{}
+	for _, nonWorkingDay := range nonWorkingDays {
+		target, err := c.previousWorkingDay(nonWorkingDay.Date)
+		if err != nil {
+			return nil, nil, err
+		}
+		targets[nonWorkingDay.Date] = target
+		relocations = append(relocations, Relocation{NonWorkingDay: nonWorkingDay, TargetDate: target})
+	}
+
+	for _, name := range pdata.SortedKeys() {
+		pipeline, err := result.AddPipeline(name)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "error while relocating work time")
+		}
+
+		for date, workTime := range pdata.NamedDayRedmineValues[core.PipelineName(name)].WorkPerDay {
+			target, ok := targets[date]
+			if !ok {
+				pipeline.PutWorkTime(date, workTime)
+				continue
+			}
+			// keep the date so that the day still shows up as empty
+			pipeline.PutWorkTime(date, 0)
+			pipeline.PutWorkTime(target, workTime)
+		}
+	}
+
+	return result, relocations, nil
+}
+
+func (c *Calendar) previousWorkingDay(date string) (string, error) {
+	parsed, err := time.Parse(core.DateLayout, date)
+	if err != nil {
+		return "", errors.Wrapf(err, "could not parse date %s", date)
+	}
+
+	for i := 1; i <= maxRelocationDays; i++ {
+		candidate := parsed.AddDate(0, 0, -i)
+		if c.IsWorkingDay(candidate) {
+			return candidate.Format(core.DateLayout), nil
+		}
+	}
+
+	return "", errors.Errorf("found no working day within %d days before %s", maxRelocationDays, date)
+}
@@ -0,0 +1,52 @@
+package calendar
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCalendar_FindNonWorkingDays(t *testing.T) {
+	input := core.NewPipelineData()
+	pipelineA, _ := input.AddPipeline("Pipeline A")
+	pipelineA.PutWorkTime("2021-05-12", 8)
+	pipelineA.PutWorkTime("2021-05-13", 2)
+	pipelineA.PutWorkTime("2021-05-15", 0)
+	pipelineB, _ := input.AddPipeline("Pipeline B")
+	pipelineB.PutWorkTime("2021-05-13", 1)
+	sut, _ := New("")
+
+	actual := sut.FindNonWorkingDays(input)
+
+	expected := []NonWorkingDay{{Date: "2021-05-13", Reason: "Christi Himmelfahrt", WorkTime: 3}}
+	assert.Equal(t, expected, actual)
+}
+
+func TestCalendar_Relocate(t *testing.T) {
+	t.Run("should move work time to the previous working day", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline("Pipeline A")
+		pipelineA.PutWorkTime("2021-05-14", 4)
+		pipelineA.PutWorkTime("2021-05-16", 2)
+		pipelineA.PutWorkTime("2021-05-24", 1)
+		sut, _ := New("")
+
+		// when
+		actual, relocations, err := sut.Relocate(input)
+
+		// then
+		require.NoError(t, err)
+		expected := core.NewPipelineData()
+		expectedA, _ := expected.AddPipeline("Pipeline A")
+		expectedA.PutWorkTime("2021-05-14", 6)
+		expectedA.PutWorkTime("2021-05-16", 0)
+		expectedA.PutWorkTime("2021-05-21", 1)
+		expectedA.PutWorkTime("2021-05-24", 0)
+		assert.Equal(t, expected, actual)
+		require.Len(t, relocations, 2)
+		assert.Equal(t, "2021-05-14", relocations[0].TargetDate)
+		assert.Equal(t, "Sunday", relocations[0].Reason)
+		assert.Equal(t, "2021-05-21", relocations[1].TargetDate)
+	})
+}
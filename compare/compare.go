@@ -0,0 +1,108 @@
+package compare
+
+import (
+	"fmt"
+	"github.com/ppxl/sagemine/core"
+	"io"
+	"math"
+	"sort"
+)
+
+// DefaultTolerance hides differences of about a minute which are caused by crunching work times into whole minutes.
+const DefaultTolerance = 0.02
+
+// Options modify which differences are reported.
+type Options struct {
+	// Tolerance contains the largest difference in hours which is not reported.
+	Tolerance float64
+}
+
+// Difference describes the differing hours of a pipeline on a date. A pipeline or date which is missing on one side
+// counts as zero hours.
+type Difference struct {
+	Pipeline string
+	Date     string
+	Expected float64
+	Actual   float64
+}
+
+// Delta returns the actual minus the expected hours.
+func (d Difference) Delta() float64 {
+	return d.Actual - d.Expected
+}
+
+type dayKey struct {
+	pipeline string
+	date     string
+}
+
+// Compare returns the differences of the actual to the expected hours which exceed the tolerance ordered by date and
+// pipeline.
+func Compare(expected, actual *core.PipelineData, options Options) []Difference {
+	expectedHours := hoursByPipelineAndDate(expected)
+	actualHours := hoursByPipelineAndDate(actual)
+
+	keys := []dayKey{}
+	for key := range expectedHours {
+		keys = append(keys, key)
+	}
+	for key := range actualHours {
+		if _, ok := expectedHours[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].date != keys[j].date {
+			return keys[i].date < keys[j].date
+		}
+		return keys[i].pipeline < keys[j].pipeline
+	})
+
+	result := []Difference{}
+	for _, key := range keys {
+		difference := Difference{Pipeline: key.pipeline, Date: key.date, Expected: expectedHours[key], Actual: actualHours[key]}
+		if math.Abs(difference.Delta()) <= options.Tolerance+1e-9 {
+			continue
+		}
+		result = append(result, difference)
+	}
+
+	return result
+}
+
+// WriteDifferences writes one line per difference or "no differences".
+//
+// Example:
+//  2021-05-04  Pipeline A  expected 6.00 h, actual 5.00 h (-1.00 h)
+//  2021-05-05  Pipeline C  expected 0.00 h, actual 1.50 h (+1.50 h)
+func WriteDifferences(w io.Writer, differences []Difference) error {
+	if len(differences) == 0 {
+		_, err := fmt.Fprintln(w, "no differences")
+		return err
+	}
+
+	for _, difference := range differences {
+		_, err := fmt.Fprintf(w, "%s  %s  expected %.2f h, actual %.2f h (%+.2f h)\n", difference.Date,
+			difference.Pipeline, difference.Expected, difference.Actual, difference.Delta())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func hoursByPipelineAndDate(pdata *core.PipelineData) map[dayKey]float64 {
+	result := map[dayKey]float64{}
+	if pdata == nil {
+		return result
+	}
+
+	for name, workPerDay := range pdata.NamedDayRedmineValues {
+		for date, hours := range workPerDay.WorkPerDay {
+			result[dayKey{pipeline: string(name), date: date}] = hours
+		}
+	}
+
+	return result
+}
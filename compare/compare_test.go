@@ -0,0 +1,102 @@
+package compare
+
+import (
+	"bytes"
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func createPipelineData(hours map[string]map[string]float64) *core.PipelineData {
+	result := core.NewPipelineData()
+	for name, days := range hours {
+		workPerDay, _ := result.AddPipeline(name)
+		for date, value := range days {
+			workPerDay.PutWorkTime(date, value)
+		}
+	}
+
+	return result
+}
+
+func TestCompare(t *testing.T) {
+	expected := createPipelineData(map[string]map[string]float64{
+		"Pipeline A": {"2021-05-03": 7.5, "2021-05-04": 6},
+		"Pipeline B": {"2021-05-03": 0.25},
+	})
+
+	t.Run("should find no differences within the tolerance", func(t *testing.T) {
+		actual := createPipelineData(map[string]map[string]float64{
+			"Pipeline A": {"2021-05-03": 7.5, "2021-05-04": 5.99},
+			"Pipeline B": {"2021-05-03": 0.25},
+		})
+
+		// when
+		differences := Compare(expected, actual, Options{Tolerance: DefaultTolerance})
+
+		// then
+		assert.Empty(t, differences)
+	})
+	t.Run("should find differing and missing hours ordered by date and pipeline", func(t *testing.T) {
+		actual := createPipelineData(map[string]map[string]float64{
+			"Pipeline A": {"2021-05-03": 7.5, "2021-05-04": 5},
+			"Pipeline C": {"2021-05-03": 1.5},
+		})
+
+		// when
+		differences := Compare(expected, actual, Options{Tolerance: DefaultTolerance})
+
+		// then
+		expectedDifferences := []Difference{
+			{Pipeline: "Pipeline B", Date: "2021-05-03", Expected: 0.25, Actual: 0},
+			{Pipeline: "Pipeline C", Date: "2021-05-03", Expected: 0, Actual: 1.5},
+			{Pipeline: "Pipeline A", Date: "2021-05-04", Expected: 6, Actual: 5},
+		}
+		assert.Equal(t, expectedDifferences, differences)
+		assert.Equal(t, -1.0, differences[2].Delta())
+	})
+	t.Run("should report every difference without tolerance", func(t *testing.T) {
+		actual := createPipelineData(map[string]map[string]float64{
+			"Pipeline A": {"2021-05-03": 7.5, "2021-05-04": 5.99},
+			"Pipeline B": {"2021-05-03": 0.25},
+		})
+
+		// when
+		differences := Compare(expected, actual, Options{})
+
+		// then
+		require.Len(t, differences, 1)
+		assert.Equal(t, "2021-05-04", differences[0].Date)
+	})
+}
+
+func TestWriteDifferences(t *testing.T) {
+	t.Run("should write one line per difference", func(t *testing.T) {
+		differences := []Difference{
+			{Pipeline: "Pipeline A", Date: "2021-05-04", Expected: 6, Actual: 5},
+			{Pipeline: "Pipeline C", Date: "2021-05-05", Expected: 0, Actual: 1.5},
+		}
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := WriteDifferences(buffer, differences)
+
+		// then
+		require.NoError(t, err)
+		expected := `2021-05-04  Pipeline A  expected 6.00 h, actual 5.00 h (-1.00 h)
+2021-05-05  Pipeline C  expected 0.00 h, actual 1.50 h (+1.50 h)
+`
+		assert.Equal(t, expected, buffer.String())
+	})
+	t.Run("should write that nothing differs", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := WriteDifferences(buffer, []Difference{})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "no differences\n", buffer.String())
+	})
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/compare"
+	"github.com/ppxl/sagemine/core"
+	"github.com/ppxl/sagemine/reader"
+	"github.com/urfave/cli/v2"
+	"io"
+	"os"
+)
+
+const (
+	flagSageLong      = "sage"
+	flagToleranceLong = "tolerance"
+)
+
+func compareCommand() *cli.Command {
+	flags := append(runFlags(),
+		&cli.StringSliceFlag{
+			Name:  flagSageLong,
+			Usage: "CSV or JSON file with the time slots which were entered into Sage, may be repeated",
+		},
+		&cli.Float64Flag{
+			Name:  flagToleranceLong,
+			Usage: "largest difference in hours per pipeline and day which is not reported (optional)",
+			Value: compare.DefaultTolerance,
+		},
+	)
+
+	return &cli.Command{
+		Name:      "compare",
+		Usage:     "compare the hours of the Redmine export with the time slots which were entered into Sage",
+		Action:    doCliCompare,
+		ArgsUsage: runArgsUsage,
+		Flags:     flags,
+	}
+}
+
+func doCliCompare(cliCtx *cli.Context) error {
+	sageFiles := cliCtx.StringSlice(flagSageLong)
+	if len(sageFiles) == 0 {
+		return errors.Errorf("compare needs the time slots entered into Sage, f. i. --%s sage.csv", flagSageLong)
+	}
+
+	args, err := buildRunArgs(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	return doCompare(os.Stdout, args, sageFiles, cliCtx.Float64(flagToleranceLong))
+}
+
+// doCompare reads the Redmine data like the run command and writes the hours per pipeline and day which differ from
+// the time slots in the Sage files.
+func doCompare(w io.Writer, args runArgs, sageFiles []string, tolerance float64) error {
+	if tolerance < 0 {
+		return errors.Errorf("invalid tolerance of %g h (expected a positive number of hours)", tolerance)
+	}
+
+	expected, _, err := prepareRedmineData(args)
+	if err != nil {
+		return err
+	}
+
+	actual, err := readSageData(args, sageFiles)
+	if err != nil {
+		return err
+	}
+
+	return compare.WriteDifferences(w, compare.Compare(expected, actual, compare.Options{Tolerance: tolerance}))
+}
+
+// readSageData converts the time slots of all Sage files back into hours per pipeline and day within the date range.
+func readSageData(args runArgs, sageFiles []string) (*core.PipelineData, error) {
+	sources := []reader.Source{}
+	for _, filename := range sageFiles {
+		options := reader.Options{
+			CSVOptions: reader.CSVOptions{
+				Filename:    filename,
+				Encoding:    args.encoding,
+				DateLayouts: args.dateLayouts,
+			},
+		}
+		sageReader, err := reader.Create(reader.FormatSage, options)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, reader.Source{Name: filename, Reader: sageReader})
+	}
+
+	data, err := reader.NewMultiReader(sources).Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "error while reading Sage data")
+	}
+	if !args.dateRange.IsUnbounded() {
+		data = data.FilterDates(args.dateRange)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_doCompare(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "compare-")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "export.csv")
+	_ = ioutil.WriteFile(path, []byte(`Anforderungspipeline;2021-05-03;2021-05-04
+Pipeline A;7,50;6,00
+`), 0600)
+	week, _ := core.ParseISOWeek("2021-W18")
+	args := runArgs{
+		lunchBreakInMin:  60,
+		filenames:        []string{path},
+		csvDelimiter:     ";",
+		decimalDelimiter: ",",
+		dateRange:        week,
+	}
+
+	t.Run("should find no differences to the crunched time slots", func(t *testing.T) {
+		data, workCalendar, err := prepareRedmineData(args)
+		require.NoError(t, err)
+		crunched, err := crunch(data, args)
+		require.NoError(t, err)
+		sagePath := filepath.Join(dir, "sage.csv")
+		sageFile, _ := os.Create(sagePath)
+		err = writeResults(sageFile, crunched, workCalendar, runArgs{outputFormat: "csv"})
+		_ = sageFile.Close()
+		require.NoError(t, err)
+		buffer := &bytes.Buffer{}
+
+		// when
+		err = doCompare(buffer, args, []string{sagePath}, 0.02)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "no differences\n", buffer.String())
+	})
+	t.Run("should show hours which differ from Sage", func(t *testing.T) {
+		sagePath := filepath.Join(dir, "entered.csv")
+		_ = ioutil.WriteFile(sagePath, []byte(`Pipeline;Date;Start;End
+Pipeline A-joined;2021-05-03;08:00;12:00
+Pipeline A-joined;2021-05-03;13:00;16:30
+Pipeline A-joined;2021-05-04;08:00;12:00
+Pipeline A-joined;2021-05-04;13:00;14:00
+Pipeline A-joined;2021-05-10;08:00;12:00
+`), 0600)
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := doCompare(buffer, args, []string{sagePath}, 0.02)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "2021-05-04  Pipeline A-joined  expected 6.00 h, actual 5.00 h (-1.00 h)\n", buffer.String())
+	})
+	t.Run("should fail for negative tolerances", func(t *testing.T) {
+		// when
+		err := doCompare(&bytes.Buffer{}, args, []string{path}, -1)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid tolerance")
+	})
+}
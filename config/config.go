@@ -0,0 +1,227 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/cruncher"
+	"github.com/ppxl/sagemine/locale"
+	"github.com/ppxl/sagemine/transformer"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// ProjectFile is the configuration file in the working directory.
+	ProjectFile = ".redsage.yaml"
+	userDir     = "redsage"
+	userFile    = "config.yaml"
+)
+
+// Config contains the run options which are read from configuration files and environment variables. Options left
+// empty fall back to the defaults of the run command.
+//
+// Example:
+//  reader:
+//    locale: de
+//    skipColumns: [Kommentar]
+//  transform:
+//    steps:
+//      - name: filter
+//        options: {exclude: Internal}
+//      - name: join
+//  cruncher:
+//    lunchBreakInMin: 45
+//  output:
+//    format: csv
+type Config struct {
+	Reader    Reader    `yaml:"reader,omitempty"`
+	Redmine   Redmine   `yaml:"redmine,omitempty"`
+	Transform Transform `yaml:"transform,omitempty"`
+	Cruncher  Cruncher  `yaml:"cruncher,omitempty"`
+	Calendar  Calendar  `yaml:"calendar,omitempty"`
+	Output    Output    `yaml:"output,omitempty"`
+	Report    Report    `yaml:"report,omitempty"`
+	Ledger    Ledger    `yaml:"ledger,omitempty"`
+	History   History   `yaml:"history,omitempty"`
+	// Locales contains additional locale presets. The presets of all files are collected.
+	Locales []locale.Preset `yaml:"locales,omitempty"`
+	// Sources contains the loaded files and environment variables in ascending precedence.
+	Sources []string `yaml:"-"`
+}
+
+// Reader contains the options of reading Redmine exports.
+type Reader struct {
+	InputFormat       string   `yaml:"inputFormat,omitempty"`
+	Sheet             string   `yaml:"sheet,omitempty"`
+	Encoding          string   `yaml:"encoding,omitempty"`
+	CSVDelimiter      string   `yaml:"csvDelimiter,omitempty"`
+	DecimalDelimiter  string   `yaml:"decimalDelimiter,omitempty"`
+	SkipColumns       []string `yaml:"skipColumns,omitempty"`
+	IgnoreSummaryLine *bool    `yaml:"ignoreSummaryLine,omitempty"`
+	Locale            string   `yaml:"locale,omitempty"`
+	LocaleFile        string   `yaml:"localeFile,omitempty"`
+}
+
+// Redmine contains the options of reading time entries from the Redmine REST API. Secrets are not part of the
+// configuration, see package credentials.
+type Redmine struct {
+	URL             string `yaml:"url,omitempty"`
+	User            string `yaml:"user,omitempty"`
+	UserID          string `yaml:"userId,omitempty"`
+	PasswordCommand string `yaml:"passwordCommand,omitempty"`
+	NetrcFile       string `yaml:"netrcFile,omitempty"`
+}
+
+// Transform contains the transformation rules.
+type Transform struct {
+	SinglePipelines []string                     `yaml:"singlePipelines,omitempty"`
+	Steps           []transformer.StepDefinition `yaml:"steps,omitempty"`
+}
+
+// Cruncher contains the work schedule.
+type Cruncher struct {
+	// Name selects a registered cruncher, f. i. "sequential".
+	Name            string `yaml:"name,omitempty"`
+	LunchBreakInMin *int   `yaml:"lunchBreakInMin,omitempty"`
+	// LunchStart contains the start of the lunch break in 24-hour format, f. i. "12:30".
+	LunchStart string `yaml:"lunchStart,omitempty"`
+	// ChunkMinutes and Interleave configure the interleaved cruncher, f. i. 30 and "round-robin".
+	ChunkMinutes *int   `yaml:"chunkMinutes,omitempty"`
+	Interleave   string `yaml:"interleave,omitempty"`
+	// Anchors contains pipelines with fixed times of day, f. i. a daily stand-up.
+	Anchors []cruncher.Anchor `yaml:"anchors,omitempty"`
+	// MinSlotMinutes, MergeInto and Consolidate avoid short and scattered time slots.
+	MinSlotMinutes *int   `yaml:"minSlotMinutes,omitempty"`
+	MergeInto      string `yaml:"mergeInto,omitempty"`
+	Consolidate    *bool  `yaml:"consolidate,omitempty"`
+}
+
+// Calendar contains the options of weekends and holidays.
+type Calendar struct {
+	HolidayState   string `yaml:"holidayState,omitempty"`
+	HolidayFile    string `yaml:"holidayFile,omitempty"`
+	NonWorkingDays string `yaml:"nonWorkingDays,omitempty"`
+}
+
+// Output contains the options of writing the results.
+type Output struct {
+	Format string `yaml:"format,omitempty"`
+}
+
+// Report contains the options of the summary report.
+type Report struct {
+	TargetHoursPerDay *float64 `yaml:"targetHoursPerDay,omitempty"`
+	// Period groups the days by "week" or "month".
+	Period string `yaml:"period,omitempty"`
+	Format string `yaml:"format,omitempty"`
+}
+
+// Ledger contains the options of the overtime ledger.
+type Ledger struct {
+	// File enables the ledger which each run updates.
+	File string `yaml:"file,omitempty"`
+}
+
+// History contains the options of the stored weeks.
+type History struct {
+	// Dir enables storing each crunched week for later diffs.
+	Dir string `yaml:"dir,omitempty"`
+}
+
+// Load returns the effective configuration. If path is set only this file is read, otherwise the user file and the
+// project file are read if they exist. Environment variables take precedence over all files.
+func Load(path string) (*Config, error) {
+	result := &Config{}
+
+	var err error
+	if path != "" {
+		err = result.LoadFile(path)
+	} else {
+		err = result.LoadFiles(DefaultFiles())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = result.ApplyEnvironment(os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DefaultFiles returns the user file and the project file in ascending precedence.
+func DefaultFiles() []string {
+	result := []string{}
+
+	configDir, err := os.UserConfigDir()
+	if err == nil {
+		result = append(result, filepath.Join(configDir, userDir, userFile))
+	}
+
+	return append(result, ProjectFile)
+}
+
+// LoadFiles reads the given files in ascending precedence. Files which do not exist are skipped.
+func (c *Config) LoadFiles(paths []string) error {
+	for _, path := range paths {
+		_, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		err = c.LoadFile(path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoadFile reads a configuration file. Options of the file replace the options read so far, locale presets are
+// added. Relative paths of the file are resolved against its directory.
+func (c *Config) LoadFile(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "could not read config file %s", path)
+	}
+
+	previousPaths := []string{}
+	for _, option := range c.pathOptions() {
+		previousPaths = append(previousPaths, *option)
+	}
+
+	locales := c.Locales
+	c.Locales = nil
+	err = yaml.UnmarshalStrict(content, c)
+	c.Locales = append(locales, c.Locales...)
+	if err != nil {
+		return errors.Wrapf(err, "could not parse config file %s", path)
+	}
+
+	dir := filepath.Dir(path)
+	for i, option := range c.pathOptions() {
+		if *option != previousPaths[i] {
+			*option = resolvePath(dir, *option)
+		}
+	}
+
+	c.Sources = append(c.Sources, path)
+	return nil
+}
+
+// pathOptions returns the options which contain file or directory paths.
+func (c *Config) pathOptions() []*string {
+	return []*string{&c.Reader.LocaleFile, &c.Calendar.HolidayFile, &c.Ledger.File, &c.History.Dir}
+}
+
+func resolvePath(dir string, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfigFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestConfig_LoadFiles(t *testing.T) {
+	dir, _ := ioutil.TempDir(os.TempDir(), "redsage-config-")
+	defer os.RemoveAll(dir)
+
+	userFile := writeConfigFile(t, dir, "user.yaml", `reader:
+  locale: de
+  skipColumns: [Kommentar]
+cruncher:
+  lunchBreakInMin: 30
+locales:
+  - name: de-ch
+    extends: de
+`)
+	projectFile := writeConfigFile(t, dir, "project.yaml", `reader:
+  locale: en
+transform:
+  steps:
+    - name: filter
+      options: {exclude: Internal}
+    - name: join
+output:
+  format: csv
+locales:
+  - name: en-gb
+    extends: en
+`)
+
+	t.Run("should let the later file take precedence", func(t *testing.T) {
+		sut := &Config{}
+
+		// when
+		err := sut.LoadFiles([]string{userFile, projectFile, filepath.Join(dir, "missing.yaml")})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "en", sut.Reader.Locale)
+		assert.Equal(t, []string{"Kommentar"}, sut.Reader.SkipColumns)
+		assert.Equal(t, 30, *sut.Cruncher.LunchBreakInMin)
+		assert.Equal(t, "csv", sut.Output.Format)
+		require.Len(t, sut.Transform.Steps, 2)
+		assert.Equal(t, "Internal", sut.Transform.Steps[0].Options["exclude"])
+		require.Len(t, sut.Locales, 2)
+		assert.Equal(t, []string{userFile, projectFile}, sut.Sources)
+	})
+	t.Run("should resolve relative paths against the directory of the file", func(t *testing.T) {
+		subDir := filepath.Join(dir, "sub")
+		require.NoError(t, os.Mkdir(subDir, 0700))
+		path := writeConfigFile(t, subDir, "paths.yaml", `reader:
+  localeFile: locales.yaml
+ledger:
+  file: ledger.json
+history:
+  dir: /var/lib/redsage
+`)
+		sut := &Config{Calendar: Calendar{HolidayFile: "holidays.txt"}}
+
+		// when
+		err := sut.LoadFile(path)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(subDir, "locales.yaml"), sut.Reader.LocaleFile)
+		assert.Equal(t, filepath.Join(subDir, "ledger.json"), sut.Ledger.File)
+		assert.Equal(t, "/var/lib/redsage", sut.History.Dir)
+		assert.Equal(t, "holidays.txt", sut.Calendar.HolidayFile)
+	})
+	t.Run("should fail for unknown options", func(t *testing.T) {
+		path := writeConfigFile(t, dir, "typo.yaml", "reader:\n  lokale: de\n")
+		sut := &Config{}
+
+		// when
+		err := sut.LoadFile(path)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), path)
+	})
+	t.Run("should fail for a missing explicit file", func(t *testing.T) {
+		// when
+		_, err := Load(filepath.Join(dir, "missing.yaml"))
+
+		// then
+		require.Error(t, err)
+	})
+}
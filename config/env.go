@@ -0,0 +1,166 @@
+package config
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/cruncher"
+	"github.com/ppxl/sagemine/transformer"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// EnvPrefix starts the names of all environment variables which are read by RedSage.
+	EnvPrefix         = "REDSAGE_"
+	envListSeparator  = ","
+	envStepsSeparator = ";"
+	// envAnchorsSeparator separates anchors because their weekdays are separated by commas.
+	envAnchorsSeparator = ";"
+)
+
+// environment maps the names of the environment variables to the options they set.
+var environment = map[string]func(c *Config, value string) error{
+	"INPUT_FORMAT":      func(c *Config, value string) error { c.Reader.InputFormat = value; return nil },
+	"SHEET":             func(c *Config, value string) error { c.Reader.Sheet = value; return nil },
+	"ENCODING":          func(c *Config, value string) error { c.Reader.Encoding = value; return nil },
+	"CSV_DELIMITER":     func(c *Config, value string) error { c.Reader.CSVDelimiter = value; return nil },
+	"DECIMAL_DELIMITER": func(c *Config, value string) error { c.Reader.DecimalDelimiter = value; return nil },
+	"SKIP_COLUMNS":      func(c *Config, value string) error { c.Reader.SkipColumns = splitList(value); return nil },
+	"IGNORE_SUMMARY_LINE": func(c *Config, value string) error {
+		ignore, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		c.Reader.IgnoreSummaryLine = &ignore
+		return nil
+	},
+	"REDMINE_URL":              func(c *Config, value string) error { c.Redmine.URL = value; return nil },
+	"REDMINE_USER":             func(c *Config, value string) error { c.Redmine.User = value; return nil },
+	"REDMINE_USER_ID":          func(c *Config, value string) error { c.Redmine.UserID = value; return nil },
+	"REDMINE_PASSWORD_COMMAND": func(c *Config, value string) error { c.Redmine.PasswordCommand = value; return nil },
+	"NETRC_FILE":               func(c *Config, value string) error { c.Redmine.NetrcFile = value; return nil },
+	"LOCALE":                   func(c *Config, value string) error { c.Reader.Locale = value; return nil },
+	"LOCALE_FILE":              func(c *Config, value string) error { c.Reader.LocaleFile = value; return nil },
+	"SINGLE_PIPELINES":         func(c *Config, value string) error { c.Transform.SinglePipelines = splitList(value); return nil },
+	"TRANSFORM": func(c *Config, value string) error {
+		steps := []transformer.StepDefinition{}
+		for _, spec := range strings.Split(value, envStepsSeparator) {
+			step, err := transformer.ParseStep(strings.TrimSpace(spec))
+			if err != nil {
+				return err
+			}
+			steps = append(steps, step)
+		}
+		c.Transform.Steps = steps
+		return nil
+	},
+	"LUNCH_BREAK": func(c *Config, value string) error {
+		lunchBreakInMin, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Cruncher.LunchBreakInMin = &lunchBreakInMin
+		return nil
+	},
+	"LUNCH_START":      func(c *Config, value string) error { c.Cruncher.LunchStart = value; return nil },
+	"HOLIDAY_STATE":    func(c *Config, value string) error { c.Calendar.HolidayState = value; return nil },
+	"HOLIDAY_FILE":     func(c *Config, value string) error { c.Calendar.HolidayFile = value; return nil },
+	"NON_WORKING_DAYS": func(c *Config, value string) error { c.Calendar.NonWorkingDays = value; return nil },
+	"OUTPUT_FORMAT":    func(c *Config, value string) error { c.Output.Format = value; return nil },
+	"TARGET_HOURS": func(c *Config, value string) error {
+		targetHours, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		c.Report.TargetHoursPerDay = &targetHours
+		return nil
+	},
+	"REPORT_PERIOD": func(c *Config, value string) error { c.Report.Period = value; return nil },
+	"REPORT_FORMAT": func(c *Config, value string) error { c.Report.Format = value; return nil },
+	"LEDGER":        func(c *Config, value string) error { c.Ledger.File = value; return nil },
+	"HISTORY":       func(c *Config, value string) error { c.History.Dir = value; return nil },
+	"CRUNCHER":      func(c *Config, value string) error { c.Cruncher.Name = value; return nil },
+	"CHUNK_MINUTES": func(c *Config, value string) error {
+		chunkMinutes, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Cruncher.ChunkMinutes = &chunkMinutes
+		return nil
+	},
+	"INTERLEAVE": func(c *Config, value string) error { c.Cruncher.Interleave = value; return nil },
+	"ANCHORS": func(c *Config, value string) error {
+		anchors := []cruncher.Anchor{}
+		for _, spec := range strings.Split(value, envAnchorsSeparator) {
+			anchor, err := cruncher.ParseAnchor(strings.TrimSpace(spec))
+			if err != nil {
+				return err
+			}
+			anchors = append(anchors, anchor)
+		}
+		c.Cruncher.Anchors = anchors
+		return nil
+	},
+	"MIN_SLOT": func(c *Config, value string) error {
+		minSlotMinutes, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		c.Cruncher.MinSlotMinutes = &minSlotMinutes
+		return nil
+	},
+	"MERGE_INTO": func(c *Config, value string) error { c.Cruncher.MergeInto = value; return nil },
+	"CONSOLIDATE": func(c *Config, value string) error {
+		consolidate, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		c.Cruncher.Consolidate = &consolidate
+		return nil
+	},
+}
+
+// EnvNames returns the names of all environment variables in lexical order.
+func EnvNames() []string {
+	result := make([]string, 0, len(environment))
+	for name := range environment {
+		result = append(result, EnvPrefix+name)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// ApplyEnvironment replaces options with the values of the set environment variables. Lists are separated by ",",
+// transformation steps by ";".
+//
+// Example:
+//  REDSAGE_TRANSFORM="filter:exclude=Internal;join" REDSAGE_LUNCH_BREAK=45 redsage run export.csv
+func (c *Config) ApplyEnvironment(lookup func(name string) (string, bool)) error {
+	for _, name := range EnvNames() {
+		value, ok := lookup(name)
+		if !ok || value == "" {
+			continue
+		}
+
+		err := environment[strings.TrimPrefix(name, EnvPrefix)](c, value)
+		if err != nil {
+			return errors.Wrapf(err, "invalid value '%s' of environment variable %s", value, name)
+		}
+		c.Sources = append(c.Sources, "$"+name)
+	}
+
+	return nil
+}
+
+func splitList(value string) []string {
+	result := []string{}
+	for _, item := range strings.Split(value, envListSeparator) {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestConfig_ApplyEnvironment(t *testing.T) {
+	t.Run("should replace options by environment variables", func(t *testing.T) {
+		env := map[string]string{
+			"REDSAGE_LOCALE":       "fr",
+			"REDSAGE_SKIP_COLUMNS": "Kommentar, Gesamtzeit",
+			"REDSAGE_LUNCH_BREAK":  "45",
+			"REDSAGE_TARGET_HOURS": "7.5",
+			"REDSAGE_TRANSFORM":    "filter:exclude=Internal; join",
+			"REDSAGE_SHEET":        "",
+			"REDSAGE_ANCHORS":      "Pipeline A=09:00-09:15@mon,wed; On-call=18:00-20:00",
+		}
+		sut := &Config{Reader: Reader{Locale: "de", Sheet: "Export"}}
+
+		// when
+		err := sut.ApplyEnvironment(func(name string) (string, bool) {
+			value, ok := env[name]
+			return value, ok
+		})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "fr", sut.Reader.Locale)
+		assert.Equal(t, "Export", sut.Reader.Sheet)
+		assert.Equal(t, []string{"Kommentar", "Gesamtzeit"}, sut.Reader.SkipColumns)
+		assert.Equal(t, 45, *sut.Cruncher.LunchBreakInMin)
+		assert.Equal(t, 7.5, *sut.Report.TargetHoursPerDay)
+		require.Len(t, sut.Transform.Steps, 2)
+		assert.Equal(t, "join", sut.Transform.Steps[1].Name)
+		require.Len(t, sut.Cruncher.Anchors, 2)
+		assert.Equal(t, []string{"mon", "wed"}, sut.Cruncher.Anchors[0].Weekdays)
+		assert.Equal(t, "On-call", sut.Cruncher.Anchors[1].Pipeline)
+	})
+	t.Run("should fail for invalid numbers", func(t *testing.T) {
+		sut := &Config{}
+
+		// when
+		err := sut.ApplyEnvironment(func(name string) (string, bool) {
+			return "lots", name == "REDSAGE_LUNCH_BREAK"
+		})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "REDSAGE_LUNCH_BREAK")
+	})
+}
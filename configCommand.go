@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/config"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+	"strings"
+)
+
+func configCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "config",
+		Usage: "inspect the configuration of config files and environment variables",
+		Subcommands: []*cli.Command{
+			{
+				Name: "show",
+				Usage: "print the effective configuration merged from " + strings.Join(config.DefaultFiles(), ", ") +
+					" and the environment variables " + strings.Join(config.EnvNames(), ", "),
+				Action: doConfigShow,
+			},
+		},
+	}
+}
+
+func doConfigShow(cliCtx *cli.Context) error {
+	cfg, err := config.Load(cliCtx.String(flagGlobalConfigLong))
+	if err != nil {
+		return err
+	}
+
+	content, err := yaml.Marshal(cfg)
+	if err != nil {
+		return errors.Wrap(err, "could not format configuration")
+	}
+
+	writer := cliCtx.App.Writer
+	if len(cfg.Sources) == 0 {
+		_, _ = fmt.Fprintln(writer, "# no config file or environment variable found, using defaults")
+	}
+	for _, source := range cfg.Sources {
+		_, _ = fmt.Fprintf(writer, "# source: %s\n", source)
+	}
+	_, err = fmt.Fprint(writer, string(content))
+
+	return err
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// DateLayout describes the date format which is used for pipeline data keys, f. i. 2021-05-03.
+const DateLayout = "2006-01-02"
+
+var isoWeekPattern = regexp.MustCompile(`^(\d{4})-W(\d{2})$`)
+
+// DateRange contains an inclusive range of dates. A zero bound leaves the range open on that side.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// NewDateRange parses two dates of the format YYYY-MM-DD. Empty strings leave the range open on that side.
+func NewDateRange(from, to string) (DateRange, error) {
+	result := DateRange{}
+	var err error
+
+	if from != "" {
+		result.From, err = time.Parse(DateLayout, from)
+		if err != nil {
+			return DateRange{}, errors.Wrapf(err, "could not parse start date '%s'", from)
+		}
+	}
+	if to != "" {
+		result.To, err = time.Parse(DateLayout, to)
+		if err != nil {
+			return DateRange{}, errors.Wrapf(err, "could not parse end date '%s'", to)
+		}
+	}
+
+	if !result.From.IsZero() && !result.To.IsZero() && result.To.Before(result.From) {
+		return DateRange{}, errors.Errorf("end date %s must not be before start date %s", to, from)
+	}
+
+	return result, nil
+}
+
+// ParseISOWeek returns the range from Monday to Sunday of an ISO 8601 week, f. i. 2021-W18.
+func ParseISOWeek(week string) (DateRange, error) {
+	matches := isoWeekPattern.FindStringSubmatch(week)
+	if matches == nil {
+		return DateRange{}, errors.Errorf("week '%s' must look like YYYY-Www, f. i. 2021-W18", week)
+	}
+
+	year, _ := strconv.Atoi(matches[1])
+	weekNumber, _ := strconv.Atoi(matches[2])
+
+	// January 4th always lies in the first ISO week
+	january4th := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	daysSinceMonday := (int(january4th.Weekday()) + 6) % 7
+	monday := january4th.AddDate(0, 0, -daysSinceMonday+(weekNumber-1)*7)
+
+	if weekNumber < 1 || FormatISOWeek(monday) != week {
+		return DateRange{}, errors.Errorf("week '%s' does not exist", week)
+	}
+
+	return DateRange{From: monday, To: monday.AddDate(0, 0, 6)}, nil
+}
+
+// ParseMonth returns the range from the first to the last day of a month, f. i. 2021-05.
+func ParseMonth(month string) (DateRange, error) {
+	first, err := time.Parse("2006-01", month)
+	if err != nil {
+		return DateRange{}, errors.Wrapf(err, "month '%s' must look like YYYY-MM, f. i. 2021-05", month)
+	}
+
+	return DateRange{From: first, To: first.AddDate(0, 1, -1)}, nil
+}
+
+// IsUnbounded returns true if the range is open on both sides and thus contains every date.
+func (dr DateRange) IsUnbounded() bool {
+	return dr.From.IsZero() && dr.To.IsZero()
+}
+
+// Contains returns true if the given date of the format YYYY-MM-DD lies within the range. Keys which are no dates
+// are only contained in an unbounded range.
+func (dr DateRange) Contains(date string) bool {
+	if dr.IsUnbounded() {
+		return true
+	}
+
+	parsed, err := time.Parse(DateLayout, date)
+	if err != nil {
+		return false
+	}
+
+	if !dr.From.IsZero() && parsed.Before(dr.From) {
+		return false
+	}
+	if !dr.To.IsZero() && parsed.After(dr.To) {
+		return false
+	}
+
+	return true
+}
+
+// FromDate returns the start date in the format YYYY-MM-DD or an empty string if the range is open at the start.
+func (dr DateRange) FromDate() string {
+	return formatBound(dr.From)
+}
+
+// ToDate returns the end date in the format YYYY-MM-DD or an empty string if the range is open at the end.
+func (dr DateRange) ToDate() string {
+	return formatBound(dr.To)
+}
+
+func (dr DateRange) String() string {
+	return fmt.Sprintf("%s..%s", dr.FromDate(), dr.ToDate())
+}
+
+func formatBound(bound time.Time) string {
+	if bound.IsZero() {
+		return ""
+	}
+
+	return bound.Format(DateLayout)
+}
+
+// FormatISOWeek returns the name of the ISO 8601 week of a date, f. i. 2021-W18.
+func FormatISOWeek(date time.Time) string {
+	year, week := date.ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", year, week)
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestNewDateRange(t *testing.T) {
+	t.Run("should parse both bounds", func(t *testing.T) {
+		actual, err := NewDateRange("2021-05-03", "2021-05-07")
+
+		require.NoError(t, err)
+		assert.Equal(t, "2021-05-03..2021-05-07", actual.String())
+	})
+	t.Run("should leave range open", func(t *testing.T) {
+		actual, err := NewDateRange("", "2021-05-07")
+
+		require.NoError(t, err)
+		assert.True(t, actual.From.IsZero())
+		assert.Equal(t, "2021-05-07", actual.ToDate())
+	})
+	t.Run("should fail for reversed bounds", func(t *testing.T) {
+		_, err := NewDateRange("2021-05-07", "2021-05-03")
+
+		require.Error(t, err)
+	})
+	t.Run("should fail for malformed date", func(t *testing.T) {
+		_, err := NewDateRange("03.05.2021", "")
+
+		require.Error(t, err)
+	})
+}
+
+func TestParseISOWeek(t *testing.T) {
+	tests := []struct {
+		week     string
+		wantFrom string
+		wantTo   string
+	}{
+		{week: "2021-W18", wantFrom: "2021-05-03", wantTo: "2021-05-09"},
+		{week: "2021-W01", wantFrom: "2021-01-04", wantTo: "2021-01-10"},
+		{week: "2020-W53", wantFrom: "2020-12-28", wantTo: "2021-01-03"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.week, func(t *testing.T) {
+			actual, err := ParseISOWeek(tt.week)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFrom, actual.FromDate())
+			assert.Equal(t, tt.wantTo, actual.ToDate())
+		})
+	}
+	t.Run("should fail for non-existing week", func(t *testing.T) {
+		_, err := ParseISOWeek("2021-W53")
+
+		require.Error(t, err)
+	})
+	t.Run("should fail for malformed week", func(t *testing.T) {
+		_, err := ParseISOWeek("2021-18")
+
+		require.Error(t, err)
+	})
+}
+
+func TestParseMonth(t *testing.T) {
+	actual, err := ParseMonth("2021-02")
+
+	require.NoError(t, err)
+	assert.Equal(t, DateRange{
+		From: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2021, 2, 28, 0, 0, 0, 0, time.UTC),
+	}, actual)
+}
+
+func TestDateRange_Contains(t *testing.T) {
+	sut, _ := NewDateRange("2021-05-03", "2021-05-07")
+
+	assert.False(t, sut.Contains("2021-05-02"))
+	assert.True(t, sut.Contains("2021-05-03"))
+	assert.True(t, sut.Contains("2021-05-07"))
+	assert.False(t, sut.Contains("2021-05-08"))
+	assert.False(t, sut.Contains("Gesamtzeit"))
+	assert.True(t, DateRange{}.Contains("Gesamtzeit"))
+}
@@ -0,0 +1,61 @@
+package core
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// ErrInvalidDate is the cause of all errors about dates which do not have the format YYYY-MM-DD. Check for it with
+// errors.Is.
+var ErrInvalidDate = errors.New("invalid date")
+
+// DateError describes a malformed date and where it was found. All fields except Date are optional.
+type DateError struct {
+	Date string
+	// Source names the origin of the date, f. i. a file name.
+	Source string
+	// Line and Column contain the 1-based position of the date in a table.
+	Line   int
+	Column int
+	// Err contains the parse error.
+	Err error
+}
+
+// NewDateError returns an error about a malformed date without position.
+func NewDateError(date string, err error) *DateError {
+	return &DateError{Date: date, Err: err}
+}
+
+func (de *DateError) Error() string {
+	position := []string{}
+	if de.Source != "" {
+		position = append(position, de.Source)
+	}
+	if de.Line > 0 {
+		position = append(position, fmt.Sprintf("line %d", de.Line))
+	}
+	if de.Column > 0 {
+		position = append(position, fmt.Sprintf("column %d", de.Column))
+	}
+
+	result := fmt.Sprintf("invalid date '%s'", de.Date)
+	if len(position) > 0 {
+		result += fmt.Sprintf(" (%s)", strings.Join(position, ", "))
+	}
+	if de.Err != nil {
+		result += ": " + de.Err.Error()
+	}
+
+	return result
+}
+
+// Unwrap returns the parse error.
+func (de *DateError) Unwrap() error {
+	return de.Err
+}
+
+// Is matches ErrInvalidDate.
+func (de *DateError) Is(target error) bool {
+	return target == ErrInvalidDate
+}
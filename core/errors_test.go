@@ -0,0 +1,34 @@
+package core
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestDateError_Error(t *testing.T) {
+	t.Run("should describe the position of the date", func(t *testing.T) {
+		sut := &DateError{Date: "Gesamtzeit", Source: "timelog.csv", Line: 1, Column: 6, Err: errors.New("no date")}
+
+		// when
+		actual := sut.Error()
+
+		// then
+		assert.Equal(t, "invalid date 'Gesamtzeit' (timelog.csv, line 1, column 6): no date", actual)
+	})
+	t.Run("should describe the date without position", func(t *testing.T) {
+		// when
+		actual := NewDateError("05/03/2021", nil).Error()
+
+		// then
+		assert.Equal(t, "invalid date '05/03/2021'", actual)
+	})
+}
+
+func TestDateError_Is(t *testing.T) {
+	// when
+	err := errors.Wrap(NewDateError("Gesamtzeit", nil), "error while crunching")
+
+	// then
+	assert.True(t, errors.Is(err, ErrInvalidDate))
+}
@@ -1,14 +1,21 @@
 package core
 
 import (
-	"errors"
 	"fmt"
-	"github.com/sirupsen/logrus"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/logging"
 	"sort"
 	"time"
 )
 
-const timeSlotFormat = "%s - %s"
+var log = logging.Logger()
+
+const (
+	timeSlotFormat      = "%s - %s"
+	emptyTimeSlotMarker = "-"
+	// WallClockLayout describes the format of time slot starts and ends, f. i. 13:00.
+	WallClockLayout = "15:04"
+)
 
 func NewPipelineData() *PipelineData {
 	values := make(map[PipelineName]*RedmineWorkPerDay, 0)
@@ -31,6 +38,34 @@ func (pd *PipelineData) Entries() int {
 	return len(pd.NamedDayRedmineValues)
 }
 
+// SortedKeys returns the pipeline names in lexical order.
+func (pd *PipelineData) SortedKeys() []string {
+	keys := make([]string, 0, len(pd.NamedDayRedmineValues))
+	for k := range pd.NamedDayRedmineValues {
+		keys = append(keys, (string)(k))
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// SortedDates returns the dates of all pipelines in ascending order.
+func (pd *PipelineData) SortedDates() []string {
+	seen := map[string]bool{}
+	dates := []string{}
+	for _, workPerDay := range pd.NamedDayRedmineValues {
+		for date := range workPerDay.WorkPerDay {
+			if !seen[date] {
+				seen[date] = true
+				dates = append(dates, date)
+			}
+		}
+	}
+	sort.Strings(dates)
+
+	return dates
+}
+
 func (pd *PipelineData) AddPipeline(pipelineName string) (*RedmineWorkPerDay, error) {
 	if pipelineName == "" {
 		return nil, errors.New("pipeline name must not be empty")
@@ -42,6 +77,24 @@ func (pd *PipelineData) AddPipeline(pipelineName string) (*RedmineWorkPerDay, er
 	return pipeline, nil
 }
 
+// FilterDates returns a copy of the pipeline data which only contains dates within the given range. Pipelines are kept
+// even if all of their dates were removed.
+func (pd *PipelineData) FilterDates(dateRange DateRange) *PipelineData {
+	result := NewPipelineData()
+
+	for name, workPerDay := range pd.NamedDayRedmineValues {
+		pipeline := newRedmineWorkPerDay()
+		for date, workTime := range workPerDay.WorkPerDay {
+			if dateRange.Contains(date) {
+				pipeline.PutWorkTime(date, workTime)
+			}
+		}
+		result.NamedDayRedmineValues[name] = pipeline
+	}
+
+	return result
+}
+
 // RedmineWorkPerDay maps a date string to the accumulated amount of time spent, f. i. 2021-05-05 -> 5.75
 type RedmineWorkPerDay struct {
 	WorkPerDay map[string]float64
@@ -67,6 +120,27 @@ func (rwpd *RedmineWorkPerDay) WorkTime(date string) float64 {
 	return rwpd.WorkPerDay[date]
 }
 
+// TotalWorkTime returns the sum of work time over all days.
+func (rwpd *RedmineWorkPerDay) TotalWorkTime() float64 {
+	result := 0.0
+	for _, date := range rwpd.SortedKeys() {
+		result += rwpd.WorkPerDay[date]
+	}
+
+	return result
+}
+
+// SortedKeys returns the dates in lexical order.
+func (rwpd *RedmineWorkPerDay) SortedKeys() []string {
+	keys := make([]string, 0, len(rwpd.WorkPerDay))
+	for k := range rwpd.WorkPerDay {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
 // CrunchedOutput contains mappings from pipeline name to Sage compatible work time
 type CrunchedOutput struct {
 	NamedDaySageValues map[PipelineName]*SageWorkPerDay
@@ -77,7 +151,7 @@ func (co *CrunchedOutput) String() string {
 
 	sortedKeys := co.SortedKeys()
 	for _, dayValue := range sortedKeys {
-		logrus.Info("String: dayValue: " + dayValue)
+		log.Info("String: dayValue: " + dayValue)
 		result += fmt.Sprintf("p: %s", co.NamedDaySageValues[(PipelineName)(dayValue)])
 	}
 
@@ -154,7 +228,7 @@ func (swpd *SageWorkPerDay) TimeSlots(day string) []TimeSlot {
 }
 
 func (swpd *SageWorkPerDay) PutEmptyTimeSlot(day string) {
-	swpd.PutTimeSlot(day, "-", "-")
+	swpd.PutTimeSlot(day, emptyTimeSlotMarker, emptyTimeSlotMarker)
 }
 
 // TimeSlot represents a dateless wall clock interval of work, f. i. from 13:00 till 14:15
@@ -169,6 +243,29 @@ func (t *TimeSlot) String() string {
 	return fmt.Sprintf(timeSlotFormat, t.Start, t.End)
 }
 
+// IsEmpty returns true for the placeholder of a day without work time.
+func (t *TimeSlot) IsEmpty() bool {
+	return t.Start == emptyTimeSlotMarker && t.End == emptyTimeSlotMarker
+}
+
+// Duration returns the time between start and end. Empty time slots last zero.
+func (t *TimeSlot) Duration() (time.Duration, error) {
+	if t.IsEmpty() {
+		return 0, nil
+	}
+
+	start, err := time.Parse(WallClockLayout, t.Start)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse start of time slot %s", t.String())
+	}
+	end, err := time.Parse(WallClockLayout, t.End)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse end of time slot %s", t.String())
+	}
+
+	return end.Sub(start), nil
+}
+
 // PipelineName contains the name of a pipeline.
 type PipelineName string
 
@@ -183,18 +280,23 @@ func NewDayTimeCounter(workStartTime string) *DayTimeCounter {
 	return &DayTimeCounter{counters: counters, defaultWorkStartTime: workStartTime}
 }
 
-func (dtc *DayTimeCounter) GetNextTimeSlotOrDefault(date string) time.Time {
+// GetNextTimeSlotOrDefault returns the end of the last time slot of the date or the work start time if the date has
+// no time slots yet. Malformed dates result in a DateError.
+func (dtc *DayTimeCounter) GetNextTimeSlotOrDefault(date string) (time.Time, error) {
 	result, ok := dtc.counters[date]
 	if !ok {
+		if _, err := time.Parse(DateLayout, date); err != nil {
+			return time.Time{}, NewDateError(date, err)
+		}
 		goodMorning, err := ParseDateWithTime(date, dtc.defaultWorkStartTime)
 		if err != nil {
-			panic("could not get end time for day: " + err.Error())
+			return time.Time{}, errors.Wrapf(err, "could not get start time of %s", date)
 		}
 		dtc.counters[date] = goodMorning
-		return goodMorning
+		return goodMorning, nil
 	}
 
-	return result
+	return result, nil
 }
 func (dtc *DayTimeCounter) EndTime(date string, endTime time.Time) {
 	dtc.counters[date] = endTime
@@ -1,15 +1,35 @@
 package core
 
 import (
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 const theDate = "2021-05-05"
 const startTime = "08:00"
 const endTime = "09:00"
 
+func TestPipelineData_SortedDates(t *testing.T) {
+	t.Run("should return the dates of all pipelines once in ascending order", func(t *testing.T) {
+		sut := NewPipelineData()
+		pipelineA, _ := sut.AddPipeline("A")
+		pipelineA.PutWorkTime("2021-05-06", 1)
+		pipelineA.PutWorkTime(theDate, 2)
+		pipelineB, _ := sut.AddPipeline("B")
+		pipelineB.PutWorkTime("2021-05-04", 3)
+		pipelineB.PutWorkTime("2021-05-06", 0)
+
+		// when
+		actual := sut.SortedDates()
+
+		// then
+		assert.Equal(t, []string{"2021-05-04", theDate, "2021-05-06"}, actual)
+	})
+}
+
 func TestRedmineWorkPerDay_PutWorkTime(t *testing.T) {
 	t.Run("should add another work time", func(t *testing.T) {
 		sut := newRedmineWorkPerDay()
@@ -91,3 +111,76 @@ func TestSageWorkPerDay_timeSlot(t *testing.T) {
 		assert.Equal(t, expected, actual)
 	})
 }
+
+func TestPipelineData_FilterDates(t *testing.T) {
+	t.Run("should keep only dates within range", func(t *testing.T) {
+		sut := NewPipelineData()
+		pipeline, _ := sut.AddPipeline("Pipeline A")
+		pipeline.PutWorkTime("2021-05-02", 1)
+		pipeline.PutWorkTime("2021-05-03", 2)
+		pipeline.PutWorkTime("2021-05-10", 3)
+		pipeline.PutWorkTime("Gesamtzeit", 6)
+		dateRange, _ := ParseISOWeek("2021-W18")
+
+		// when
+		actual := sut.FilterDates(dateRange)
+
+		// then
+		expected := NewPipelineData()
+		expectedPipeline, _ := expected.AddPipeline("Pipeline A")
+		expectedPipeline.PutWorkTime("2021-05-03", 2)
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, 4, pipeline.Days())
+	})
+}
+
+func TestTimeSlot_Duration(t *testing.T) {
+	t.Run("should return duration", func(t *testing.T) {
+		sut := TimeSlot{Start: "08:00", End: "12:15"}
+
+		actual, err := sut.Duration()
+
+		require.NoError(t, err)
+		assert.Equal(t, 4*time.Hour+15*time.Minute, actual)
+	})
+	t.Run("should return zero for empty time slot", func(t *testing.T) {
+		sut := SageWorkPerDay{}
+		sut.PutEmptyTimeSlot(theDate)
+
+		actual, err := sut.TimeSlots(theDate)[0].Duration()
+
+		require.NoError(t, err)
+		assert.True(t, sut.TimeSlots(theDate)[0].IsEmpty())
+		assert.Equal(t, time.Duration(0), actual)
+	})
+	t.Run("should fail for malformed time", func(t *testing.T) {
+		sut := TimeSlot{Start: "8 o'clock", End: "12:15"}
+
+		_, err := sut.Duration()
+
+		require.Error(t, err)
+	})
+}
+
+func TestDayTimeCounter_GetNextTimeSlotOrDefault(t *testing.T) {
+	t.Run("should start the day at the work start time", func(t *testing.T) {
+		sut := NewDayTimeCounter("08:00:00")
+
+		// when
+		actual, err := sut.GetNextTimeSlotOrDefault("2021-05-03")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2021, time.May, 3, 8, 0, 0, 0, time.UTC), actual)
+	})
+	t.Run("should fail for invalid dates", func(t *testing.T) {
+		sut := NewDayTimeCounter("08:00:00")
+
+		// when
+		_, err := sut.GetNextTimeSlotOrDefault("Gesamtzeit")
+
+		// then
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrInvalidDate))
+	})
+}
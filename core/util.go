@@ -14,3 +14,14 @@ func ParseDateWithTime(dateYYYYMMDD, timeHHMMSS string) (time.Time, error) {
 
 	return parsed, nil
 }
+
+// ContainsString returns true if the values contain the given value.
+func ContainsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+
+	return false
+}
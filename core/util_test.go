@@ -21,3 +21,13 @@ func TestParseDateWithTime(t *testing.T) {
 		require.Error(t, err)
 	})
 }
+
+func TestContainsString(t *testing.T) {
+	t.Run("should find contained value", func(t *testing.T) {
+		assert.True(t, ContainsString([]string{"A", "B"}, "B"))
+	})
+	t.Run("should not find missing value", func(t *testing.T) {
+		assert.False(t, ContainsString([]string{"A", "B"}, "b"))
+		assert.False(t, ContainsString(nil, "A"))
+	})
+}
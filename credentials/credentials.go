@@ -0,0 +1,171 @@
+package credentials
+
+import (
+	"bytes"
+	"github.com/pkg/errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Environment variables which contain Redmine credentials.
+const (
+	EnvAPIKey   = "REDSAGE_REDMINE_API_KEY"
+	EnvUser     = "REDSAGE_REDMINE_USER"
+	EnvPassword = "REDSAGE_REDMINE_PASSWORD"
+)
+
+const redacted = "[redacted]"
+
+// Secret contains a confidential value like a password or an API key. Secrets are redacted when they are formatted
+// or marshalled so that they do not end up in logs, errors or printed configurations.
+type Secret string
+
+// String returns a placeholder instead of the secret.
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// GoString returns a placeholder instead of the secret for the %#v verb.
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+// MarshalText returns a placeholder instead of the secret for JSON and YAML encoders.
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Reveal returns the confidential value. It must only be used to authenticate against Redmine.
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+// Credentials authenticate against the Redmine REST API either by an API key or by user and password.
+type Credentials struct {
+	APIKey   Secret
+	User     string
+	Password Secret
+}
+
+// IsEmpty returns true if neither an API key nor a user is known.
+func (c Credentials) IsEmpty() bool {
+	return c.APIKey == "" && c.User == ""
+}
+
+// Options select the sources of the credentials.
+type Options struct {
+	// Host is the Redmine host which is looked up in the netrc file.
+	Host string
+	// User overrides the user of all other sources.
+	User string
+	// PasswordCommand is a shell command which prints the password, or the API key if no user is known, f. i.
+	// "pass show redmine".
+	PasswordCommand string
+	// NetrcFile is the path of a netrc file. Empty uses ~/.netrc if it exists.
+	NetrcFile string
+	// LookupEnv reads environment variables. Nil uses os.LookupEnv.
+	LookupEnv func(name string) (string, bool)
+}
+
+// Resolve returns the credentials of the first source which provides them: the environment variables, the password
+// command and the netrc file. Secrets are never accepted as command line arguments so that they are not kept in the
+// shell history.
+func Resolve(options Options) (Credentials, error) {
+	lookupEnv := options.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	result := Credentials{User: options.User}
+	if result.User == "" {
+		result.User, _ = lookupEnv(EnvUser)
+	}
+
+	if apiKey, ok := lookupEnv(EnvAPIKey); ok && apiKey != "" {
+		result.APIKey = Secret(apiKey)
+		return result, nil
+	}
+	if password, ok := lookupEnv(EnvPassword); ok && password != "" && result.User != "" {
+		result.Password = Secret(password)
+		return result, nil
+	}
+
+	if options.PasswordCommand != "" {
+		secret, err := runPasswordCommand(options.PasswordCommand)
+		if err != nil {
+			return Credentials{}, err
+		}
+		if result.User == "" {
+			result.APIKey = secret
+		} else {
+			result.Password = secret
+		}
+		return result, nil
+	}
+
+	netrcFile, err := netrcPath(options.NetrcFile)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if netrcFile == "" {
+		return result, checkPassword(result)
+	}
+
+	machine, err := LookupNetrc(netrcFile, options.Host)
+	if err != nil {
+		return Credentials{}, err
+	}
+	switch {
+	case machine.Login == "":
+		result.APIKey = machine.Password
+	case result.User != "" && result.User != machine.Login:
+		return Credentials{}, errors.Errorf("netrc file %s contains the login %s instead of the Redmine user %s",
+			netrcFile, machine.Login, result.User)
+	default:
+		result.User = machine.Login
+		result.Password = machine.Password
+	}
+
+	return result, checkPassword(result)
+}
+
+// checkPassword fails for a known user without password instead of authenticating with an empty password.
+func checkPassword(creds Credentials) error {
+	if creds.User != "" && creds.APIKey == "" && creds.Password == "" {
+		return errors.Errorf("no password found for Redmine user %s (set $%s, a password command or a netrc file)",
+			creds.User, EnvPassword)
+	}
+
+	return nil
+}
+
+// runPasswordCommand runs the command with the shell and returns the first line of its output. The output is not
+// part of any error.
+func runPasswordCommand(command string) (Secret, error) {
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+
+	cmd := exec.Command(shell, flag, command)
+	stdout := &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return "", errors.Wrapf(err, "password command '%s' failed", command)
+	}
+
+	secret := strings.TrimSpace(strings.SplitN(stdout.String(), "\n", 2)[0])
+	if secret == "" {
+		return "", errors.Errorf("password command '%s' printed no password", command)
+	}
+
+	return Secret(secret), nil
+}
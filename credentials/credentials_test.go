@@ -0,0 +1,110 @@
+package credentials
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func lookupIn(env map[string]string) func(name string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+func TestSecret(t *testing.T) {
+	creds := Credentials{APIKey: "0123456789abcdef", User: "jdoe", Password: "s3cret"}
+
+	t.Run("should redact secrets when formatted", func(t *testing.T) {
+		for _, verb := range []string{"%s", "%v", "%+v", "%#v"} {
+			actual := fmt.Sprintf(verb, creds)
+
+			assert.NotContains(t, actual, "0123456789abcdef", verb)
+			assert.NotContains(t, actual, "s3cret", verb)
+		}
+	})
+	t.Run("should redact secrets when marshalled", func(t *testing.T) {
+		actual, err := json.Marshal(creds)
+
+		require.NoError(t, err)
+		assert.Equal(t, `{"APIKey":"[redacted]","User":"jdoe","Password":"[redacted]"}`, string(actual))
+	})
+	t.Run("should reveal the secret on request", func(t *testing.T) {
+		assert.Equal(t, "s3cret", creds.Password.Reveal())
+	})
+}
+
+func TestResolve(t *testing.T) {
+	dir, _ := ioutil.TempDir(os.TempDir(), "redsage-credentials-")
+	defer os.RemoveAll(dir)
+	netrcFile := filepath.Join(dir, "netrc")
+	_ = ioutil.WriteFile(netrcFile, []byte("machine redmine.example.com login jdoe password fromNetrc\n"), 0600)
+
+	t.Run("should prefer the API key of the environment", func(t *testing.T) {
+		// when
+		actual, err := Resolve(Options{Host: "redmine.example.com", NetrcFile: netrcFile,
+			LookupEnv: lookupIn(map[string]string{EnvAPIKey: "0123456789abcdef"})})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "0123456789abcdef", actual.APIKey.Reveal())
+	})
+	t.Run("should read user and password of the environment", func(t *testing.T) {
+		// when
+		actual, err := Resolve(Options{LookupEnv: lookupIn(map[string]string{EnvUser: "jdoe", EnvPassword: "s3cret"})})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "jdoe", actual.User)
+		assert.Equal(t, "s3cret", actual.Password.Reveal())
+	})
+	t.Run("should run the password command", func(t *testing.T) {
+		// when
+		actual, err := Resolve(Options{User: "jdoe", PasswordCommand: "echo fromCommand", LookupEnv: lookupIn(nil)})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "fromCommand", actual.Password.Reveal())
+	})
+	t.Run("should fail for a failing password command", func(t *testing.T) {
+		// when
+		_, err := Resolve(Options{PasswordCommand: "exit 3", LookupEnv: lookupIn(nil)})
+
+		// then
+		require.Error(t, err)
+	})
+	t.Run("should fall back to the netrc file", func(t *testing.T) {
+		// when
+		actual, err := Resolve(Options{Host: "redmine.example.com", NetrcFile: netrcFile, LookupEnv: lookupIn(nil)})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "jdoe", actual.User)
+		assert.Equal(t, "fromNetrc", actual.Password.Reveal())
+	})
+	t.Run("should fail for a netrc login of another user", func(t *testing.T) {
+		// when
+		_, err := Resolve(Options{Host: "redmine.example.com", User: "alice", NetrcFile: netrcFile, LookupEnv: lookupIn(nil)})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "contains the login jdoe instead of the Redmine user alice")
+	})
+	t.Run("should fail for a user without password", func(t *testing.T) {
+		noPasswordNetrc := filepath.Join(dir, "netrc-without-password")
+		_ = ioutil.WriteFile(noPasswordNetrc, []byte("machine redmine.example.com login jdoe\n"), 0600)
+
+		// when
+		_, err := Resolve(Options{Host: "redmine.example.com", User: "jdoe", NetrcFile: noPasswordNetrc, LookupEnv: lookupIn(nil)})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no password found for Redmine user jdoe")
+	})
+}
@@ -0,0 +1,135 @@
+package credentials
+
+import (
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const netrcFileName = ".netrc"
+
+// Machine is an entry of a netrc file.
+type Machine struct {
+	Name     string
+	Login    string
+	Password Secret
+}
+
+// netrcPath returns the given path or ~/.netrc if it exists. A missing default file is no error.
+func netrcPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", nil
+	}
+
+	path = filepath.Join(home, netrcFileName)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return "", nil
+	}
+
+	return path, nil
+}
+
+// LookupNetrc returns the entry of the host from a netrc file, or the default entry if the host is not listed. The
+// file must not be accessible by other users because it contains passwords.
+//
+// Example:
+//  machine redmine.example.com login jdoe password s3cret
+//  machine redmine.example.org password 0123456789abcdef
+func LookupNetrc(path string, host string) (Machine, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Machine{}, errors.Wrapf(err, "could not read netrc file %s", path)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return Machine{}, errors.Errorf("netrc file %s must not be accessible by other users (permissions %s, use chmod 600)",
+			path, info.Mode().Perm())
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Machine{}, errors.Wrapf(err, "could not read netrc file %s", path)
+	}
+
+	machines, err := parseNetrc(string(content))
+	if err != nil {
+		return Machine{}, errors.Wrapf(err, "could not parse netrc file %s", path)
+	}
+
+	var fallback *Machine
+	for index, machine := range machines {
+		if machine.Name == host {
+			return machine, nil
+		}
+		if machine.Name == "" && fallback == nil {
+			fallback = &machines[index]
+		}
+	}
+	if fallback != nil {
+		return *fallback, nil
+	}
+
+	return Machine{}, errors.Errorf("netrc file %s contains no entry for %s", path, host)
+}
+
+// parseNetrc returns all machine entries. The default entry has an empty name. Macro definitions are skipped.
+func parseNetrc(content string) ([]Machine, error) {
+	result := []Machine{}
+	current := -1
+
+	lines := strings.Split(content, "\n")
+	for lineIndex := 0; lineIndex < len(lines); lineIndex++ {
+		tokens := strings.Fields(lines[lineIndex])
+		for index := 0; index < len(tokens); index++ {
+			token := tokens[index]
+			if strings.HasPrefix(token, "#") {
+				break
+			}
+
+			switch token {
+			case "default":
+				result = append(result, Machine{})
+				current = len(result) - 1
+				continue
+			case "macdef":
+				for lineIndex+1 < len(lines) && strings.TrimSpace(lines[lineIndex+1]) != "" {
+					lineIndex++
+				}
+				index = len(tokens)
+				continue
+			}
+
+			if index+1 >= len(tokens) {
+				return nil, errors.Errorf("line %d: missing value", lineIndex+1)
+			}
+			index++
+			value := tokens[index]
+
+			switch token {
+			case "machine":
+				result = append(result, Machine{Name: value})
+				current = len(result) - 1
+			case "login", "password", "account":
+				if current < 0 {
+					return nil, errors.Errorf("line %d: '%s' outside of a machine entry", lineIndex+1, token)
+				}
+				if token == "login" {
+					result[current].Login = value
+				} else if token == "password" {
+					result[current].Password = Secret(value)
+				}
+			default:
+				return nil, errors.Errorf("line %d: unexpected token", lineIndex+1)
+			}
+		}
+	}
+
+	return result, nil
+}
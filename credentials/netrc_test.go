@@ -0,0 +1,84 @@
+package credentials
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+const netrcContent = `# Redmine instances
+machine redmine.example.com
+  login jdoe
+  password s3cret
+
+macdef init
+cd /pub
+bin
+
+machine redmine.example.org password 0123456789abcdef
+default login anonymous password guest
+`
+
+func TestLookupNetrc(t *testing.T) {
+	dir, _ := ioutil.TempDir(os.TempDir(), "redsage-netrc-")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "netrc")
+	_ = ioutil.WriteFile(path, []byte(netrcContent), 0600)
+
+	t.Run("should find the machine", func(t *testing.T) {
+		// when
+		actual, err := LookupNetrc(path, "redmine.example.com")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "jdoe", actual.Login)
+		assert.Equal(t, "s3cret", actual.Password.Reveal())
+	})
+	t.Run("should find a machine without login", func(t *testing.T) {
+		// when
+		actual, err := LookupNetrc(path, "redmine.example.org")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "", actual.Login)
+		assert.Equal(t, "0123456789abcdef", actual.Password.Reveal())
+	})
+	t.Run("should fall back to the default entry", func(t *testing.T) {
+		// when
+		actual, err := LookupNetrc(path, "redmine.example.net")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "anonymous", actual.Login)
+	})
+	t.Run("should refuse files which other users may read", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("permissions are not checked on Windows")
+		}
+		openPath := filepath.Join(dir, "open-netrc")
+		_ = ioutil.WriteFile(openPath, []byte(netrcContent), 0644)
+		_ = os.Chmod(openPath, 0644)
+
+		// when
+		_, err := LookupNetrc(openPath, "redmine.example.com")
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "chmod 600")
+	})
+	t.Run("should not reveal passwords in parse errors", func(t *testing.T) {
+		brokenPath := filepath.Join(dir, "broken-netrc")
+		_ = ioutil.WriteFile(brokenPath, []byte("machine example.com password my secret\n"), 0600)
+
+		// when
+		_, err := LookupNetrc(brokenPath, "example.com")
+
+		// then
+		require.Error(t, err)
+		assert.NotContains(t, err.Error(), "secret")
+	})
+}
@@ -0,0 +1,335 @@
+package cruncher
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	anchorPipelineSeparator = "="
+	anchorWindowSeparator   = "-"
+	anchorWeekdaySeparator  = "@"
+	weekdayListSeparator    = ","
+	weekdayRangeSeparator   = "-"
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Anchor places the work time of a pipeline into a fixed window of the day before all other pipelines are placed,
+// f. i. a daily stand-up. If the pipeline has less work time on a day the window is shortened, work time beyond the
+// window is placed like the work time of other pipelines.
+type Anchor struct {
+	Pipeline string `yaml:"pipeline"`
+	// Start and End contain the window in 24-hour format, f. i. "09:00" and "09:15".
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+	// Weekdays restricts the anchor to days or ranges of days, f. i. "mon-fri" or "sat". Empty applies it to every day.
+	Weekdays []string `yaml:"weekdays,omitempty"`
+}
+
+// ParseAnchor parses an anchor of the form pipeline=HH:MM-HH:MM[@weekday[,weekday...]], f. i.
+// "Pipeline A=09:00-09:15@mon-fri".
+func ParseAnchor(spec string) (Anchor, error) {
+	parts := strings.SplitN(spec, anchorPipelineSeparator, 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return Anchor{}, errors.Errorf("anchor '%s' must look like pipeline=HH:MM-HH:MM[@weekdays]", spec)
+	}
+	result := Anchor{Pipeline: strings.TrimSpace(parts[0])}
+
+	windowAndWeekdays := strings.SplitN(parts[1], anchorWeekdaySeparator, 2)
+	window := strings.SplitN(windowAndWeekdays[0], anchorWindowSeparator, 2)
+	if len(window) != 2 {
+		return Anchor{}, errors.Errorf("anchor '%s' must look like pipeline=HH:MM-HH:MM[@weekdays]", spec)
+	}
+	result.Start = strings.TrimSpace(window[0])
+	result.End = strings.TrimSpace(window[1])
+	if len(windowAndWeekdays) == 2 {
+		for _, weekday := range strings.Split(windowAndWeekdays[1], weekdayListSeparator) {
+			result.Weekdays = append(result.Weekdays, strings.TrimSpace(weekday))
+		}
+	}
+
+	_, err := parseAnchor(result)
+	if err != nil {
+		return Anchor{}, err
+	}
+
+	return result, nil
+}
+
+// String returns the anchor in the form which ParseAnchor accepts.
+func (a Anchor) String() string {
+	result := a.Pipeline + anchorPipelineSeparator + a.Start + anchorWindowSeparator + a.End
+	if len(a.Weekdays) > 0 {
+		result += anchorWeekdaySeparator + strings.Join(a.Weekdays, weekdayListSeparator)
+	}
+
+	return result
+}
+
+// parsedAnchor contains the validated window and weekdays of an anchor.
+type parsedAnchor struct {
+	pipeline string
+	start    string
+	end      string
+	weekdays map[time.Weekday]bool
+}
+
+func parseAnchors(pdata *core.PipelineData, anchors []Anchor) ([]parsedAnchor, error) {
+	result := make([]parsedAnchor, 0, len(anchors))
+	for _, anchor := range anchors {
+		if _, ok := pdata.NamedDayRedmineValues[core.PipelineName(anchor.Pipeline)]; anchor.Pipeline != "" && !ok {
+			return nil, errors.Errorf("pipeline '%s' of anchor '%s' does not exist", anchor.Pipeline, anchor)
+		}
+		parsed, err := parseAnchor(anchor)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, parsed)
+	}
+
+	return result, nil
+}
+
+func parseAnchor(anchor Anchor) (parsedAnchor, error) {
+	if anchor.Pipeline == "" {
+		return parsedAnchor{}, errors.Errorf("anchor '%s' misses a pipeline", anchor)
+	}
+
+	start, err := time.Parse(wallClockLayout, anchor.Start)
+	if err != nil {
+		return parsedAnchor{}, errors.Errorf("invalid start time '%s' of anchor '%s' (expected format: HH:MM)", anchor.Start, anchor)
+	}
+	end, err := time.Parse(wallClockLayout, anchor.End)
+	if err != nil {
+		return parsedAnchor{}, errors.Errorf("invalid end time '%s' of anchor '%s' (expected format: HH:MM)", anchor.End, anchor)
+	}
+	if !end.After(start) {
+		return parsedAnchor{}, errors.Errorf("anchor '%s' must end after it starts", anchor)
+	}
+
+	weekdays, err := parseWeekdays(anchor.Weekdays)
+	if err != nil {
+		return parsedAnchor{}, errors.Wrapf(err, "invalid weekdays of anchor '%s'", anchor)
+	}
+
+	return parsedAnchor{
+		pipeline: anchor.Pipeline,
+		start:    start.Format(wallClockLayout),
+		end:      end.Format(wallClockLayout),
+		weekdays: weekdays,
+	}, nil
+}
+
+// parseWeekdays converts days like "mon" and ranges like "mon-fri" into a set of weekdays. An empty list contains all
+// weekdays.
+func parseWeekdays(values []string) (map[time.Weekday]bool, error) {
+	result := map[time.Weekday]bool{}
+	if len(values) == 0 {
+		for _, weekday := range weekdayNames {
+			result[weekday] = true
+		}
+		return result, nil
+	}
+
+	for _, value := range values {
+		bounds := strings.SplitN(strings.ToLower(strings.TrimSpace(value)), weekdayRangeSeparator, 2)
+		first, ok := weekdayNames[bounds[0]]
+		if !ok {
+			return nil, errors.Errorf("unknown weekday '%s' (expected mon, tue, wed, thu, fri, sat or sun)", value)
+		}
+		last := first
+		if len(bounds) == 2 {
+			last, ok = weekdayNames[bounds[1]]
+			if !ok {
+				return nil, errors.Errorf("unknown weekday '%s' (expected mon, tue, wed, thu, fri, sat or sun)", value)
+			}
+		}
+
+		for weekday := first; ; weekday = (weekday + 1) % 7 {
+			result[weekday] = true
+			if weekday == last {
+				break
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// interval is a part of a day which other work time flows around, f. i. the lunch break or an anchored slot.
+type interval struct {
+	start time.Time
+	end   time.Time
+	// anchor contains the pipeline of an anchored slot. It is empty for the lunch break.
+	anchor string
+}
+
+// anchoredDay contains the anchored slots of a day and the intervals which the remaining work time must avoid.
+type anchoredDay struct {
+	// slots contains the anchored slots per pipeline.
+	slots map[string][]interval
+	// minutes contains the anchored minutes per pipeline.
+	minutes map[string]int
+	// blocked contains the lunch break and all anchored slots ordered by start.
+	blocked []interval
+	lunch   interval
+}
+
+// anchorDay places the anchors of the day's weekday. The work time of the pipelines in minutes limits how much of each
+// window is used.
+func anchorDay(day string, anchors []parsedAnchor, minutes map[string]int, lunchStartTime string, lunchBreakInMin int) (anchoredDay, error) {
+	date, err := time.Parse(core.DateLayout, day)
+	if err != nil {
+		return anchoredDay{}, core.NewDateError(day, err)
+	}
+	lunchStart, err := core.ParseDateWithTime(day, lunchStartTime+":00")
+	if err != nil {
+		return anchoredDay{}, err
+	}
+
+	result := anchoredDay{slots: map[string][]interval{}, minutes: map[string]int{}}
+	// a lunch break of zero minutes still splits slots at lunch time
+	result.lunch = interval{start: lunchStart, end: lunchStart.Add(time.Duration(lunchBreakInMin) * time.Minute)}
+	result.blocked = append(result.blocked, result.lunch)
+
+	for _, anchor := range anchors {
+		if !anchor.weekdays[date.Weekday()] {
+			continue
+		}
+		available := minutes[anchor.pipeline] - result.minutes[anchor.pipeline]
+		if available <= 0 {
+			continue
+		}
+
+		start, err := core.ParseDateWithTime(day, anchor.start+":00")
+		if err != nil {
+			return anchoredDay{}, err
+		}
+		end, err := core.ParseDateWithTime(day, anchor.end+":00")
+		if err != nil {
+			return anchoredDay{}, err
+		}
+		if window := int(end.Sub(start).Minutes()); window > available {
+			end = start.Add(time.Duration(available) * time.Minute)
+		}
+
+		slot := interval{start: start, end: end, anchor: anchor.pipeline}
+		for _, other := range result.blocked {
+			if other.end.After(other.start) && slot.start.Before(other.end) && other.start.Before(slot.end) {
+				return anchoredDay{}, errors.Errorf("anchor of %s at %s overlaps %s on %s",
+					anchor.pipeline, formatInterval(slot), describeInterval(other), day)
+			}
+		}
+
+		result.slots[anchor.pipeline] = append(result.slots[anchor.pipeline], slot)
+		result.minutes[anchor.pipeline] += int(end.Sub(start).Minutes())
+		result.blocked = append(result.blocked, slot)
+	}
+
+	sort.Slice(result.blocked, func(i, j int) bool { return result.blocked[i].start.Before(result.blocked[j].start) })
+	return result, nil
+}
+
+// skipBlocked moves the given time behind all blocked intervals which contain it. The intervals must be ordered by
+// start.
+func skipBlocked(current time.Time, blocked []interval, placement *Placement) time.Time {
+	for _, other := range blocked {
+		if !current.Before(other.start) && current.Before(other.end) {
+			current = other.end
+			placement.decide(DecisionShift, "start lies within the %s, shifted to %s", describeInterval(other), current.Format(wallClockLayout))
+		}
+	}
+
+	return current
+}
+
+// flow places the given minutes from start on and splits them around the blocked intervals. The slot rules may move
+// the lunch break instead of splitting off a short slot. It returns the slots and the end of the last slot.
+func (d *anchoredDay) flow(start time.Time, minutes int, rules slotRules, placement *Placement) ([]interval, time.Time) {
+	result := []interval{}
+	current := skipBlocked(start, d.blocked, placement)
+	remaining := time.Duration(minutes) * time.Minute
+
+	for {
+		end := current.Add(remaining)
+		next, ok := nextBlocked(current, end, d.blocked)
+		if ok && next.anchor == "" && d.moveLunch(current, end, rules, placement) {
+			current = skipBlocked(current, d.blocked, placement)
+			end = current.Add(remaining)
+			next, ok = nextBlocked(current, end, d.blocked)
+		}
+		if !ok {
+			result = append(result, interval{start: current, end: end})
+			return result, end
+		}
+
+		result = append(result, interval{start: current, end: next.start})
+		remaining -= next.start.Sub(current)
+		if next.anchor == "" {
+			placement.decide(DecisionSplit, "ends %s after lunch start %s, split around a %d min break",
+				end.Sub(next.start), next.start.Format(wallClockLayout), int(next.end.Sub(next.start).Minutes()))
+		} else {
+			placement.decide(DecisionSplit, "ends %s after the %s starts, continued at %s",
+				end.Sub(next.start), describeInterval(next), next.end.Format(wallClockLayout))
+		}
+		current = skipBlocked(next.end, d.blocked, placement)
+	}
+}
+
+// nextBlocked returns the first blocked interval which starts after the start of the given slot and before its end.
+func nextBlocked(start time.Time, end time.Time, blocked []interval) (interval, bool) {
+	for _, other := range blocked {
+		if other.start.After(start) && other.start.Before(end) {
+			return other, true
+		}
+	}
+
+	return interval{}, false
+}
+
+// workMinutes returns the work time of all pipelines on a day in whole minutes. Both crunchers truncate to minutes so
+// that they book the same totals.
+func workMinutes(pdata *core.PipelineData, day string) map[string]int {
+	result := map[string]int{}
+	for name, workPerDay := range pdata.NamedDayRedmineValues {
+		result[string(name)] = int(workPerDay.WorkTime(day) * 60)
+	}
+
+	return result
+}
+
+// sortTimeSlots orders the slots of a pipeline on a day by their start and merges adjacent slots.
+func sortTimeSlots(pipeline *core.SageWorkPerDay, day string) {
+	slots := (*pipeline)[day]
+	sort.SliceStable(slots, func(i, j int) bool { return slots[i].Start < slots[j].Start })
+
+	result := []core.TimeSlot{}
+	for _, slot := range slots {
+		if last := len(result) - 1; last >= 0 && !slot.IsEmpty() && result[last].End == slot.Start {
+			result[last].End = slot.End
+			continue
+		}
+		result = append(result, slot)
+	}
+	(*pipeline)[day] = result
+}
+
+func formatInterval(value interval) string {
+	return value.start.Format(wallClockLayout) + " - " + value.end.Format(wallClockLayout)
+}
+
+func describeInterval(value interval) string {
+	if value.anchor == "" {
+		return "lunch break"
+	}
+
+	return fmt.Sprintf("anchor of %s at %s", value.anchor, formatInterval(value))
+}
@@ -0,0 +1,125 @@
+package cruncher
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParseAnchor(t *testing.T) {
+	t.Run("should parse pipeline, window and weekdays", func(t *testing.T) {
+		// when
+		actual, err := ParseAnchor("Pipeline A=09:00-09:15@mon-fri,sun")
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, Anchor{Pipeline: "Pipeline A", Start: "09:00", End: "09:15", Weekdays: []string{"mon-fri", "sun"}}, actual)
+		assert.Equal(t, "Pipeline A=09:00-09:15@mon-fri,sun", actual.String())
+	})
+	t.Run("should fail for malformed anchors", func(t *testing.T) {
+		for _, spec := range []string{"Pipeline A", "=09:00-09:15", "Pipeline A=09:00", "Pipeline A=9-10", "Pipeline A=10:00-09:00",
+			"Pipeline A=09:00-09:15@someday"} {
+			// when
+			_, err := ParseAnchor(spec)
+
+			// then
+			assert.Error(t, err, spec)
+		}
+	})
+}
+
+func Test_parseWeekdays(t *testing.T) {
+	t.Run("should wrap ranges around the weekend", func(t *testing.T) {
+		// when
+		actual, err := parseWeekdays([]string{"Sat-Mon"})
+
+		// then
+		require.NoError(t, err)
+		assert.Len(t, actual, 3)
+		assert.True(t, actual[6])
+		assert.True(t, actual[0])
+		assert.True(t, actual[1])
+	})
+}
+
+func Test_cruncher_CrunchWithAnchors(t *testing.T) {
+	input := core.NewPipelineData()
+	pipelineA, _ := input.AddPipeline(pipelineAName)
+	pipelineA.PutWorkTime(date3, 1)
+	pipelineA.PutWorkTime(date4, 0.1)
+	pipelineB, _ := input.AddPipeline(pipelineBName)
+	pipelineB.PutWorkTime(date3, 4)
+	pipelineB.PutWorkTime(date4, 4)
+	standUp := Anchor{Pipeline: pipelineBName, Start: "09:00", End: "09:15"}
+
+	t.Run("should place anchors first and fill the gaps around them", func(t *testing.T) {
+		config := Config{LunchBreakInMin: 60, Anchors: []Anchor{standUp, {Pipeline: pipelineAName, Start: "18:00", End: "18:30"}}}
+
+		// when
+		actual, err := New().Crunch(input, config)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "08:30"}, {Start: "18:00", End: "18:30"}},
+			actual.NamedDaySageValues[pipelineAName].TimeSlots(date3))
+		assert.Equal(t, []core.TimeSlot{{Start: "08:30", End: "12:00"}, {Start: "13:00", End: "13:30"}},
+			actual.NamedDaySageValues[pipelineBName].TimeSlots(date3))
+	})
+	t.Run("should shorten the window to the work time of the day", func(t *testing.T) {
+		config := Config{LunchBreakInMin: 60, Anchors: []Anchor{{Pipeline: pipelineAName, Start: "09:00", End: "09:15"}}}
+
+		// when
+		actual, err := New().Crunch(input, config)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "09:00", End: "09:06"}}, actual.NamedDaySageValues[pipelineAName].TimeSlots(date4))
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "09:00"}, {Start: "09:06", End: "12:00"}, {Start: "13:00", End: "13:06"}},
+			actual.NamedDaySageValues[pipelineBName].TimeSlots(date4))
+	})
+	t.Run("should apply anchors only on their weekdays", func(t *testing.T) {
+		// 2021-05-03 is a Monday
+		config := Config{LunchBreakInMin: 60, Anchors: []Anchor{{Pipeline: pipelineAName, Start: "15:00", End: "16:00", Weekdays: []string{"tue"}}}}
+
+		// when
+		actual, err := New().Crunch(input, config)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "09:00"}}, actual.NamedDaySageValues[pipelineAName].TimeSlots(date3))
+		assert.Equal(t, []core.TimeSlot{{Start: "15:00", End: "15:06"}}, actual.NamedDaySageValues[pipelineAName].TimeSlots(date4))
+	})
+	t.Run("should interleave the remaining pipelines around anchors", func(t *testing.T) {
+		config := Config{LunchBreakInMin: 60, Anchors: []Anchor{standUp}}
+
+		// when
+		actual, err := NewInterleaved().Crunch(input, config)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "09:00"}}, actual.NamedDaySageValues[pipelineAName].TimeSlots(date3))
+		assert.Equal(t, []core.TimeSlot{{Start: "09:00", End: "12:00"}, {Start: "13:00", End: "14:00"}},
+			actual.NamedDaySageValues[pipelineBName].TimeSlots(date3))
+	})
+	t.Run("should fail for anchors which overlap the lunch break", func(t *testing.T) {
+		config := Config{LunchBreakInMin: 60, Anchors: []Anchor{{Pipeline: pipelineBName, Start: "11:30", End: "12:30"}}}
+
+		// when
+		_, err := New().Crunch(input, config)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "anchor of Pipeline B at 11:30 - 12:30 overlaps lunch break on 2021-05-03")
+	})
+	t.Run("should fail for anchors of unknown pipelines", func(t *testing.T) {
+		config := Config{LunchBreakInMin: 60, Anchors: []Anchor{{Pipeline: "Stand-up", Start: "09:00", End: "09:15"}}}
+
+		// when
+		_, err := NewInterleaved().Crunch(input, config)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pipeline 'Stand-up' of anchor 'Stand-up=09:00-09:15' does not exist")
+	})
+}
@@ -3,19 +3,50 @@ package cruncher
 import (
 	"github.com/pkg/errors"
 	"github.com/ppxl/sagemine/core"
-	"github.com/sirupsen/logrus"
+	"github.com/ppxl/sagemine/logging"
+	"math"
 	"time"
 )
 
+var log = logging.Logger()
+
 const (
-	dayStartTime    = "08:00:00"
-	lunchStartTime  = "12:00:00"
-	wallClockLayout = "15:04"
+	dayStartTime = "08:00:00"
+	// DefaultLunchStartTime is used if no lunch start time is configured.
+	DefaultLunchStartTime = "12:00"
+	wallClockLayout       = "15:04"
+	// roundingTolerance hides floating point noise like 0.1 h = 6.000000000000001 min in explanations
+	roundingTolerance = 1e-6
 )
 
 // Config contains configuration values that modify the number crunching behaviour.
 type Config struct {
 	LunchBreakInMin int
+	// LunchStartTime contains the start of the lunch break in 24-hour format, f. i. "12:30". Empty starts lunch at
+	// DefaultLunchStartTime.
+	LunchStartTime string
+	// PipelineOrder contains the order in which pipelines are placed on a date. Pipelines which are not listed follow
+	// in lexical order.
+	PipelineOrder map[string][]string
+	// ChunkMinutes contains the length of the turns which the interleaved cruncher gives each pipeline. Zero uses
+	// DefaultChunkMinutes.
+	ChunkMinutes int
+	// InterleaveMode selects how the interleaved cruncher mixes the pipelines of a day, f. i. InterleaveHalfDays.
+	// Empty selects InterleaveRoundRobin.
+	InterleaveMode string
+	// Anchors contains pipelines which are placed into fixed windows of the day before all other pipelines.
+	Anchors []Anchor
+	// MinSlotMinutes avoids time slots which are shorter: smaller work times of a day are merged into another pipeline
+	// and the lunch break moves instead of splitting off a shorter slot. Zero allows slots of any length.
+	MinSlotMinutes int
+	// MergeInto names the pipeline which receives the work times below MinSlotMinutes. Empty merges them into the
+	// pipeline placed before them, or after them for the first pipeline of a day.
+	MergeInto string
+	// ConsolidateSlots moves the lunch break behind a slot instead of splitting it so that each pipeline gets as few
+	// slots as possible.
+	ConsolidateSlots bool
+	// Trace records how each time slot was produced if it is set.
+	Trace *Trace
 }
 
 // Cruncher provides methods for transforming values from a redmine pipeline data.
@@ -27,7 +58,8 @@ type Cruncher interface {
 type cruncher struct {
 }
 
-func New() *cruncher {
+// New returns the cruncher which places the pipelines of a day one after another from 08:00 on.
+func New() Cruncher {
 	return &cruncher{}
 }
 
@@ -38,47 +70,111 @@ func (c *cruncher) Crunch(pdata *core.PipelineData, config Config) (*core.Crunch
 	output := core.NewCrunchedOutput()
 	dayTimeCounter := core.NewDayTimeCounter(dayStartTime)
 
-	for redminePipeline, workPerDay := range pdata.NamedDayRedmineValues {
-		logrus.Debugf("Add new pipeline %s", redminePipeline)
-		pipelineName := string(redminePipeline)
+	lunchStartTime, err := parseLunchStartTime(config.LunchStartTime)
+	if err != nil {
+		return nil, err
+	}
+	anchors, err := parseAnchors(pdata, config.Anchors)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := newSlotRules(pdata, config)
+	if err != nil {
+		return nil, err
+	}
+
+	pipelineNames := pdata.SortedKeys()
+	for _, pipelineName := range pipelineNames {
+		log.Debugf("Add new pipeline %s", pipelineName)
+		_, err := output.AddPipeline(pipelineName)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while crunching time data")
+		}
+	}
 
-		pipeline, err := output.AddPipeline(pipelineName)
+	for _, day := range pdata.SortedDates() {
+		pipelinesOfDay := orderPipelines(pipelineNames, config.PipelineOrder[day])
+		plan := planDay(workMinutes(pdata, day), pipelinesOfDay, rules)
+		anchored, err := anchorDay(day, anchors, plan.minutes, lunchStartTime, config.LunchBreakInMin)
 		if err != nil {
 			return nil, errors.Wrap(err, "error while crunching time data")
 		}
 
-		for day, worktime := range workPerDay.WorkPerDay {
-			currentDayAndTime := dayTimeCounter.GetNextTimeSlotOrDefault(day)
+		for _, pipelineName := range pipelinesOfDay {
+			workPerDay := pdata.NamedDayRedmineValues[core.PipelineName(pipelineName)]
+			if _, ok := workPerDay.WorkPerDay[day]; !ok && plan.minutes[pipelineName] == 0 {
+				continue
+			}
+			pipeline := output.NamedDaySageValues[core.PipelineName(pipelineName)]
+
+			worktime := workPerDay.WorkTime(day)
+			currentDayAndTime, err := dayTimeCounter.GetNextTimeSlotOrDefault(day)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error while crunching time data for pipeline %s", pipelineName)
+			}
+			placement := config.Trace.place(day, pipelineName, worktime, currentDayAndTime)
 
-			if containsNoWorkTime(worktime) {
+			if target, ok := plan.mergedInto[pipelineName]; ok {
+				placement.decide(DecisionMerge, "%d min are shorter than the minimum slot of %d min, merged into %s",
+					int(worktime*60), rules.minSlotMinutes, target)
 				pipeline.PutEmptyTimeSlot(day)
+				placement.finish(0, currentDayAndTime)
+				continue
+			}
+			if containsNoWorkTime(worktime) && plan.minutes[pipelineName] == 0 {
+				placement.decide(DecisionEmpty, "no work time, adding an empty slot")
+				pipeline.PutEmptyTimeSlot(day)
+				placement.finish(0, currentDayAndTime)
 				continue
 			}
 
-			start := currentDayAndTime.Format(wallClockLayout)
+			placement.decide(DecisionPlace, "starts at %s", currentDayAndTime.Format(wallClockLayout))
+			if received := plan.received[pipelineName]; received > 0 {
+				placement.decide(DecisionMerge, "received %d min of pipelines below the minimum slot", received)
+			}
+			for _, slot := range anchored.slots[pipelineName] {
+				placement.decide(DecisionAnchor, "anchored at %s", formatInterval(slot))
+				pipeline.PutTimeSlot(day, slot.start.Format(wallClockLayout), slot.end.Format(wallClockLayout))
+				placement.slot(slot.start.Format(wallClockLayout), slot.end.Format(wallClockLayout))
+			}
 
 			// decimals don't work well with duration: Do instead manual minute calculation
-			calcedEndTime := time.Duration(worktime*60) * time.Minute
-			endTime := currentDayAndTime.Add(calcedEndTime)
-
-			diff, endTimeIntersectsWithLunchtime := endTimeFallsIntoLunch(endTime, day)
-			if endTimeIntersectsWithLunchtime {
-				logrus.Debugf("Time slot %s - %s falls into lunch by %s. Breaking up into two parts...", currentDayAndTime, endTime, diff)
-				endBeforeLunch := endTime.Add(-diff)
-				pipeline.PutTimeSlot(day, start, endBeforeLunch.Format(wallClockLayout))
-				// update current time to enable correct timing of the second slot
-				currentDayAndTime = endBeforeLunch
-
-				startAfterLunch := currentDayAndTime.Add(time.Duration(config.LunchBreakInMin) * time.Minute)
-				endAfterLunch := startAfterLunch.Add(diff)
-				pipeline.PutTimeSlot(day, startAfterLunch.Format(wallClockLayout), endAfterLunch.Format(wallClockLayout))
-				currentDayAndTime = endAfterLunch
-			} else {
-				end := endTime.Format(wallClockLayout)
-				pipeline.PutTimeSlot(day, start, end)
-				currentDayAndTime = endTime
+			redmineMinutes := int(worktime * 60)
+			minutes := plan.minutes[pipelineName]
+			remaining := minutes - anchored.minutes[pipelineName]
+			// an anchored pipeline is only placed again if its work time exceeds its anchors
+			flows := remaining > 0 || anchored.minutes[pipelineName] == 0
+			if flows {
+				currentDayAndTime = skipBlocked(currentDayAndTime, anchored.blocked, placement)
 			}
+			if math.Abs(float64(redmineMinutes)-worktime*60) > roundingTolerance {
+				placement.decide(DecisionRound, "%.4f h = %.2f min, truncated to %d min", worktime, worktime*60, redmineMinutes)
+			}
+
+			if flows {
+				endTime := currentDayAndTime.Add(time.Duration(remaining) * time.Minute)
+				_, endTimeIntersectsWithLunchtime := endTimeFallsIntoLunch(endTime, anchored.lunch.start)
+				if endTimeIntersectsWithLunchtime && !currentDayAndTime.Before(anchored.lunch.start) {
+					placement.decide(DecisionNoSplit, "starts after lunch, no split needed")
+				}
 
+				var slots []interval
+				slots, currentDayAndTime = anchored.flow(currentDayAndTime, remaining, rules, placement)
+				for _, slot := range slots {
+					log.Debugf("Placing time slot %s of %s", formatInterval(slot), pipelineName)
+					pipeline.PutTimeSlot(day, slot.start.Format(wallClockLayout), slot.end.Format(wallClockLayout))
+					placement.slot(slot.start.Format(wallClockLayout), slot.end.Format(wallClockLayout))
+				}
+			}
+			if len(anchored.slots[pipelineName]) > 0 || rules.consolidate {
+				sortTimeSlots(pipeline, day)
+			}
+			err = checkEndOfDay(day, currentDayAndTime)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error while crunching time data for pipeline %s", pipelineName)
+			}
+
+			placement.finish(minutes, currentDayAndTime)
 			dayTimeCounter.EndTime(day, currentDayAndTime)
 		}
 	}
@@ -88,18 +184,78 @@ func (c *cruncher) Crunch(pdata *core.PipelineData, config Config) (*core.Crunch
 
 // endTimeFallsIntoLunch returns false if the given time does not overlap with to configured lunch time. Otherwise true
 // and the (aboslute) duration of the overlap will be returned.
-func endTimeFallsIntoLunch(workTimeEnd time.Time, day string) (time.Duration, bool) {
-	parsedLunchtime, err := time.Parse(time.RFC3339, day+"T"+lunchStartTime+"Z")
+func endTimeFallsIntoLunch(workTimeEnd time.Time, lunchStart time.Time) (time.Duration, bool) {
+	fallsIntoLunchTime := workTimeEnd.After(lunchStart)
+	if fallsIntoLunchTime {
+		return workTimeEnd.Sub(lunchStart), true
+	}
+
+	return 0, false
+}
+
+// checkEndOfDay fails if the time slots of a day reach midnight. Their times of day would wrap around and start
+// before they end.
+func checkEndOfDay(day string, end time.Time) error {
+	midnight, err := core.ParseDateWithTime(day, "00:00:00")
 	if err != nil {
-		panic("lunchtime: " + err.Error())
+		return err
 	}
+	midnight = midnight.AddDate(0, 0, 1)
 
-	fallsIntoLunchTime := workTimeEnd.After(parsedLunchtime)
-	if fallsIntoLunchTime {
-		return workTimeEnd.Sub(parsedLunchtime), true
+	if !end.Before(midnight) {
+		return errors.Errorf("work time on %s does not fit into the day: its time slots would last until %s on %s",
+			day, end.Format(wallClockLayout), end.Format(core.DateLayout))
 	}
 
-	return 0, false
+	return nil
+}
+
+// parseLunchStartTime validates the lunch start time in 24-hour format. Lunch must not start before the work day.
+func parseLunchStartTime(lunchStartTime string) (string, error) {
+	if lunchStartTime == "" {
+		return DefaultLunchStartTime, nil
+	}
+
+	parsed, err := time.Parse(wallClockLayout, lunchStartTime)
+	if err != nil {
+		return "", errors.Errorf("invalid lunch start time '%s' (expected format: HH:MM)", lunchStartTime)
+	}
+
+	dayStart, err := time.Parse("15:04:05", dayStartTime)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid day start time")
+	}
+	if parsed.Before(dayStart) {
+		return "", errors.Errorf("lunch start time '%s' lies before the start of the work day at %s",
+			lunchStartTime, dayStart.Format(wallClockLayout))
+	}
+
+	return parsed.Format(wallClockLayout), nil
+}
+
+// orderPipelines returns the preferred pipelines first, followed by all other pipelines in their given order.
+// Preferred pipelines which do not exist are ignored.
+func orderPipelines(pipelineNames []string, preferred []string) []string {
+	known := map[string]bool{}
+	for _, name := range pipelineNames {
+		known[name] = true
+	}
+
+	result := []string{}
+	placed := map[string]bool{}
+	for _, name := range preferred {
+		if known[name] && !placed[name] {
+			placed[name] = true
+			result = append(result, name)
+		}
+	}
+	for _, name := range pipelineNames {
+		if !placed[name] {
+			result = append(result, name)
+		}
+	}
+
+	return result
 }
 
 func roundWorkTimeToNextHour(currentWorkTime time.Time) time.Time {
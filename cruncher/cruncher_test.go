@@ -1,6 +1,7 @@
 package cruncher
 
 import (
+	"github.com/pkg/errors"
 	"github.com/ppxl/sagemine/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -95,28 +96,150 @@ func Test_cruncher_Crunch(t *testing.T) {
 }
 
 func Test_endTimeFallsIntoLunch(t *testing.T) {
+	lunchStart, _ := time.Parse(time.RFC3339, date5+"T12:00:00Z")
+
 	t.Run("should be false for 11:00 < 12:00", func(t *testing.T) {
 		worktimeEnd, _ := time.Parse(time.RFC3339, date5+"T11:00:00Z")
-		actualDiff, actualHit := endTimeFallsIntoLunch(worktimeEnd, date5)
+		actualDiff, actualHit := endTimeFallsIntoLunch(worktimeEnd, lunchStart)
 		assert.False(t, actualHit)
 		assert.Equal(t, time.Duration(0), actualDiff)
 	})
 	t.Run("should be false for 12:00 == 12:00", func(t *testing.T) {
 		worktimeEnd, _ := time.Parse(time.RFC3339, date5+"T12:00:00Z")
-		actualDiff, actualHit := endTimeFallsIntoLunch(worktimeEnd, date5)
+		actualDiff, actualHit := endTimeFallsIntoLunch(worktimeEnd, lunchStart)
 		assert.False(t, actualHit)
 		assert.Equal(t, time.Duration(0), actualDiff)
 	})
 	t.Run("should be true for 12:01 > 12:00", func(t *testing.T) {
 		worktimeEnd, _ := time.Parse(time.RFC3339, date5+"T12:01:00Z")
-		actualDiff, actualMiss := endTimeFallsIntoLunch(worktimeEnd, date5)
+		actualDiff, actualMiss := endTimeFallsIntoLunch(worktimeEnd, lunchStart)
 		assert.True(t, actualMiss)
 		assert.Equal(t, 1*time.Minute, actualDiff)
 	})
 	t.Run("should be true for 13:00 > 12:00", func(t *testing.T) {
 		worktimeEnd, _ := time.Parse(time.RFC3339, date5+"T13:00:00Z")
-		actualDiff, actualMiss := endTimeFallsIntoLunch(worktimeEnd, date5)
+		actualDiff, actualMiss := endTimeFallsIntoLunch(worktimeEnd, lunchStart)
 		assert.True(t, actualMiss)
 		assert.Equal(t, 1*time.Hour, actualDiff)
 	})
 }
+
+func Test_cruncher_CrunchMultiplePipelines(t *testing.T) {
+	t.Run("should place pipelines in order and respect lunch break only once", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime(date3, 4)
+		pipelineA.PutWorkTime(date4, 5)
+		pipelineB, _ := input.AddPipeline("Pipeline B")
+		pipelineB.PutWorkTime(date3, 1.5)
+		pipelineB.PutWorkTime(date4, 1)
+
+		sut := New()
+
+		//when
+		actual, err := sut.Crunch(input, Config{LunchBreakInMin: 60})
+
+		// then
+		require.NoError(t, err)
+		expected := core.NewCrunchedOutput()
+		expectedPipelineA, _ := expected.AddPipeline(pipelineAName)
+		expectedPipelineA.PutTimeSlot(date3, "08:00", "12:00")
+		expectedPipelineA.PutTimeSlot(date4, "08:00", "12:00")
+		expectedPipelineA.PutTimeSlot(date4, "13:00", "14:00")
+		expectedPipelineB, _ := expected.AddPipeline("Pipeline B")
+		expectedPipelineB.PutTimeSlot(date3, "13:00", "14:30")
+		expectedPipelineB.PutTimeSlot(date4, "14:00", "15:00")
+		assert.Equal(t, expected, actual)
+	})
+}
+
+func Test_cruncher_CrunchSchedule(t *testing.T) {
+	input := core.NewPipelineData()
+	pipelineA, _ := input.AddPipeline(pipelineAName)
+	pipelineA.PutWorkTime(date3, 4)
+	pipelineA.PutWorkTime(date4, 4)
+	pipelineB, _ := input.AddPipeline("Pipeline B")
+	pipelineB.PutWorkTime(date3, 2)
+	pipelineB.PutWorkTime(date4, 2)
+
+	t.Run("should place pipelines in the configured order per day", func(t *testing.T) {
+		config := Config{LunchBreakInMin: 60, PipelineOrder: map[string][]string{date4: {"Pipeline B", "Unknown"}}}
+
+		// when
+		actual, err := New().Crunch(input, config)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "12:00"}}, actual.NamedDaySageValues[pipelineAName].TimeSlots(date3))
+		assert.Equal(t, []core.TimeSlot{{Start: "13:00", End: "15:00"}}, actual.NamedDaySageValues["Pipeline B"].TimeSlots(date3))
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "10:00"}}, actual.NamedDaySageValues["Pipeline B"].TimeSlots(date4))
+		assert.Equal(t, []core.TimeSlot{{Start: "10:00", End: "12:00"}, {Start: "13:00", End: "15:00"}},
+			actual.NamedDaySageValues[pipelineAName].TimeSlots(date4))
+	})
+	t.Run("should start lunch at the configured time", func(t *testing.T) {
+		config := Config{LunchBreakInMin: 30, LunchStartTime: "11:30"}
+
+		// when
+		actual, err := New().Crunch(input, config)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "11:30"}, {Start: "12:00", End: "12:30"}},
+			actual.NamedDaySageValues[pipelineAName].TimeSlots(date3))
+	})
+	t.Run("should fail for an invalid lunch start time", func(t *testing.T) {
+		// when
+		_, err := New().Crunch(input, Config{LunchStartTime: "noon"})
+
+		// then
+		require.Error(t, err)
+	})
+	t.Run("should fail for a lunch start time before the work day", func(t *testing.T) {
+		// when
+		_, err := New().Crunch(input, Config{LunchBreakInMin: 60, LunchStartTime: "07:00"})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "before the start of the work day at 08:00")
+	})
+	t.Run("should fail for invalid dates", func(t *testing.T) {
+		invalid := core.NewPipelineData()
+		pipeline, _ := invalid.AddPipeline(pipelineAName)
+		pipeline.PutWorkTime("Gesamtzeit", 8)
+
+		// when
+		_, err := New().Crunch(invalid, Config{})
+
+		// then
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, core.ErrInvalidDate))
+		var dateError *core.DateError
+		require.True(t, errors.As(err, &dateError))
+		assert.Equal(t, "Gesamtzeit", dateError.Date)
+	})
+	t.Run("should fail for work time which lasts past midnight", func(t *testing.T) {
+		long := core.NewPipelineData()
+		pipeline, _ := long.AddPipeline(pipelineAName)
+		pipeline.PutWorkTime(date3, 16.5)
+
+		// when
+		_, err := New().Crunch(long, Config{LunchBreakInMin: 60})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "work time on 2021-05-03 does not fit into the day: its time slots would last until 01:30 on 2021-05-04")
+	})
+	t.Run("should place work time which ends before midnight", func(t *testing.T) {
+		long := core.NewPipelineData()
+		pipeline, _ := long.AddPipeline(pipelineAName)
+		pipeline.PutWorkTime(date3, 14.5)
+
+		// when
+		actual, err := New().Crunch(long, Config{LunchBreakInMin: 60})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "12:00"}, {Start: "13:00", End: "23:30"}},
+			actual.NamedDaySageValues[pipelineAName].TimeSlots(date3))
+	})
+}
@@ -0,0 +1,174 @@
+package cruncher
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"io"
+	"sort"
+	"time"
+)
+
+// Kinds of decisions which the cruncher records while placing a pipeline.
+const (
+	DecisionEmpty   = "empty"
+	DecisionPlace   = "place"
+	DecisionAnchor  = "anchor"
+	DecisionMerge   = "merge"
+	DecisionLunch   = "lunch"
+	DecisionRound   = "round"
+	DecisionShift   = "shift"
+	DecisionSplit   = "split"
+	DecisionNoSplit = "no-split"
+	DecisionSlot    = "slot"
+	DecisionCounter = "counter"
+)
+
+// Decision explains a single step of placing a pipeline on a day.
+type Decision struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// Placement explains how the work time of a pipeline on a day became Sage time slots.
+type Placement struct {
+	Pipeline     string  `json:"pipeline"`
+	RedmineHours float64 `json:"redmineHours"`
+	// Minutes contains the work time after rounding to whole minutes.
+	Minutes int `json:"minutes"`
+	// CounterBefore and CounterAfter contain the day's next free time before and after the placement.
+	CounterBefore string     `json:"counterBefore"`
+	CounterAfter  string     `json:"counterAfter"`
+	Slots         []string   `json:"slots"`
+	Decisions     []Decision `json:"decisions"`
+}
+
+// DayTrace contains the placements of a day in the order the cruncher made them.
+type DayTrace struct {
+	Date       string       `json:"date"`
+	Placements []*Placement `json:"placements"`
+}
+
+// Trace records the decisions of a crunch. Pass a trace in Config.Trace to explain how each slot was produced.
+type Trace struct {
+	days map[string]*DayTrace
+}
+
+// NewTrace creates an empty trace.
+func NewTrace() *Trace {
+	return &Trace{days: map[string]*DayTrace{}}
+}
+
+// Days returns the traces of all days ordered by date.
+func (t *Trace) Days() []*DayTrace {
+	result := make([]*DayTrace, 0, len(t.days))
+	for _, day := range t.days {
+		result = append(result, day)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date < result[j].Date })
+
+	return result
+}
+
+// WriteText writes the trace as readable text, one block per day.
+//
+// Example:
+//  2021-05-06
+//    Pipeline A: 4.50 h = 270 min, counter 08:00 -> 13:30
+//      place: starts at 08:00
+//      split: ends 30m0s after lunch start 12:00, split around a 60 min break
+//      slot: 08:00 - 12:00
+//      slot: 13:00 - 13:30
+//      counter: next free time is 13:30
+func (t *Trace) WriteText(w io.Writer) error {
+	for _, day := range t.Days() {
+		_, err := fmt.Fprintln(w, day.Date)
+		if err != nil {
+			return errors.Wrap(err, "could not write explanation")
+		}
+
+		for _, placement := range day.Placements {
+			_, err = fmt.Fprintf(w, "  %s: %.2f h = %d min, counter %s -> %s\n", placement.Pipeline,
+				placement.RedmineHours, placement.Minutes, placement.CounterBefore, placement.CounterAfter)
+			if err != nil {
+				return errors.Wrap(err, "could not write explanation")
+			}
+
+			for _, decision := range placement.Decisions {
+				_, err = fmt.Fprintf(w, "    %s: %s\n", decision.Kind, decision.Message)
+				if err != nil {
+					return errors.Wrap(err, "could not write explanation")
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// WriteJSON writes the trace as a JSON object with a list of days.
+func (t *Trace) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(struct {
+		Days []*DayTrace `json:"days"`
+	}{Days: t.Days()})
+	if err != nil {
+		return errors.Wrap(err, "could not write explanation")
+	}
+
+	return nil
+}
+
+// place starts the placement of a pipeline on a day. A nil trace records nothing.
+func (t *Trace) place(date string, pipeline string, redmineHours float64, counter time.Time) *Placement {
+	if t == nil {
+		return nil
+	}
+
+	day, ok := t.days[date]
+	if !ok {
+		day = &DayTrace{Date: date}
+		t.days[date] = day
+	}
+
+	placement := &Placement{
+		Pipeline:      pipeline,
+		RedmineHours:  redmineHours,
+		CounterBefore: counter.Format(wallClockLayout),
+		Slots:         []string{},
+		Decisions:     []Decision{},
+	}
+	day.Placements = append(day.Placements, placement)
+
+	return placement
+}
+
+// decide records a decision. A nil placement records nothing.
+func (p *Placement) decide(kind string, format string, args ...interface{}) {
+	if p == nil {
+		return
+	}
+
+	p.Decisions = append(p.Decisions, Decision{Kind: kind, Message: fmt.Sprintf(format, args...)})
+}
+
+func (p *Placement) slot(start string, end string) {
+	if p == nil {
+		return
+	}
+
+	p.Slots = append(p.Slots, start+" - "+end)
+	p.decide(DecisionSlot, "%s - %s", start, end)
+}
+
+func (p *Placement) finish(minutes int, counter time.Time) {
+	if p == nil {
+		return
+	}
+
+	p.Minutes = minutes
+	p.CounterAfter = counter.Format(wallClockLayout)
+	p.decide(DecisionCounter, "next free time is %s", p.CounterAfter)
+}
@@ -0,0 +1,76 @@
+package cruncher
+
+import (
+	"bytes"
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func createTracedCrunch(t *testing.T) *Trace {
+	input := core.NewPipelineData()
+	pipelineA, _ := input.AddPipeline(pipelineAName)
+	pipelineA.PutWorkTime(date3, 4)
+	pipelineA.PutWorkTime(date4, 0)
+	pipelineB, _ := input.AddPipeline("Pipeline B")
+	pipelineB.PutWorkTime(date3, 4.505)
+	trace := NewTrace()
+
+	_, err := New().Crunch(input, Config{LunchBreakInMin: 60, Trace: trace})
+	require.NoError(t, err)
+
+	return trace
+}
+
+func TestTrace(t *testing.T) {
+	t.Run("should record the decisions per day in order", func(t *testing.T) {
+		// when
+		actual := createTracedCrunch(t)
+
+		// then
+		days := actual.Days()
+		require.Len(t, days, 2)
+		assert.Equal(t, date3, days[0].Date)
+		require.Len(t, days[0].Placements, 2)
+
+		first := days[0].Placements[0]
+		assert.Equal(t, pipelineAName, first.Pipeline)
+		assert.Equal(t, "08:00", first.CounterBefore)
+		assert.Equal(t, "12:00", first.CounterAfter)
+		assert.Equal(t, []string{"08:00 - 12:00"}, first.Slots)
+
+		second := days[0].Placements[1]
+		assert.Equal(t, 270, second.Minutes)
+		assert.Equal(t, "17:30", second.CounterAfter)
+		kinds := []string{}
+		for _, decision := range second.Decisions {
+			kinds = append(kinds, decision.Kind)
+		}
+		assert.Equal(t, []string{DecisionPlace, DecisionShift, DecisionRound, DecisionNoSplit, DecisionSlot, DecisionCounter}, kinds)
+
+		assert.Equal(t, DecisionEmpty, days[1].Placements[0].Decisions[0].Kind)
+	})
+	t.Run("should write text", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := createTracedCrunch(t).WriteText(buffer)
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, buffer.String(), "2021-05-03\n  Pipeline A: 4.00 h = 240 min, counter 08:00 -> 12:00\n    place: starts at 08:00\n")
+		assert.Contains(t, buffer.String(), "    shift: start lies within the lunch break, shifted to 13:00\n")
+	})
+	t.Run("should write JSON", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := createTracedCrunch(t).WriteJSON(buffer)
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, buffer.String(), `"date": "2021-05-03"`)
+		assert.Contains(t, buffer.String(), `"kind": "round"`)
+	})
+}
@@ -0,0 +1,315 @@
+package cruncher
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"math"
+	"sort"
+	"time"
+)
+
+// InterleavedName identifies the cruncher which alternates between the pipelines of a day.
+const InterleavedName = "interleaved"
+
+// Modes of the interleaved cruncher.
+const (
+	// InterleaveRoundRobin places the pipelines of a day in turns of Config.ChunkMinutes.
+	InterleaveRoundRobin = "round-robin"
+	// InterleaveHalfDays splits each pipeline by its weight into a morning and an afternoon block.
+	InterleaveHalfDays = "half-days"
+	// DefaultChunkMinutes is used if no chunk size is configured.
+	DefaultChunkMinutes = 60
+)
+
+// segment is a piece of work time of a pipeline which is placed without interruption unless it reaches lunch.
+type segment struct {
+	pipeline string
+	minutes  int
+}
+
+type interleavedCruncher struct {
+}
+
+// NewInterleaved returns the cruncher which alternates between the pipelines of a day so that the Sage entries look
+// like a plausible day. The work time per pipeline and day is the same as the sequential cruncher's.
+func NewInterleaved() Cruncher {
+	return &interleavedCruncher{}
+}
+
+// Crunch places the pipelines of each day in chunks from 08:00 on, splits chunks at lunch and merges adjacent slots of
+// the same pipeline.
+func (ic *interleavedCruncher) Crunch(pdata *core.PipelineData, config Config) (*core.CrunchedOutput, error) {
+	lunchStartTime, err := parseLunchStartTime(config.LunchStartTime)
+	if err != nil {
+		return nil, err
+	}
+
+	chunkMinutes := config.ChunkMinutes
+	if chunkMinutes == 0 {
+		chunkMinutes = DefaultChunkMinutes
+	}
+	if chunkMinutes < 0 {
+		return nil, errors.Errorf("invalid chunk size of %d min (expected a positive number of minutes)", chunkMinutes)
+	}
+
+	anchors, err := parseAnchors(pdata, config.Anchors)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := newSlotRules(pdata, config)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := config.InterleaveMode
+	if mode == "" {
+		mode = InterleaveRoundRobin
+	}
+	if mode != InterleaveRoundRobin && mode != InterleaveHalfDays {
+		return nil, errors.Errorf("unsupported interleave mode '%s' (supported modes: %s, %s)",
+			mode, InterleaveRoundRobin, InterleaveHalfDays)
+	}
+
+	output := core.NewCrunchedOutput()
+	pipelineNames := pdata.SortedKeys()
+	for _, pipelineName := range pipelineNames {
+		_, err := output.AddPipeline(pipelineName)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while crunching time data")
+		}
+	}
+
+	for _, day := range pdata.SortedDates() {
+		if _, err := time.Parse(core.DateLayout, day); err != nil {
+			return nil, errors.Wrap(core.NewDateError(day, err), "error while crunching time data")
+		}
+		pipelinesOfDay := orderPipelines(pipelineNames, config.PipelineOrder[day])
+		plan := planDay(workMinutes(pdata, day), pipelinesOfDay, rules)
+		anchored, err := anchorDay(day, anchors, plan.minutes, lunchStartTime, config.LunchBreakInMin)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while crunching time data")
+		}
+		dayStart, err := core.ParseDateWithTime(day, dayStartTime)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while crunching time data")
+		}
+
+		placed := []string{}
+		active := []string{}
+		minutes := map[string]int{}
+		remaining := map[string]int{}
+		placements := map[string]*Placement{}
+		for _, pipelineName := range pipelinesOfDay {
+			workPerDay := pdata.NamedDayRedmineValues[core.PipelineName(pipelineName)]
+			if _, ok := workPerDay.WorkPerDay[day]; !ok && plan.minutes[pipelineName] == 0 {
+				continue
+			}
+			pipeline := output.NamedDaySageValues[core.PipelineName(pipelineName)]
+
+			worktime := workPerDay.WorkTime(day)
+			placement := config.Trace.place(day, pipelineName, worktime, dayStart)
+			if target, ok := plan.mergedInto[pipelineName]; ok {
+				placement.decide(DecisionMerge, "%d min are shorter than the minimum slot of %d min, merged into %s",
+					int(worktime*60), rules.minSlotMinutes, target)
+				pipeline.PutEmptyTimeSlot(day)
+				placement.finish(0, dayStart)
+				continue
+			}
+			if containsNoWorkTime(worktime) && plan.minutes[pipelineName] == 0 {
+				placement.decide(DecisionEmpty, "no work time, adding an empty slot")
+				pipeline.PutEmptyTimeSlot(day)
+				placement.finish(0, dayStart)
+				continue
+			}
+
+			// same rounding as the sequential cruncher so that both strategies book the same totals
+			if redmineMinutes := int(worktime * 60); math.Abs(float64(redmineMinutes)-worktime*60) > roundingTolerance {
+				placement.decide(DecisionRound, "%.4f h = %.2f min, truncated to %d min", worktime, worktime*60, redmineMinutes)
+			}
+			if received := plan.received[pipelineName]; received > 0 {
+				placement.decide(DecisionMerge, "received %d min of pipelines below the minimum slot", received)
+			}
+			minutes[pipelineName] = plan.minutes[pipelineName]
+			for _, slot := range anchored.slots[pipelineName] {
+				placement.decide(DecisionAnchor, "anchored at %s", formatInterval(slot))
+				pipeline.PutTimeSlot(day, slot.start.Format(wallClockLayout), slot.end.Format(wallClockLayout))
+			}
+
+			placements[pipelineName] = placement
+			placed = append(placed, pipelineName)
+			remaining[pipelineName] = minutes[pipelineName] - anchored.minutes[pipelineName]
+			if remaining[pipelineName] == 0 {
+				continue
+			}
+			if mode == InterleaveRoundRobin {
+				placement.decide(DecisionPlace, "interleaved in chunks of %d min", chunkMinutes)
+			} else {
+				placement.decide(DecisionPlace, "interleaved by weight into morning and afternoon")
+			}
+			active = append(active, pipelineName)
+		}
+
+		var segments []segment
+		if mode == InterleaveRoundRobin {
+			segments = roundRobinSegments(active, remaining, chunkMinutes, rules.minSlotMinutes)
+		} else {
+			segments = halfDaySegments(active, remaining, freeMinutes(dayStart, anchored.lunch.start, anchored.blocked), rules.minSlotMinutes)
+		}
+
+		end := placeSegments(output, day, segments, dayStart, &anchored, rules, placements)
+		err = checkEndOfDay(day, end)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while crunching time data")
+		}
+
+		for _, pipelineName := range placed {
+			pipeline := output.NamedDaySageValues[core.PipelineName(pipelineName)]
+			sortTimeSlots(pipeline, day)
+			placement := placements[pipelineName]
+			for _, slot := range pipeline.TimeSlots(day) {
+				placement.slot(slot.Start, slot.End)
+			}
+			placement.finish(minutes[pipelineName], end)
+		}
+	}
+
+	return output, nil
+}
+
+// roundRobinSegments lets the pipelines take turns with chunks until all of their work time is used up. A rest shorter
+// than the minimum slot is added to the last chunk of its pipeline.
+func roundRobinSegments(pipelineNames []string, minutes map[string]int, chunkMinutes int, minSlotMinutes int) []segment {
+	remaining := map[string]int{}
+	for _, name := range pipelineNames {
+		remaining[name] = minutes[name]
+	}
+
+	result := []segment{}
+	for placed := true; placed; {
+		placed = false
+		for _, name := range pipelineNames {
+			if remaining[name] == 0 {
+				continue
+			}
+			chunk := chunkMinutes
+			if remaining[name]-chunk < minSlotMinutes {
+				chunk = remaining[name]
+			}
+			if remaining[name] < chunk {
+				chunk = remaining[name]
+			}
+			result = append(result, segment{pipeline: name, minutes: chunk})
+			remaining[name] -= chunk
+			placed = true
+		}
+	}
+
+	return result
+}
+
+// halfDaySegments gives each pipeline a share of the morning by its weight of the day's work time. The shares are
+// rounded by the largest remainder so that the morning is filled exactly. The rest of each pipeline follows in the
+// afternoon. Parts shorter than the minimum slot join the other half of their pipeline.
+func halfDaySegments(pipelineNames []string, minutes map[string]int, morningMinutes int, minSlotMinutes int) []segment {
+	total := 0
+	for _, name := range pipelineNames {
+		total += minutes[name]
+	}
+
+	morning := map[string]int{}
+	if total <= morningMinutes {
+		for _, name := range pipelineNames {
+			morning[name] = minutes[name]
+		}
+	} else if morningMinutes > 0 {
+		remainders := make([]int, 0, len(pipelineNames))
+		assigned := 0
+		for index, name := range pipelineNames {
+			morning[name] = minutes[name] * morningMinutes / total
+			assigned += morning[name]
+			remainders = append(remainders, index)
+		}
+		sort.SliceStable(remainders, func(i, j int) bool {
+			left := minutes[pipelineNames[remainders[i]]] * morningMinutes % total
+			right := minutes[pipelineNames[remainders[j]]] * morningMinutes % total
+			return left > right
+		})
+		for _, index := range remainders[:morningMinutes-assigned] {
+			morning[pipelineNames[index]]++
+		}
+	}
+
+	for _, name := range pipelineNames {
+		if morning[name] < minSlotMinutes {
+			morning[name] = 0
+		} else if minutes[name]-morning[name] < minSlotMinutes {
+			morning[name] = minutes[name]
+		}
+	}
+
+	result := []segment{}
+	for _, name := range pipelineNames {
+		if morning[name] > 0 {
+			result = append(result, segment{pipeline: name, minutes: morning[name]})
+		}
+	}
+	for _, name := range pipelineNames {
+		if afternoon := minutes[name] - morning[name]; afternoon > 0 {
+			result = append(result, segment{pipeline: name, minutes: afternoon})
+		}
+	}
+
+	return result
+}
+
+// placeSegments lays the segments out one after another around the blocked intervals and merges a slot into the
+// previous slot of its pipeline if they are adjacent. It returns the end of the last slot.
+func placeSegments(output *core.CrunchedOutput, day string, segments []segment, dayStart time.Time, anchored *anchoredDay,
+	rules slotRules, placements map[string]*Placement) time.Time {
+	current := dayStart
+
+	for _, seg := range segments {
+		pipeline := output.NamedDaySageValues[core.PipelineName(seg.pipeline)]
+		var slots []interval
+		slots, current = anchored.flow(current, seg.minutes, rules, placements[seg.pipeline])
+		for _, slot := range slots {
+			putMergedTimeSlot(pipeline, day, slot.start, slot.end)
+		}
+	}
+
+	return current
+}
+
+// freeMinutes returns the minutes between start and end which are not blocked.
+func freeMinutes(start time.Time, end time.Time, blocked []interval) int {
+	if !end.After(start) {
+		return 0
+	}
+
+	result := end.Sub(start)
+	for _, other := range blocked {
+		overlapStart, overlapEnd := other.start, other.end
+		if overlapStart.Before(start) {
+			overlapStart = start
+		}
+		if overlapEnd.After(end) {
+			overlapEnd = end
+		}
+		if overlapEnd.After(overlapStart) {
+			result -= overlapEnd.Sub(overlapStart)
+		}
+	}
+
+	return int(result.Minutes())
+}
+
+// putMergedTimeSlot extends the last slot of the day if it ends where the new slot starts. Otherwise it adds the slot.
+func putMergedTimeSlot(pipeline *core.SageWorkPerDay, day string, start time.Time, end time.Time) {
+	slots := (*pipeline)[day]
+	if last := len(slots) - 1; last >= 0 && slots[last].End == start.Format(wallClockLayout) {
+		slots[last].End = end.Format(wallClockLayout)
+		return
+	}
+
+	pipeline.PutTimeSlot(day, start.Format(wallClockLayout), end.Format(wallClockLayout))
+}
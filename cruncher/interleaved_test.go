@@ -0,0 +1,142 @@
+package cruncher
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+const pipelineBName = "Pipeline B"
+
+func Test_interleavedCruncher_Crunch(t *testing.T) {
+	t.Run("should let the pipelines take turns in chunks", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime(date3, 2.5)
+		pipelineB, _ := input.AddPipeline(pipelineBName)
+		pipelineB.PutWorkTime(date3, 3)
+
+		// when
+		actual, err := NewInterleaved().Crunch(input, Config{LunchBreakInMin: 60})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "09:00"}, {Start: "10:00", End: "11:00"}, {Start: "13:00", End: "13:30"}},
+			actual.NamedDaySageValues[pipelineAName].TimeSlots(date3))
+		assert.Equal(t, []core.TimeSlot{{Start: "09:00", End: "10:00"}, {Start: "11:00", End: "12:00"}, {Start: "13:30", End: "14:30"}},
+			actual.NamedDaySageValues[pipelineBName].TimeSlots(date3))
+	})
+	t.Run("should split the pipelines by weight into morning and afternoon", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime(date3, 6)
+		pipelineB, _ := input.AddPipeline(pipelineBName)
+		pipelineB.PutWorkTime(date3, 2)
+
+		// when
+		actual, err := NewInterleaved().Crunch(input, Config{LunchBreakInMin: 60, InterleaveMode: InterleaveHalfDays})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "11:00"}, {Start: "13:00", End: "16:00"}},
+			actual.NamedDaySageValues[pipelineAName].TimeSlots(date3))
+		assert.Equal(t, []core.TimeSlot{{Start: "11:00", End: "12:00"}, {Start: "16:00", End: "17:00"}},
+			actual.NamedDaySageValues[pipelineBName].TimeSlots(date3))
+	})
+	t.Run("should merge adjacent chunks of the same pipeline", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime(date3, 5)
+		pipelineA.PutWorkTime(date4, 0)
+
+		// when
+		actual, err := NewInterleaved().Crunch(input, Config{LunchBreakInMin: 60, ChunkMinutes: 30})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "12:00"}, {Start: "13:00", End: "14:00"}},
+			actual.NamedDaySageValues[pipelineAName].TimeSlots(date3))
+		assert.Equal(t, []core.TimeSlot{{Start: "-", End: "-"}}, actual.NamedDaySageValues[pipelineAName].TimeSlots(date4))
+	})
+	t.Run("should book the same totals as the sequential cruncher", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime(date3, 1.1)
+		pipelineA.PutWorkTime(date4, 4.35)
+		pipelineB, _ := input.AddPipeline(pipelineBName)
+		pipelineB.PutWorkTime(date3, 2.7)
+		pipelineB.PutWorkTime(date4, 0.45)
+		pipelineC, _ := input.AddPipeline("Pipeline C")
+		pipelineC.PutWorkTime(date3, 3.3)
+		config := Config{LunchBreakInMin: 45, LunchStartTime: "11:30"}
+		sequential, err := New().Crunch(input, config)
+		require.NoError(t, err)
+
+		for _, mode := range []string{InterleaveRoundRobin, InterleaveHalfDays} {
+			config.InterleaveMode = mode
+			config.ChunkMinutes = 25
+
+			// when
+			actual, err := NewInterleaved().Crunch(input, config)
+
+			// then
+			require.NoError(t, err)
+			for _, pipeline := range input.SortedKeys() {
+				for _, day := range []string{date3, date4} {
+					assert.Equal(t, bookedTime(t, sequential, pipeline, day), bookedTime(t, actual, pipeline, day),
+						"%s on %s in mode %s", pipeline, day, mode)
+				}
+			}
+		}
+	})
+	t.Run("should fail for unknown interleave modes", func(t *testing.T) {
+		// when
+		_, err := NewInterleaved().Crunch(core.NewPipelineData(), Config{InterleaveMode: "random"})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported interleave mode 'random'")
+	})
+	t.Run("should fail for dates which cannot be parsed", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime("Gesamtzeit", 8)
+
+		// when
+		_, err := NewInterleaved().Crunch(input, Config{})
+
+		// then
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, core.ErrInvalidDate))
+	})
+	t.Run("should fail for work time which lasts past midnight", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime(date3, 10)
+		pipelineB, _ := input.AddPipeline(pipelineBName)
+		pipelineB.PutWorkTime(date3, 6.5)
+
+		// when
+		_, err := NewInterleaved().Crunch(input, Config{LunchBreakInMin: 60})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "work time on 2021-05-03 does not fit into the day: its time slots would last until 01:30 on 2021-05-04")
+	})
+}
+
+func bookedTime(t *testing.T, output *core.CrunchedOutput, pipeline string, day string) time.Duration {
+	t.Helper()
+
+	var result time.Duration
+	for _, slot := range output.NamedDaySageValues[core.PipelineName(pipeline)].TimeSlots(day) {
+		duration, err := slot.Duration()
+		require.NoError(t, err)
+		result += duration
+	}
+
+	return result
+}
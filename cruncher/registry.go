@@ -0,0 +1,55 @@
+package cruncher
+
+import (
+	"github.com/pkg/errors"
+	"sort"
+	"strings"
+)
+
+// SequentialName identifies the cruncher which places the pipelines of a day one after another.
+const SequentialName = "sequential"
+
+var registry = map[string]func() Cruncher{
+	SequentialName:  New,
+	InterleavedName: NewInterleaved,
+}
+
+// Register adds a cruncher which is selected by its name regardless of case. Third-party crunchers register themselves
+// in an init function of their package.
+func Register(name string, create func() Cruncher) error {
+	if name == "" || create == nil {
+		return errors.New("cruncher registration needs a name and a constructor")
+	}
+	name = strings.ToLower(name)
+	if _, ok := registry[name]; ok {
+		return errors.Errorf("cruncher '%s' is already registered", name)
+	}
+
+	registry[name] = create
+	return nil
+}
+
+// Create returns the registered cruncher of the given name. An empty name selects the sequential cruncher.
+func Create(name string) (Cruncher, error) {
+	if name == "" {
+		name = SequentialName
+	}
+
+	create, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return nil, errors.Errorf("unsupported cruncher '%s' (supported crunchers: %s)", name, strings.Join(Names(), ", "))
+	}
+
+	return create(), nil
+}
+
+// Names returns the names of all registered crunchers in lexical order.
+func Names() []string {
+	result := make([]string, 0, len(registry))
+	for name := range registry {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result
+}
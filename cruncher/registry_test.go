@@ -0,0 +1,65 @@
+package cruncher
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type emptyCruncher struct{}
+
+func (ec *emptyCruncher) Crunch(pdata *core.PipelineData, config Config) (*core.CrunchedOutput, error) {
+	return core.NewCrunchedOutput(), nil
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("should select the sequential cruncher by default", func(t *testing.T) {
+		// when
+		actual, err := Create("")
+
+		// then
+		require.NoError(t, err)
+		assert.IsType(t, New(), actual)
+	})
+	t.Run("should select a registered cruncher", func(t *testing.T) {
+		defer delete(registry, "empty")
+		err := Register("empty", func() Cruncher { return &emptyCruncher{} })
+		require.NoError(t, err)
+
+		// when
+		actual, err := Create("empty")
+
+		// then
+		require.NoError(t, err)
+		assert.IsType(t, &emptyCruncher{}, actual)
+	})
+	t.Run("should select a registered cruncher regardless of case", func(t *testing.T) {
+		defer delete(registry, "empty")
+		err := Register("Empty", func() Cruncher { return &emptyCruncher{} })
+		require.NoError(t, err)
+
+		// when
+		actual, err := Create("EMPTY")
+
+		// then
+		require.NoError(t, err)
+		assert.IsType(t, &emptyCruncher{}, actual)
+		assert.Contains(t, Names(), "empty")
+	})
+	t.Run("should fail for unknown crunchers", func(t *testing.T) {
+		// when
+		_, err := Create("random")
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported cruncher 'random' (supported crunchers: interleaved, sequential)")
+	})
+	t.Run("should fail for names which are already registered", func(t *testing.T) {
+		// when
+		err := Register(SequentialName, New)
+
+		// then
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,150 @@
+package cruncher
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"sort"
+	"time"
+)
+
+// slotRules contain the options which avoid short and scattered time slots.
+type slotRules struct {
+	minSlotMinutes int
+	mergeInto      string
+	consolidate    bool
+}
+
+func newSlotRules(pdata *core.PipelineData, config Config) (slotRules, error) {
+	if config.MinSlotMinutes < 0 {
+		return slotRules{}, errors.Errorf("invalid minimum slot of %d min (expected a positive number of minutes)", config.MinSlotMinutes)
+	}
+	if config.MergeInto != "" {
+		if _, ok := pdata.NamedDayRedmineValues[core.PipelineName(config.MergeInto)]; !ok {
+			return slotRules{}, errors.Errorf("pipeline '%s' to merge short work times into does not exist", config.MergeInto)
+		}
+	}
+
+	return slotRules{
+		minSlotMinutes: config.MinSlotMinutes,
+		mergeInto:      config.MergeInto,
+		consolidate:    config.ConsolidateSlots,
+	}, nil
+}
+
+func (r slotRules) minSlot() time.Duration {
+	return time.Duration(r.minSlotMinutes) * time.Minute
+}
+
+// dayPlan contains the minutes which each pipeline books on a day.
+type dayPlan struct {
+	minutes map[string]int
+	// mergedInto maps a pipeline with a work time below the minimum slot to the pipeline which received it.
+	mergedInto map[string]string
+	// received contains the minutes which a pipeline received from merged pipelines.
+	received map[string]int
+}
+
+// planDay merges work times which are shorter than the minimum slot into the designated pipeline or else into the
+// pipeline which is placed before them, or after them for the first pipeline of a day. The total of the day does not
+// change.
+func planDay(minutes map[string]int, order []string, rules slotRules) dayPlan {
+	result := dayPlan{minutes: map[string]int{}, mergedInto: map[string]string{}, received: map[string]int{}}
+	for name, value := range minutes {
+		result.minutes[name] = value
+	}
+	if rules.minSlotMinutes == 0 {
+		return result
+	}
+
+	for index, name := range order {
+		value := result.minutes[name]
+		if value == 0 || value >= rules.minSlotMinutes || name == rules.mergeInto {
+			continue
+		}
+
+		target := rules.mergeInto
+		if target == "" {
+			target = adjacentPipeline(order, index, result.minutes)
+		}
+		if target == "" {
+			continue
+		}
+
+		result.minutes[target] += value
+		result.received[target] += value
+		result.minutes[name] = 0
+		result.received[name] = 0
+		result.mergedInto[name] = target
+	}
+
+	// a merged pipeline may have been merged again
+	for name, target := range result.mergedInto {
+		for result.mergedInto[target] != "" {
+			target = result.mergedInto[target]
+		}
+		result.mergedInto[name] = target
+	}
+
+	return result
+}
+
+// adjacentPipeline returns the closest pipeline with work time before the given position, or else after it.
+func adjacentPipeline(order []string, index int, minutes map[string]int) string {
+	for before := index - 1; before >= 0; before-- {
+		if minutes[order[before]] > 0 {
+			return order[before]
+		}
+	}
+	for after := index + 1; after < len(order); after++ {
+		if minutes[order[after]] > 0 {
+			return order[after]
+		}
+	}
+
+	return ""
+}
+
+// moveLunch moves the lunch break instead of splitting the slot from start to end if the slot rules call for it: a
+// consolidated slot or a part after lunch which is shorter than the minimum slot postpones the break until the slot
+// ends, a part before lunch which is shorter than the minimum slot brings the break forward to the start of the slot.
+// The break never moves onto an anchor.
+func (d *anchoredDay) moveLunch(start time.Time, end time.Time, rules slotRules, placement *Placement) bool {
+	lunchBreak := d.lunch.end.Sub(d.lunch.start)
+
+	var moved interval
+	var reason string
+	switch {
+	case rules.consolidate:
+		moved = interval{start: end, end: end.Add(lunchBreak)}
+		reason = "instead of splitting the slot"
+	case end.Sub(d.lunch.start) < rules.minSlot():
+		moved = interval{start: end, end: end.Add(lunchBreak)}
+		reason = "instead of splitting off a " + end.Sub(d.lunch.start).String() + " slot after lunch"
+	case d.lunch.start.Sub(start) < rules.minSlot():
+		moved = interval{start: start, end: start.Add(lunchBreak)}
+		reason = "instead of splitting off a " + d.lunch.start.Sub(start).String() + " slot before lunch"
+	default:
+		return false
+	}
+
+	spanStart, spanEnd := d.lunch.start, moved.end
+	if moved.start.Before(spanStart) {
+		spanStart, spanEnd = moved.start, d.lunch.end
+	}
+	for _, other := range d.blocked {
+		if other.anchor != "" && other.start.Before(spanEnd) && other.end.After(spanStart) {
+			return false
+		}
+	}
+
+	placement.decide(DecisionLunch, "lunch break moved to %s %s", formatInterval(moved), reason)
+	for index := range d.blocked {
+		if d.blocked[index].anchor == "" {
+			d.blocked[index] = moved
+		}
+	}
+	d.lunch = moved
+	sort.Slice(d.blocked, func(i, j int) bool { return d.blocked[i].start.Before(d.blocked[j].start) })
+
+	return true
+}
@@ -0,0 +1,151 @@
+package cruncher
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_planDay(t *testing.T) {
+	order := []string{"A", "B", "C", "D"}
+
+	t.Run("should merge short work times into the pipeline before, or after for the first pipeline", func(t *testing.T) {
+		// when
+		actual := planDay(map[string]int{"A": 5, "B": 120, "C": 10, "D": 60}, order, slotRules{minSlotMinutes: 15})
+
+		// then
+		assert.Equal(t, map[string]int{"A": 0, "B": 135, "C": 0, "D": 60}, actual.minutes)
+		assert.Equal(t, map[string]string{"A": "B", "C": "B"}, actual.mergedInto)
+		assert.Equal(t, 15, actual.received["B"])
+	})
+	t.Run("should follow pipelines which were merged again", func(t *testing.T) {
+		// when
+		actual := planDay(map[string]int{"A": 5, "B": 5, "C": 60}, order, slotRules{minSlotMinutes: 15})
+
+		// then
+		assert.Equal(t, map[string]int{"A": 0, "B": 0, "C": 70}, actual.minutes)
+		assert.Equal(t, map[string]string{"A": "C", "B": "C"}, actual.mergedInto)
+	})
+	t.Run("should merge into the designated pipeline", func(t *testing.T) {
+		// when
+		actual := planDay(map[string]int{"A": 5, "B": 120, "D": 10}, order, slotRules{minSlotMinutes: 15, mergeInto: "D"})
+
+		// then
+		assert.Equal(t, map[string]int{"A": 0, "B": 120, "D": 15}, actual.minutes)
+	})
+	t.Run("should keep short work times without other pipelines", func(t *testing.T) {
+		// when
+		actual := planDay(map[string]int{"A": 5}, order, slotRules{minSlotMinutes: 15})
+
+		// then
+		assert.Equal(t, map[string]int{"A": 5}, actual.minutes)
+	})
+}
+
+func Test_cruncher_CrunchWithSlotRules(t *testing.T) {
+	t.Run("should merge tiny work times and keep the total of the day", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime(date3, 0.08)
+		pipelineB, _ := input.AddPipeline(pipelineBName)
+		pipelineB.PutWorkTime(date3, 2)
+		trace := NewTrace()
+
+		// when
+		actual, err := New().Crunch(input, Config{LunchBreakInMin: 60, MinSlotMinutes: 15, Trace: trace})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "-", End: "-"}}, actual.NamedDaySageValues[pipelineAName].TimeSlots(date3))
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "10:04"}}, actual.NamedDaySageValues[pipelineBName].TimeSlots(date3))
+		assert.Equal(t, DecisionMerge, trace.Days()[0].Placements[0].Decisions[0].Kind)
+	})
+	t.Run("should bring lunch forward instead of splitting off a short slot before lunch", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime(date3, 3.9)
+		pipelineB, _ := input.AddPipeline(pipelineBName)
+		pipelineB.PutWorkTime(date3, 2)
+
+		// when
+		actual, err := New().Crunch(input, Config{LunchBreakInMin: 60, MinSlotMinutes: 15})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "11:54"}}, actual.NamedDaySageValues[pipelineAName].TimeSlots(date3))
+		assert.Equal(t, []core.TimeSlot{{Start: "12:54", End: "14:54"}}, actual.NamedDaySageValues[pipelineBName].TimeSlots(date3))
+	})
+	t.Run("should postpone lunch instead of splitting off a short slot after lunch", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime(date3, 4.2)
+		pipelineB, _ := input.AddPipeline(pipelineBName)
+		pipelineB.PutWorkTime(date3, 1)
+
+		// when
+		actual, err := New().Crunch(input, Config{LunchBreakInMin: 60, MinSlotMinutes: 15})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "12:12"}}, actual.NamedDaySageValues[pipelineAName].TimeSlots(date3))
+		assert.Equal(t, []core.TimeSlot{{Start: "13:12", End: "14:12"}}, actual.NamedDaySageValues[pipelineBName].TimeSlots(date3))
+	})
+	t.Run("should consolidate slots by postponing lunch", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime(date3, 4.5)
+		pipelineB, _ := input.AddPipeline(pipelineBName)
+		pipelineB.PutWorkTime(date3, 1)
+
+		// when
+		actual, err := New().Crunch(input, Config{LunchBreakInMin: 60, ConsolidateSlots: true})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "12:30"}}, actual.NamedDaySageValues[pipelineAName].TimeSlots(date3))
+		assert.Equal(t, []core.TimeSlot{{Start: "13:30", End: "14:30"}}, actual.NamedDaySageValues[pipelineBName].TimeSlots(date3))
+	})
+	t.Run("should not move lunch onto an anchor", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime(date3, 4.5)
+		pipelineB, _ := input.AddPipeline(pipelineBName)
+		pipelineB.PutWorkTime(date3, 0.25)
+		config := Config{LunchBreakInMin: 60, ConsolidateSlots: true, Anchors: []Anchor{{Pipeline: pipelineBName, Start: "13:00", End: "13:15"}}}
+
+		// when
+		actual, err := New().Crunch(input, config)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "12:00"}, {Start: "13:15", End: "13:45"}},
+			actual.NamedDaySageValues[pipelineAName].TimeSlots(date3))
+	})
+	t.Run("should add short rests to the last chunk of the interleaved cruncher", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime(date3, 1.1)
+		pipelineB, _ := input.AddPipeline(pipelineBName)
+		pipelineB.PutWorkTime(date3, 1)
+
+		// when
+		actual, err := NewInterleaved().Crunch(input, Config{LunchBreakInMin: 60, MinSlotMinutes: 15})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "09:06"}}, actual.NamedDaySageValues[pipelineAName].TimeSlots(date3))
+		assert.Equal(t, []core.TimeSlot{{Start: "09:06", End: "10:06"}}, actual.NamedDaySageValues[pipelineBName].TimeSlots(date3))
+	})
+	t.Run("should fail for unknown pipelines to merge into", func(t *testing.T) {
+		input := core.NewPipelineData()
+		_, _ = input.AddPipeline(pipelineAName)
+
+		// when
+		_, err := New().Crunch(input, Config{MinSlotMinutes: 15, MergeInto: "Internal"})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pipeline 'Internal' to merge short work times into does not exist")
+	})
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/history"
+	"github.com/urfave/cli/v2"
+	"io"
+	"os"
+)
+
+func diffCommand() *cli.Command {
+	return &cli.Command{
+		Name: "diff",
+		Usage: "crunch a week again and show the time slots which changed since it was stored in the --" +
+			flagHistoryLong + " directory",
+		Action:    doCliDiff,
+		ArgsUsage: runArgsUsage,
+		Flags:     runFlags(),
+	}
+}
+
+func doCliDiff(cliCtx *cli.Context) error {
+	week := cliCtx.String(flagWeekLong)
+	if week == "" {
+		return errors.Errorf("diff needs the week to compare, f. i. --%s 2021-W18", flagWeekLong)
+	}
+
+	args, err := buildRunArgs(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	return doDiff(os.Stdout, args, week)
+}
+
+// doDiff crunches the week like the run command and writes the changes against the latest stored version.
+func doDiff(w io.Writer, args runArgs, week string) error {
+	if args.historyDir == "" {
+		return errors.Errorf("no history configured, use --%s or the history section of the config file", flagHistoryLong)
+	}
+
+	latest, found, err := history.NewStore(args.historyDir).Latest(week)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.Errorf("week %s was not stored in history %s yet", week, args.historyDir)
+	}
+
+	_, result, err := processRedmineData(args)
+	if err != nil {
+		return err
+	}
+	data, crunched := result.Data, result.Crunched
+
+	input := "unchanged"
+	if history.InputHash(data, args.dateRange) != latest.InputHash {
+		input = "changed"
+	}
+	_, err = fmt.Fprintf(w, "%s compared with the version of %s (Redmine input %s)\n", week,
+		latest.CreatedAt.Local().Format("2006-01-02 15:04:05"), input)
+	if err != nil {
+		return err
+	}
+
+	return history.WriteChanges(w, history.Diff(latest.Crunched, history.FilterDates(crunched, args.dateRange)))
+}
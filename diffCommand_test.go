@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"github.com/ppxl/sagemine/core"
+	"github.com/ppxl/sagemine/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_doDiff(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "history-")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "export.csv")
+	_ = ioutil.WriteFile(path, []byte(`Anforderungspipeline;2021-05-03;2021-05-04
+Pipeline A;7,50;6,00
+`), 0600)
+	week, _ := core.ParseISOWeek("2021-W18")
+	args := runArgs{
+		lunchBreakInMin:  60,
+		filenames:        []string{path},
+		csvDelimiter:     ";",
+		decimalDelimiter: ",",
+		dateRange:        week,
+		historyDir:       filepath.Join(dir, "history"),
+	}
+
+	t.Run("should fail for weeks which were not stored", func(t *testing.T) {
+		// when
+		err := doDiff(&bytes.Buffer{}, args, "2021-W18")
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "week 2021-W18 was not stored")
+	})
+
+	data, _, err := prepareRedmineData(args)
+	require.NoError(t, err)
+	crunched, err := crunch(data, args)
+	require.NoError(t, err)
+	_, err = history.NewStore(args.historyDir).Record(data, crunched, time.Now())
+	require.NoError(t, err)
+
+	t.Run("should find no changes", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := doDiff(buffer, args, "2021-W18")
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, buffer.String(), "(Redmine input unchanged)\nno changes\n")
+	})
+	t.Run("should show corrected Redmine entries", func(t *testing.T) {
+		_ = ioutil.WriteFile(path, []byte(`Anforderungspipeline;2021-05-03;2021-05-04
+Pipeline A;7,50;5,00
+`), 0600)
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := doDiff(buffer, args, "2021-W18")
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, buffer.String(), "(Redmine input changed)\n")
+		assert.Contains(t, buffer.String(), "~ Pipeline A-joined  2021-05-04  08:00-12:00, 13:00-15:00 -> 08:00-12:00, 13:00-14:00\n")
+	})
+}
@@ -0,0 +1,141 @@
+package history
+
+import (
+	"fmt"
+	"github.com/ppxl/sagemine/core"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Kinds of changes between two versions of a week.
+const (
+	ChangeAdded   = "+"
+	ChangeRemoved = "-"
+	ChangeChanged = "~"
+)
+
+// Change describes the differing time slots of a pipeline on a date. Empty time slots are left out.
+type Change struct {
+	Kind     string
+	Pipeline string
+	Date     string
+	Before   []core.TimeSlot
+	After    []core.TimeSlot
+}
+
+// Diff returns the changes from the before to the after version ordered by pipeline and date.
+func Diff(before, after *core.CrunchedOutput) []Change {
+	beforeSlots := slotsByPipelineAndDate(before)
+	afterSlots := slotsByPipelineAndDate(after)
+
+	keys := []slotKey{}
+	for key := range beforeSlots {
+		keys = append(keys, key)
+	}
+	for key := range afterSlots {
+		if _, ok := beforeSlots[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].pipeline != keys[j].pipeline {
+			return keys[i].pipeline < keys[j].pipeline
+		}
+		return keys[i].date < keys[j].date
+	})
+
+	result := []Change{}
+	for _, key := range keys {
+		beforeDay, inBefore := beforeSlots[key]
+		afterDay, inAfter := afterSlots[key]
+		change := Change{Pipeline: key.pipeline, Date: key.date}
+
+		switch {
+		case !inBefore:
+			change.Kind = ChangeAdded
+		case !inAfter:
+			change.Kind = ChangeRemoved
+		case formatSlots(beforeDay) != formatSlots(afterDay):
+			change.Kind = ChangeChanged
+		default:
+			continue
+		}
+		change.Before = beforeDay
+		change.After = afterDay
+		result = append(result, change)
+	}
+
+	return result
+}
+
+// WriteChanges writes one line per change or "no changes".
+//
+// Example:
+//  ~ Pipeline A  2021-05-04  08:00-12:00, 13:00-15:00 -> 08:00-12:00, 13:00-14:00
+//  + Pipeline C  2021-05-05  10:00-11:00
+func WriteChanges(w io.Writer, changes []Change) error {
+	if len(changes) == 0 {
+		_, err := fmt.Fprintln(w, "no changes")
+		return err
+	}
+
+	for _, change := range changes {
+		var slots string
+		switch change.Kind {
+		case ChangeAdded:
+			slots = formatSlots(change.After)
+		case ChangeRemoved:
+			slots = formatSlots(change.Before)
+		default:
+			slots = formatSlots(change.Before) + " -> " + formatSlots(change.After)
+		}
+
+		_, err := fmt.Fprintf(w, "%s %s  %s  %s\n", change.Kind, change.Pipeline, change.Date, slots)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type slotKey struct {
+	pipeline string
+	date     string
+}
+
+// slotsByPipelineAndDate maps pipeline and date to the non-empty time slots ordered by start.
+func slotsByPipelineAndDate(crunched *core.CrunchedOutput) map[slotKey][]core.TimeSlot {
+	result := map[slotKey][]core.TimeSlot{}
+	if crunched == nil {
+		return result
+	}
+
+	for name, workPerDay := range crunched.NamedDaySageValues {
+		for date, slots := range *workPerDay {
+			nonEmpty := []core.TimeSlot{}
+			for _, slot := range slots {
+				if !slot.IsEmpty() {
+					nonEmpty = append(nonEmpty, slot)
+				}
+			}
+			if len(nonEmpty) == 0 {
+				continue
+			}
+			sort.SliceStable(nonEmpty, func(i, j int) bool { return nonEmpty[i].Start < nonEmpty[j].Start })
+			result[slotKey{pipeline: string(name), date: date}] = nonEmpty
+		}
+	}
+
+	return result
+}
+
+func formatSlots(slots []core.TimeSlot) string {
+	formatted := make([]string, 0, len(slots))
+	for _, slot := range slots {
+		formatted = append(formatted, slot.Start+"-"+slot.End)
+	}
+
+	return strings.Join(formatted, ", ")
+}
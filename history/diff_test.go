@@ -0,0 +1,77 @@
+package history
+
+import (
+	"bytes"
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	_, before := createHistoryInput(5)
+	_, after := createHistoryInput(4)
+	pipelineC, _ := after.AddPipeline("Pipeline C")
+	pipelineC.PutTimeSlot("2021-05-10", "10:00", "11:00")
+	pipelineC.PutEmptyTimeSlot("2021-05-07")
+
+	t.Run("should find changed and added slots", func(t *testing.T) {
+		// when
+		actual := Diff(before, after)
+
+		// then
+		require.Len(t, actual, 2)
+		assert.Equal(t, ChangeChanged, actual[0].Kind)
+		assert.Equal(t, "Pipeline A", actual[0].Pipeline)
+		assert.Equal(t, "2021-05-07", actual[0].Date)
+		assert.Equal(t, ChangeAdded, actual[1].Kind)
+		assert.Equal(t, "Pipeline C", actual[1].Pipeline)
+	})
+	t.Run("should find removed slots", func(t *testing.T) {
+		// when
+		actual := Diff(after, before)
+
+		// then
+		require.Len(t, actual, 2)
+		assert.Equal(t, ChangeRemoved, actual[1].Kind)
+	})
+	t.Run("should find no changes between equal versions", func(t *testing.T) {
+		// when
+		actual := Diff(before, before)
+
+		// then
+		assert.Empty(t, actual)
+	})
+}
+
+func TestWriteChanges(t *testing.T) {
+	t.Run("should write one line per change", func(t *testing.T) {
+		changes := []Change{
+			{Kind: ChangeChanged, Pipeline: "Pipeline A", Date: "2021-05-07",
+				Before: []core.TimeSlot{{Start: "08:00", End: "12:00"}, {Start: "13:00", End: "14:00"}},
+				After:  []core.TimeSlot{{Start: "08:00", End: "12:00"}}},
+			{Kind: ChangeRemoved, Pipeline: "Pipeline C", Date: "2021-05-10", Before: []core.TimeSlot{{Start: "10:00", End: "11:00"}}},
+		}
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := WriteChanges(buffer, changes)
+
+		// then
+		require.NoError(t, err)
+		expected := `~ Pipeline A  2021-05-07  08:00-12:00, 13:00-14:00 -> 08:00-12:00
+- Pipeline C  2021-05-10  10:00-11:00
+`
+		assert.Equal(t, expected, buffer.String())
+	})
+	t.Run("should write that nothing changed", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := WriteChanges(buffer, []Change{})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "no changes\n", buffer.String())
+	})
+}
@@ -0,0 +1,198 @@
+package history
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	snapshotExtension = ".json"
+	// snapshotLayout names the snapshot files so that their lexical order is their chronological order.
+	snapshotLayout = "20060102T150405.000000000Z"
+	filePermission = 0600
+	dirPermission  = 0700
+)
+
+// Snapshot is a stored version of the crunched time slots of a single ISO 8601 week.
+type Snapshot struct {
+	Week      string    `json:"week"`
+	CreatedAt time.Time `json:"createdAt"`
+	// InputHash identifies the Redmine work time the time slots were crunched from.
+	InputHash string               `json:"inputHash"`
+	Crunched  *core.CrunchedOutput `json:"crunched"`
+}
+
+// Store keeps the snapshots in a directory per week.
+//
+// Example:
+//  history/2021-W18/20210510T170304.000000000Z.json
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store in the given directory. The directory is created with the first snapshot.
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Record splits the crunched output into weeks and stores a snapshot of each week which differs from its latest
+// snapshot. It returns the names of the stored weeks.
+func (s *Store) Record(pdata *core.PipelineData, crunched *core.CrunchedOutput, now time.Time) ([]string, error) {
+	result := []string{}
+
+	for _, week := range Weeks(crunched) {
+		weekRange, err := core.ParseISOWeek(week)
+		if err != nil {
+			return nil, err
+		}
+
+		stored, err := s.Save(Snapshot{
+			Week:      week,
+			CreatedAt: now.UTC(),
+			InputHash: InputHash(pdata, weekRange),
+			Crunched:  FilterDates(crunched, weekRange),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if stored {
+			result = append(result, week)
+		}
+	}
+
+	return result, nil
+}
+
+// Save stores the snapshot unless it equals the latest snapshot of its week and returns true if it was stored.
+func (s *Store) Save(snapshot Snapshot) (bool, error) {
+	latest, found, err := s.Latest(snapshot.Week)
+	if err != nil {
+		return false, err
+	}
+	if found && latest.InputHash == snapshot.InputHash && len(Diff(latest.Crunched, snapshot.Crunched)) == 0 {
+		return false, nil
+	}
+
+	content, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return false, errors.Wrapf(err, "could not format snapshot of week %s", snapshot.Week)
+	}
+
+	weekDir := filepath.Join(s.dir, snapshot.Week)
+	err = os.MkdirAll(weekDir, dirPermission)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not create history directory %s", weekDir)
+	}
+
+	path := filepath.Join(weekDir, snapshot.CreatedAt.UTC().Format(snapshotLayout)+snapshotExtension)
+	err = ioutil.WriteFile(path, append(content, '\n'), filePermission)
+	if err != nil {
+		return false, errors.Wrapf(err, "could not write snapshot %s", path)
+	}
+
+	return true, nil
+}
+
+// Latest returns the most recent snapshot of the week. The boolean is false if the week was never stored.
+func (s *Store) Latest(week string) (Snapshot, bool, error) {
+	weekDir := filepath.Join(s.dir, week)
+	files, err := ioutil.ReadDir(weekDir)
+	if os.IsNotExist(err) {
+		return Snapshot{}, false, nil
+	}
+	if err != nil {
+		return Snapshot{}, false, errors.Wrapf(err, "could not read history directory %s", weekDir)
+	}
+
+	names := []string{}
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), snapshotExtension) {
+			names = append(names, file.Name())
+		}
+	}
+	if len(names) == 0 {
+		return Snapshot{}, false, nil
+	}
+	sort.Strings(names)
+
+	path := filepath.Join(weekDir, names[len(names)-1])
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Snapshot{}, false, errors.Wrapf(err, "could not read snapshot %s", path)
+	}
+
+	result := Snapshot{}
+	err = json.Unmarshal(content, &result)
+	if err != nil {
+		return Snapshot{}, false, errors.Wrapf(err, "could not parse snapshot %s", path)
+	}
+
+	return result, true, nil
+}
+
+// Weeks returns the ISO 8601 weeks of all dates of the crunched output in ascending order.
+func Weeks(crunched *core.CrunchedOutput) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, workPerDay := range crunched.NamedDaySageValues {
+		for date := range *workPerDay {
+			parsed, err := time.Parse(core.DateLayout, date)
+			if err != nil {
+				continue
+			}
+			name := core.FormatISOWeek(parsed)
+			if !seen[name] {
+				seen[name] = true
+				result = append(result, name)
+			}
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// FilterDates returns the pipelines with the time slots within the date range. Pipelines without time slots in the
+// range are left out.
+func FilterDates(crunched *core.CrunchedOutput, dateRange core.DateRange) *core.CrunchedOutput {
+	result := core.NewCrunchedOutput()
+	for name, workPerDay := range crunched.NamedDaySageValues {
+		filtered := core.SageWorkPerDay{}
+		for date, slots := range *workPerDay {
+			if dateRange.Contains(date) {
+				filtered[date] = append([]core.TimeSlot{}, slots...)
+			}
+		}
+		if len(filtered) > 0 {
+			result.NamedDaySageValues[name] = &filtered
+		}
+	}
+
+	return result
+}
+
+// InputHash returns a SHA-256 hash of the Redmine work time within the date range. It changes whenever an entry of the
+// range is corrected, added or removed.
+func InputHash(pdata *core.PipelineData, dateRange core.DateRange) string {
+	hash := sha256.New()
+	for _, name := range pdata.SortedKeys() {
+		workPerDay := pdata.NamedDayRedmineValues[core.PipelineName(name)]
+		for _, date := range workPerDay.SortedKeys() {
+			if dateRange.Contains(date) {
+				_, _ = fmt.Fprintf(hash, "%s\t%s\t%g\n", name, date, workPerDay.WorkTime(date))
+			}
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,110 @@
+package history
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func createHistoryInput(pipelineAHours float64) (*core.PipelineData, *core.CrunchedOutput) {
+	pdata := core.NewPipelineData()
+	pipelineA, _ := pdata.AddPipeline("Pipeline A")
+	pipelineA.PutWorkTime("2021-05-07", pipelineAHours)
+	pipelineA.PutWorkTime("2021-05-10", 2)
+
+	crunched := core.NewCrunchedOutput()
+	crunchedA, _ := crunched.AddPipeline("Pipeline A")
+	crunchedA.PutTimeSlot("2021-05-07", "08:00", "12:00")
+	if pipelineAHours > 4 {
+		crunchedA.PutTimeSlot("2021-05-07", "13:00", "14:00")
+	}
+	crunchedA.PutTimeSlot("2021-05-10", "08:00", "10:00")
+
+	return pdata, crunched
+}
+
+func TestStore_Record(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "history-")
+	defer os.RemoveAll(dir)
+	sut := NewStore(dir)
+	now := time.Date(2021, time.May, 11, 17, 3, 4, 0, time.UTC)
+
+	t.Run("should store a snapshot per week", func(t *testing.T) {
+		pdata, crunched := createHistoryInput(5)
+
+		// when
+		actual, err := sut.Record(pdata, crunched, now)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2021-W18", "2021-W19"}, actual)
+		_, err = os.Stat(filepath.Join(dir, "2021-W18", "20210511T170304.000000000Z.json"))
+		require.NoError(t, err)
+
+		latest, found, err := sut.Latest("2021-W18")
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, now, latest.CreatedAt)
+		assert.Len(t, latest.Crunched.NamedDaySageValues["Pipeline A"].TimeSlots("2021-05-07"), 2)
+		assert.Empty(t, latest.Crunched.NamedDaySageValues["Pipeline A"].TimeSlots("2021-05-10"))
+	})
+	t.Run("should skip unchanged weeks", func(t *testing.T) {
+		pdata, crunched := createHistoryInput(5)
+
+		// when
+		actual, err := sut.Record(pdata, crunched, now.Add(time.Hour))
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+	t.Run("should store corrected weeks only", func(t *testing.T) {
+		pdata, crunched := createHistoryInput(4)
+
+		// when
+		actual, err := sut.Record(pdata, crunched, now.Add(2*time.Hour))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2021-W18"}, actual)
+		latest, _, _ := sut.Latest("2021-W18")
+		assert.Equal(t, now.Add(2*time.Hour), latest.CreatedAt)
+	})
+}
+
+func TestStore_Latest(t *testing.T) {
+	t.Run("should report weeks which were never stored", func(t *testing.T) {
+		sut := NewStore(filepath.Join(os.TempDir(), "missing-history"))
+
+		// when
+		_, found, err := sut.Latest("2021-W18")
+
+		// then
+		require.NoError(t, err)
+		assert.False(t, found)
+	})
+}
+
+func TestInputHash(t *testing.T) {
+	week, _ := core.ParseISOWeek("2021-W18")
+
+	t.Run("should only change with the work time of the range", func(t *testing.T) {
+		pdata, _ := createHistoryInput(5)
+		expected := InputHash(pdata, week)
+
+		// when
+		pdata.NamedDayRedmineValues["Pipeline A"].PutWorkTime("2021-05-11", 3)
+		unchanged := InputHash(pdata, week)
+		pdata.NamedDayRedmineValues["Pipeline A"].PutWorkTime("2021-05-07", 6)
+		changed := InputHash(pdata, week)
+
+		// then
+		assert.Equal(t, expected, unchanged)
+		assert.NotEqual(t, expected, changed)
+	})
+}
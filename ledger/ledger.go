@@ -0,0 +1,276 @@
+package ledger
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// FormatVersion is written to every ledger file to recognize incompatible files of future versions.
+	FormatVersion  = 1
+	filePermission = 0600
+	dirPermission  = 0700
+	// maxTrailingWeeks limits the missing weeks after the last recorded week.
+	maxTrailingWeeks = 4
+)
+
+// Ledger keeps the target and booked hours of all processed days grouped by ISO week. Recording a day again replaces
+// it so that processing the same week twice does not change the balance.
+type Ledger struct {
+	Version int    `json:"version"`
+	Weeks   []Week `json:"weeks"`
+}
+
+// Week contains the processed days of an ISO 8601 week, f. i. 2021-W18.
+type Week struct {
+	Week     string  `json:"week"`
+	Target   float64 `json:"target"`
+	Booked   float64 `json:"booked"`
+	Overtime float64 `json:"overtime"`
+	Days     []Day   `json:"days"`
+}
+
+// Day contains the hours of a single date in the format YYYY-MM-DD.
+type Day struct {
+	Date   string  `json:"date"`
+	Target float64 `json:"target"`
+	Booked float64 `json:"booked"`
+}
+
+// New creates an empty ledger.
+func New() *Ledger {
+	return &Ledger{Version: FormatVersion, Weeks: []Week{}}
+}
+
+// Load reads a ledger file. A missing file results in an empty ledger.
+func Load(path string) (*Ledger, error) {
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read ledger %s", path)
+	}
+
+	result := New()
+	err = json.Unmarshal(content, result)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse ledger %s", path)
+	}
+	if result.Version != FormatVersion {
+		return nil, errors.Errorf("unsupported version %d of ledger %s (supported version: %d)", result.Version, path, FormatVersion)
+	}
+
+	return result, nil
+}
+
+// Save writes the ledger to a temporary file first and replaces the given file afterwards so that an interrupted run
+// does not leave a broken ledger behind.
+func (l *Ledger) Save(path string) error {
+	content, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "could not format ledger")
+	}
+
+	dir := filepath.Dir(path)
+	err = os.MkdirAll(dir, dirPermission)
+	if err != nil {
+		return errors.Wrapf(err, "could not create directory of ledger %s", path)
+	}
+
+	tempFile, err := ioutil.TempFile(dir, filepath.Base(path)+".*")
+	if err != nil {
+		return errors.Wrapf(err, "could not write ledger %s", path)
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write(append(content, '\n'))
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tempFile.Name(), filePermission)
+	}
+	if err == nil {
+		err = os.Rename(tempFile.Name(), path)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "could not write ledger %s", path)
+	}
+
+	return nil
+}
+
+// Record adds the days to their weeks, replacing days which were recorded before, and returns the names of the
+// affected weeks in ascending order.
+func (l *Ledger) Record(days []Day) ([]string, error) {
+	weeks := map[string]*Week{}
+	for index := range l.Weeks {
+		weeks[l.Weeks[index].Week] = &l.Weeks[index]
+	}
+
+	affected := map[string]*Week{}
+	for _, day := range days {
+		name, err := weekOf(day.Date)
+		if err != nil {
+			return nil, err
+		}
+
+		week, ok := affected[name]
+		if !ok {
+			week = &Week{Week: name, Days: []Day{}}
+			if recorded, ok := weeks[name]; ok {
+				week.Days = append(week.Days, recorded.Days...)
+			}
+			affected[name] = week
+		}
+		week.put(day)
+	}
+
+	result := []string{}
+	for name, week := range affected {
+		week.sum()
+		weeks[name] = week
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	l.Weeks = make([]Week, 0, len(weeks))
+	for _, week := range weeks {
+		l.Weeks = append(l.Weeks, *week)
+	}
+	sort.Slice(l.Weeks, func(i, j int) bool { return l.Weeks[i].Week < l.Weeks[j].Week })
+
+	return result, nil
+}
+
+// Week returns the recorded week of the given name.
+func (l *Ledger) Week(name string) (Week, bool) {
+	for _, week := range l.Weeks {
+		if week.Week == name {
+			return week, true
+		}
+	}
+
+	return Week{}, false
+}
+
+// Balance returns the sum of the overtime of all recorded weeks. Negative values mean missing hours.
+func (l *Ledger) Balance() float64 {
+	result := 0.0
+	for _, week := range l.Weeks {
+		result += week.Overtime
+	}
+
+	return result
+}
+
+// MissingWeeks returns the weeks between the first and the last recorded week which were not processed yet, followed
+// by at most maxTrailingWeeks finished weeks after the last recorded week so that an old ledger does not list every
+// week since then. The week of now is not finished and thus never missing.
+func (l *Ledger) MissingWeeks(now time.Time) ([]string, error) {
+	result := []string{}
+	if len(l.Weeks) == 0 {
+		return result, nil
+	}
+
+	first, err := core.ParseISOWeek(l.Weeks[0].Week)
+	if err != nil {
+		return nil, err
+	}
+	last, err := core.ParseISOWeek(l.Weeks[len(l.Weeks)-1].Week)
+	if err != nil {
+		return nil, err
+	}
+
+	current := core.FormatISOWeek(now)
+	trailingWeeks := 0
+	for monday := first.From; core.FormatISOWeek(monday) < current; monday = monday.AddDate(0, 0, 7) {
+		if monday.After(last.From) {
+			if trailingWeeks == maxTrailingWeeks {
+				break
+			}
+			trailingWeeks++
+		}
+
+		name := core.FormatISOWeek(monday)
+		if _, ok := l.Week(name); !ok {
+			result = append(result, name)
+		}
+	}
+
+	return result, nil
+}
+
+// WriteSummary writes the hours of the given weeks, the overtime balance and the weeks which were not processed yet.
+//
+// Example:
+//  2021-W18: booked 42.00 h of 40.00 h, overtime +2.00 h
+//  overtime balance: +1.50 h over 3 weeks
+//  weeks not processed yet: 2021-W16
+func (l *Ledger) WriteSummary(w io.Writer, weekNames []string, now time.Time) error {
+	for _, name := range weekNames {
+		week, ok := l.Week(name)
+		if !ok {
+			continue
+		}
+		_, err := fmt.Fprintf(w, "%s: booked %.2f h of %.2f h, overtime %+.2f h\n", week.Week, week.Booked, week.Target, week.Overtime)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "overtime balance: %+.2f h over %d weeks\n", l.Balance(), len(l.Weeks))
+	if err != nil {
+		return err
+	}
+
+	missing, err := l.MissingWeeks(now)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		_, err = fmt.Fprintf(w, "weeks not processed yet: %s\n", strings.Join(missing, ", "))
+	}
+
+	return err
+}
+
+func (w *Week) put(day Day) {
+	for index := range w.Days {
+		if w.Days[index].Date == day.Date {
+			w.Days[index] = day
+			return
+		}
+	}
+	w.Days = append(w.Days, day)
+	sort.Slice(w.Days, func(i, j int) bool { return w.Days[i].Date < w.Days[j].Date })
+}
+
+func (w *Week) sum() {
+	w.Target = 0
+	w.Booked = 0
+	for _, day := range w.Days {
+		w.Target += day.Target
+		w.Booked += day.Booked
+	}
+	w.Overtime = w.Booked - w.Target
+}
+
+func weekOf(date string) (string, error) {
+	parsed, err := time.Parse(core.DateLayout, date)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid date '%s'", date)
+	}
+
+	return core.FormatISOWeek(parsed), nil
+}
@@ -0,0 +1,165 @@
+package ledger
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func createWeek18() []Day {
+	return []Day{
+		{Date: "2021-05-03", Target: 8, Booked: 9},
+		{Date: "2021-05-04", Target: 8, Booked: 8.5},
+		{Date: "2021-05-08", Target: 0, Booked: 1},
+	}
+}
+
+func TestLedger_Record(t *testing.T) {
+	t.Run("should sum up the days per week", func(t *testing.T) {
+		sut := New()
+
+		// when
+		actual, err := sut.Record(append(createWeek18(), Day{Date: "2021-05-10", Target: 8, Booked: 6}))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2021-W18", "2021-W19"}, actual)
+		week, ok := sut.Week("2021-W18")
+		require.True(t, ok)
+		assert.Equal(t, 16.0, week.Target)
+		assert.Equal(t, 18.5, week.Booked)
+		assert.Equal(t, 2.5, week.Overtime)
+		assert.Equal(t, 0.5, sut.Balance())
+	})
+	t.Run("should be idempotent", func(t *testing.T) {
+		sut := New()
+		_, err := sut.Record(createWeek18())
+		require.NoError(t, err)
+
+		// when
+		_, err = sut.Record(createWeek18())
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, sut.Weeks, 1)
+		assert.Len(t, sut.Weeks[0].Days, 3)
+		assert.Equal(t, 2.5, sut.Balance())
+	})
+	t.Run("should replace recorded days and keep the others", func(t *testing.T) {
+		sut := New()
+		_, err := sut.Record(createWeek18())
+		require.NoError(t, err)
+
+		// when
+		_, err = sut.Record([]Day{{Date: "2021-05-04", Target: 8, Booked: 8}, {Date: "2021-05-05", Target: 8, Booked: 7}})
+
+		// then
+		require.NoError(t, err)
+		week, _ := sut.Week("2021-W18")
+		assert.Len(t, week.Days, 4)
+		assert.Equal(t, "2021-05-05", week.Days[2].Date)
+		assert.Equal(t, 1.0, week.Overtime)
+	})
+	t.Run("should fail for invalid dates", func(t *testing.T) {
+		// when
+		_, err := New().Record([]Day{{Date: "Gesamtzeit"}})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid date 'Gesamtzeit'")
+	})
+}
+
+func TestLedger_MissingWeeks(t *testing.T) {
+	sut := New()
+	_, _ = sut.Record(createWeek18())
+	_, _ = sut.Record([]Day{{Date: "2021-05-20", Target: 8, Booked: 8}})
+
+	t.Run("should return the gaps up to the last finished week", func(t *testing.T) {
+		// when
+		actual, err := sut.MissingWeeks(time.Date(2021, time.June, 2, 0, 0, 0, 0, time.UTC))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2021-W19", "2021-W21"}, actual)
+	})
+	t.Run("should return at most four finished weeks after the last recorded week", func(t *testing.T) {
+		// when
+		actual, err := sut.MissingWeeks(time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC))
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2021-W19", "2021-W21", "2021-W22", "2021-W23", "2021-W24"}, actual)
+	})
+	t.Run("should return no weeks for an empty ledger", func(t *testing.T) {
+		// when
+		actual, err := New().MissingWeeks(time.Now())
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, actual)
+	})
+}
+
+func TestLedger_WriteSummary(t *testing.T) {
+	sut := New()
+	weeks, _ := sut.Record(createWeek18())
+	_, _ = sut.Record([]Day{{Date: "2021-05-17", Target: 8, Booked: 7}})
+	buffer := &bytes.Buffer{}
+
+	// when
+	err := sut.WriteSummary(buffer, weeks, time.Date(2021, time.May, 19, 0, 0, 0, 0, time.UTC))
+
+	// then
+	require.NoError(t, err)
+	expected := `2021-W18: booked 18.50 h of 16.00 h, overtime +2.50 h
+overtime balance: +1.50 h over 2 weeks
+weeks not processed yet: 2021-W19
+`
+	assert.Equal(t, expected, buffer.String())
+}
+
+func TestLoad(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "ledger-")
+	defer os.RemoveAll(dir)
+
+	t.Run("should return an empty ledger for a missing file", func(t *testing.T) {
+		// when
+		actual, err := Load(filepath.Join(dir, "missing.json"))
+
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, actual.Weeks)
+	})
+	t.Run("should read a saved ledger", func(t *testing.T) {
+		path := filepath.Join(dir, "redsage", "ledger.json")
+		sut := New()
+		_, _ = sut.Record(createWeek18())
+		require.NoError(t, sut.Save(path))
+
+		// when
+		actual, err := Load(path)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, sut, actual)
+		info, _ := os.Stat(path)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+	t.Run("should fail for unknown versions", func(t *testing.T) {
+		path := filepath.Join(dir, "future.json")
+		_ = ioutil.WriteFile(path, []byte(`{"version": 2, "weeks": []}`), 0600)
+
+		// when
+		_, err := Load(path)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported version 2")
+	})
+}
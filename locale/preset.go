@@ -0,0 +1,171 @@
+package locale
+
+import (
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+// Preset bundles the regional settings of Redmine exports and RedSage output.
+type Preset struct {
+	Name string `yaml:"name"`
+	// Extends names a preset whose values are used for all fields left empty.
+	Extends string `yaml:"extends,omitempty"`
+	// CSVDelimiter separates the columns of CSV exports, f. i. ";".
+	CSVDelimiter string `yaml:"csvDelimiter,omitempty"`
+	// DecimalDelimiter separates the decimals of work times in exports, f. i. ",".
+	DecimalDelimiter string `yaml:"decimalDelimiter,omitempty"`
+	// SummaryRowLabel names the pipeline row which sums up all pipelines, f. i. "Gesamtzeit".
+	SummaryRowLabel string `yaml:"summaryRowLabel,omitempty"`
+	// SummaryColumnLabel names the column which sums up all days, f. i. "Gesamtzeit".
+	SummaryColumnLabel string `yaml:"summaryColumnLabel,omitempty"`
+	// DateHeaderLayouts contains Go time layouts of the date column headers, f. i. "02.01.2006".
+	DateHeaderLayouts []string `yaml:"dateHeaderLayouts,omitempty"`
+	// OutputDecimalSeparator separates the decimals of hours in RedSage's output.
+	OutputDecimalSeparator string `yaml:"outputDecimalSeparator,omitempty"`
+}
+
+// Presets contains locale presets by their name.
+type Presets struct {
+	presets map[string]Preset
+}
+
+var builtInPresets = []Preset{
+	{
+		Name:                   "de",
+		CSVDelimiter:           ";",
+		DecimalDelimiter:       ",",
+		SummaryRowLabel:        "Gesamtzeit",
+		SummaryColumnLabel:     "Gesamtzeit",
+		DateHeaderLayouts:      []string{"2006-01-02", "02.01.2006", "2.1.2006"},
+		OutputDecimalSeparator: ",",
+	},
+	{
+		Name:                   "en",
+		CSVDelimiter:           ",",
+		DecimalDelimiter:       ".",
+		SummaryRowLabel:        "Total time",
+		SummaryColumnLabel:     "Total time",
+		DateHeaderLayouts:      []string{"2006-01-02", "01/02/2006", "1/2/2006"},
+		OutputDecimalSeparator: ".",
+	},
+	{
+		Name:                   "fr",
+		CSVDelimiter:           ";",
+		DecimalDelimiter:       ",",
+		SummaryRowLabel:        "Temps total",
+		SummaryColumnLabel:     "Temps total",
+		DateHeaderLayouts:      []string{"2006-01-02", "02/01/2006"},
+		OutputDecimalSeparator: ",",
+	},
+}
+
+// BuiltIn returns the presets which ship with RedSage.
+func BuiltIn() *Presets {
+	result := &Presets{presets: map[string]Preset{}}
+	for _, preset := range builtInPresets {
+		result.presets[preset.Name] = preset
+	}
+
+	return result
+}
+
+// Names returns the names of all presets in lexical order.
+func (p *Presets) Names() []string {
+	result := make([]string, 0, len(p.presets))
+	for name := range p.presets {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// Lookup returns the preset with the given name.
+func (p *Presets) Lookup(name string) (Preset, error) {
+	preset, ok := p.presets[strings.ToLower(name)]
+	if !ok {
+		return Preset{}, errors.Errorf("unknown locale '%s' (known locales: %s)", name, strings.Join(p.Names(), ", "))
+	}
+
+	return preset, nil
+}
+
+// Add adds a preset or replaces one with the same name. Fields left empty are taken from the extended preset which
+// must already be known.
+func (p *Presets) Add(preset Preset) error {
+	preset.Name = strings.ToLower(preset.Name)
+	if preset.Name == "" {
+		return errors.New("locale preset name must not be empty")
+	}
+
+	if preset.Extends != "" {
+		base, err := p.Lookup(preset.Extends)
+		if err != nil {
+			return errors.Wrapf(err, "locale preset '%s' extends an unknown preset", preset.Name)
+		}
+		preset = inherit(preset, base)
+	}
+
+	p.presets[preset.Name] = preset
+	return nil
+}
+
+// LoadFile adds all presets from a YAML file which contains a list of presets. Presets may extend presets which are
+// defined earlier in the same file.
+//
+// Example:
+//  - name: de-ch
+//    extends: de
+//    decimalDelimiter: "."
+func (p *Presets) LoadFile(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "could not read locale file %s", path)
+	}
+
+	presets := []Preset{}
+	err = yaml.UnmarshalStrict(content, &presets)
+	if err != nil {
+		return errors.Wrapf(err, "could not parse locale file %s", path)
+	}
+
+	return p.AddAll(presets)
+}
+
+// AddAll adds the presets in their order.
+func (p *Presets) AddAll(presets []Preset) error {
+	for _, preset := range presets {
+		err := p.Add(preset)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func inherit(preset Preset, base Preset) Preset {
+	if preset.CSVDelimiter == "" {
+		preset.CSVDelimiter = base.CSVDelimiter
+	}
+	if preset.DecimalDelimiter == "" {
+		preset.DecimalDelimiter = base.DecimalDelimiter
+	}
+	if preset.SummaryRowLabel == "" {
+		preset.SummaryRowLabel = base.SummaryRowLabel
+	}
+	if preset.SummaryColumnLabel == "" {
+		preset.SummaryColumnLabel = base.SummaryColumnLabel
+	}
+	if len(preset.DateHeaderLayouts) == 0 {
+		preset.DateHeaderLayouts = base.DateHeaderLayouts
+	}
+	if preset.OutputDecimalSeparator == "" {
+		preset.OutputDecimalSeparator = base.OutputDecimalSeparator
+	}
+
+	return preset
+}
@@ -0,0 +1,97 @@
+package locale
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestPresets_Lookup(t *testing.T) {
+	t.Run("should find built-in preset case-insensitively", func(t *testing.T) {
+		actual, err := BuiltIn().Lookup("DE")
+
+		require.NoError(t, err)
+		assert.Equal(t, ";", actual.CSVDelimiter)
+		assert.Equal(t, "Gesamtzeit", actual.SummaryRowLabel)
+	})
+	t.Run("should list known presets for unknown preset", func(t *testing.T) {
+		_, err := BuiltIn().Lookup("tlh")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "de, en, fr")
+	})
+}
+
+func TestPresets_Add(t *testing.T) {
+	t.Run("should inherit empty fields", func(t *testing.T) {
+		sut := BuiltIn()
+
+		err := sut.Add(Preset{Name: "de-CH", Extends: "de", DecimalDelimiter: "."})
+
+		require.NoError(t, err)
+		actual, _ := sut.Lookup("de-ch")
+		assert.Equal(t, ".", actual.DecimalDelimiter)
+		assert.Equal(t, ";", actual.CSVDelimiter)
+		assert.Equal(t, "Gesamtzeit", actual.SummaryColumnLabel)
+	})
+	t.Run("should fail for unknown base", func(t *testing.T) {
+		err := BuiltIn().Add(Preset{Name: "xx", Extends: "yy"})
+
+		require.Error(t, err)
+	})
+	t.Run("should fail for empty name", func(t *testing.T) {
+		err := BuiltIn().Add(Preset{})
+
+		require.Error(t, err)
+	})
+}
+
+func TestPresets_LoadFile(t *testing.T) {
+	t.Run("should load presets in order", func(t *testing.T) {
+		file, _ := ioutil.TempFile(os.TempDir(), "locales-")
+		path := file.Name()
+		defer os.Remove(path)
+		_, _ = file.WriteString(`
+- name: nl
+  extends: en
+  csvDelimiter: ";"
+  decimalDelimiter: ","
+  summaryRowLabel: Totale tijd
+  summaryColumnLabel: Totale tijd
+  outputDecimalSeparator: ","
+- name: nl-be
+  extends: nl
+  dateHeaderLayouts: ["02/01/2006"]
+`)
+		sut := BuiltIn()
+
+		// when
+		err := sut.LoadFile(path)
+
+		// then
+		require.NoError(t, err)
+		actual, _ := sut.Lookup("nl-be")
+		assert.Equal(t, Preset{
+			Name:                   "nl-be",
+			Extends:                "nl",
+			CSVDelimiter:           ";",
+			DecimalDelimiter:       ",",
+			SummaryRowLabel:        "Totale tijd",
+			SummaryColumnLabel:     "Totale tijd",
+			DateHeaderLayouts:      []string{"02/01/2006"},
+			OutputDecimalSeparator: ",",
+		}, actual)
+	})
+	t.Run("should fail for unknown fields", func(t *testing.T) {
+		file, _ := ioutil.TempFile(os.TempDir(), "locales-")
+		path := file.Name()
+		defer os.Remove(path)
+		_, _ = file.WriteString("- name: nl\n  delimiter: \";\"\n")
+
+		err := BuiltIn().LoadFile(path)
+
+		require.Error(t, err)
+	})
+}
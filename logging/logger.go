@@ -1,24 +1,47 @@
 package logging
 
 import (
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"os"
+)
+
+// Log formats which are supported by Init.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
 )
 
 var (
-	loggerInstance = logrus.New()
+	loggerInstance = newLogger()
 )
 
-type AppLogger struct {
-	logger *logrus.Logger
+func newLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(os.Stderr)
+
+	return logger
 }
 
-func Init(logLevel logrus.Level) error {
-	loggerInstance := logrus.New()
+// Init configures the logger which is shared by all packages. Log messages are written to stderr so that stdout only
+// contains the results.
+func Init(logLevel logrus.Level, format string) error {
+	switch format {
+	case FormatText, "":
+		loggerInstance.SetFormatter(&logrus.TextFormatter{})
+	case FormatJSON:
+		loggerInstance.SetFormatter(&logrus.JSONFormatter{})
+	default:
+		return errors.Errorf("unsupported log format '%s' (supported formats: %s, %s)", format, FormatText, FormatJSON)
+	}
+
 	loggerInstance.SetLevel(logLevel)
+	loggerInstance.SetOutput(os.Stderr)
 
 	return nil
 }
 
+// Logger returns the shared logger. Packages may keep the returned logger because Init configures it in place.
 func Logger() *logrus.Logger {
 	return loggerInstance
 }
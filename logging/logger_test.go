@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"bytes"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+func TestInit(t *testing.T) {
+	logger := Logger()
+	defer func() { _ = Init(logrus.InfoLevel, FormatText) }()
+
+	t.Run("should configure the shared logger", func(t *testing.T) {
+		// when
+		err := Init(logrus.DebugLevel, FormatJSON)
+
+		// then
+		require.NoError(t, err)
+		assert.Same(t, logger, Logger())
+		assert.Equal(t, logrus.DebugLevel, logger.GetLevel())
+		assert.Equal(t, os.Stderr, logger.Out)
+
+		buffer := &bytes.Buffer{}
+		logger.SetOutput(buffer)
+		logger.Debug("hello")
+		assert.Contains(t, buffer.String(), `"msg":"hello"`)
+	})
+	t.Run("should fail for unknown formats", func(t *testing.T) {
+		// when
+		err := Init(logrus.DebugLevel, "xml")
+
+		// then
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,57 @@
+package output
+
+import (
+	"encoding/csv"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"io"
+)
+
+var csvHeader = []string{"Pipeline", "Date", "Start", "End", "Hours", "Note"}
+
+type csvWriter struct {
+	options Options
+}
+
+// Write writes one CSV row per time slot. Days without work time are left out.
+func (cw *csvWriter) Write(w io.Writer, crunched *core.CrunchedOutput) error {
+	delimiter := []rune(cw.options.CSVDelimiter)
+	if len(delimiter) != 1 {
+		return errors.Errorf("CSV delimiter must be a single character: '%s'", cw.options.CSVDelimiter)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = delimiter[0]
+
+	err := writer.Write(csvHeader)
+	if err != nil {
+		return err
+	}
+
+	for _, pipelineName := range crunched.SortedKeys() {
+		pipeline := crunched.NamedDaySageValues[core.PipelineName(pipelineName)]
+
+		for _, date := range pipeline.SortedKeys() {
+			note, _ := describeDay(cw.options, date)
+
+			for _, slot := range pipeline.TimeSlots(date) {
+				if slot.IsEmpty() {
+					continue
+				}
+
+				hours, err := slotHours(slot)
+				if err != nil {
+					return errors.Wrapf(err, "could not write time slot of pipeline %s on %s", pipelineName, date)
+				}
+
+				err = writer.Write([]string{pipelineName, date, slot.Start, slot.End, FormatHours(hours, cw.options.DecimalSeparator), note})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
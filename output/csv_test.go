@@ -0,0 +1,31 @@
+package output
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_csvWriter_Write(t *testing.T) {
+	t.Run("should write localized hours and skip empty days", func(t *testing.T) {
+		sut, _ := New(FormatCSV, Options{DecimalSeparator: ",", Days: weekendDescriber{}})
+		buffer := &bytes.Buffer{}
+
+		err := sut.Write(buffer, createCrunchedOutput())
+
+		require.NoError(t, err)
+		expected := "Pipeline;Date;Start;End;Hours;Note\n" +
+			"Pipeline A;2021-05-07;08:00;12:00;4,00;\n" +
+			"Pipeline A;2021-05-08;08:00;08:45;0,75;Saturday\n" +
+			"Pipeline B;2021-05-07;13:00;14:30;1,50;\n"
+		assert.Equal(t, expected, buffer.String())
+	})
+	t.Run("should fail for multi-character delimiter", func(t *testing.T) {
+		sut, _ := New(FormatCSV, Options{CSVDelimiter: ";;"})
+
+		err := sut.Write(&bytes.Buffer{}, createCrunchedOutput())
+
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,69 @@
+package output
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"io"
+)
+
+type jsonDocument struct {
+	Pipelines []jsonPipeline `json:"pipelines"`
+}
+
+type jsonPipeline struct {
+	Name string    `json:"name"`
+	Days []jsonDay `json:"days"`
+}
+
+type jsonDay struct {
+	Date  string     `json:"date"`
+	Hours float64    `json:"hours"`
+	Note  string     `json:"note,omitempty"`
+	Slots []jsonSlot `json:"slots"`
+}
+
+type jsonSlot struct {
+	Start string  `json:"start"`
+	End   string  `json:"end"`
+	Hours float64 `json:"hours"`
+}
+
+type jsonWriter struct {
+	options Options
+}
+
+// Write writes all pipelines with their days and time slots as an indented JSON document. Days without work time
+// contain no time slots.
+func (jw *jsonWriter) Write(w io.Writer, crunched *core.CrunchedOutput) error {
+	document := jsonDocument{Pipelines: []jsonPipeline{}}
+
+	for _, pipelineName := range crunched.SortedKeys() {
+		pipeline := crunched.NamedDaySageValues[core.PipelineName(pipelineName)]
+		outPipeline := jsonPipeline{Name: pipelineName, Days: []jsonDay{}}
+
+		for _, date := range pipeline.SortedKeys() {
+			note, _ := describeDay(jw.options, date)
+			day := jsonDay{Date: date, Note: note, Slots: []jsonSlot{}}
+
+			for _, slot := range pipeline.TimeSlots(date) {
+				if slot.IsEmpty() {
+					continue
+				}
+
+				hours, err := slotHours(slot)
+				if err != nil {
+					return errors.Wrapf(err, "could not write time slot of pipeline %s on %s", pipelineName, date)
+				}
+				day.Hours += hours
+				day.Slots = append(day.Slots, jsonSlot{Start: slot.Start, End: slot.End, Hours: hours})
+			}
+			outPipeline.Days = append(outPipeline.Days, day)
+		}
+		document.Pipelines = append(document.Pipelines, outPipeline)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(document)
+}
@@ -0,0 +1,36 @@
+package output
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_jsonWriter_Write(t *testing.T) {
+	sut, _ := New(FormatJSON, Options{Days: weekendDescriber{}})
+	buffer := &bytes.Buffer{}
+
+	err := sut.Write(buffer, createCrunchedOutput())
+
+	require.NoError(t, err)
+	expected := `{
+  "pipelines": [
+    {
+      "name": "Pipeline A",
+      "days": [
+        {"date": "2021-05-06", "hours": 0, "slots": []},
+        {"date": "2021-05-07", "hours": 4, "slots": [{"start": "08:00", "end": "12:00", "hours": 4}]},
+        {"date": "2021-05-08", "hours": 0.75, "note": "Saturday", "slots": [{"start": "08:00", "end": "08:45", "hours": 0.75}]}
+      ]
+    },
+    {
+      "name": "Pipeline B",
+      "days": [
+        {"date": "2021-05-07", "hours": 1.5, "slots": [{"start": "13:00", "end": "14:30", "hours": 1.5}]}
+      ]
+    }
+  ]
+}`
+	assert.JSONEq(t, expected, buffer.String())
+}
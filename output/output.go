@@ -0,0 +1,89 @@
+package output
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"io"
+	"strings"
+)
+
+// Output format names.
+const (
+	FormatText = "text"
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+const (
+	defaultDecimalSeparator = "."
+	defaultCSVDelimiter     = ";"
+)
+
+// DayDescriber explains why a date is no working day, f. i. a holiday calendar.
+type DayDescriber interface {
+	Describe(date string) (string, bool)
+}
+
+// Options contain configuration values that modify the output.
+type Options struct {
+	// DecimalSeparator separates the decimals of hours, f. i. ",". Defaults to ".".
+	DecimalSeparator string
+	// CSVDelimiter separates the columns of the CSV format. Defaults to ";".
+	CSVDelimiter string
+	// Days marks non-working days in the output (optional).
+	Days DayDescriber
+}
+
+// Writer writes crunched time slots in a specific format.
+type Writer interface {
+	Write(w io.Writer, crunched *core.CrunchedOutput) error
+}
+
+// Formats returns all supported output format names.
+func Formats() []string {
+	return []string{FormatText, FormatCSV, FormatJSON}
+}
+
+// New creates a writer for the given format.
+func New(format string, options Options) (Writer, error) {
+	if options.DecimalSeparator == "" {
+		options.DecimalSeparator = defaultDecimalSeparator
+	}
+	if options.CSVDelimiter == "" {
+		options.CSVDelimiter = defaultCSVDelimiter
+	}
+
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		return &textWriter{options: options}, nil
+	case FormatCSV:
+		return &csvWriter{options: options}, nil
+	case FormatJSON:
+		return &jsonWriter{options: options}, nil
+	default:
+		return nil, errors.Errorf("unsupported output format '%s' (supported formats: %s)", format, strings.Join(Formats(), ", "))
+	}
+}
+
+// FormatHours formats hours with two decimals and the given decimal separator, f. i. "7,50".
+func FormatHours(hours float64, decimalSeparator string) string {
+	return strings.Replace(fmt.Sprintf("%.2f", hours), ".", decimalSeparator, 1)
+}
+
+func describeDay(options Options, date string) (string, bool) {
+	if options.Days == nil {
+		return "", false
+	}
+
+	return options.Days.Describe(date)
+}
+
+func slotHours(slot core.TimeSlot) (float64, error) {
+	duration, err := slot.Duration()
+	if err != nil {
+		return 0, err
+	}
+
+	return duration.Hours(), nil
+}
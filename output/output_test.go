@@ -0,0 +1,49 @@
+package output
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type weekendDescriber struct{}
+
+func (wd weekendDescriber) Describe(date string) (string, bool) {
+	if date == "2021-05-08" {
+		return "Saturday", true
+	}
+	return "", false
+}
+
+func createCrunchedOutput() *core.CrunchedOutput {
+	crunched := core.NewCrunchedOutput()
+	pipelineB, _ := crunched.AddPipeline("Pipeline B")
+	pipelineB.PutTimeSlot("2021-05-07", "13:00", "14:30")
+	pipelineA, _ := crunched.AddPipeline("Pipeline A")
+	pipelineA.PutTimeSlot("2021-05-07", "08:00", "12:00")
+	pipelineA.PutEmptyTimeSlot("2021-05-06")
+	pipelineA.PutTimeSlot("2021-05-08", "08:00", "08:45")
+
+	return crunched
+}
+
+func TestNew(t *testing.T) {
+	t.Run("should fail for unknown format", func(t *testing.T) {
+		_, err := New("pdf", Options{})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "text, csv, json")
+	})
+	t.Run("should default to text", func(t *testing.T) {
+		actual, err := New("", Options{})
+
+		require.NoError(t, err)
+		assert.IsType(t, &textWriter{}, actual)
+	})
+}
+
+func TestFormatHours(t *testing.T) {
+	assert.Equal(t, "7,50", FormatHours(7.5, ","))
+	assert.Equal(t, "0.25", FormatHours(0.25, "."))
+}
@@ -0,0 +1,40 @@
+package output
+
+import (
+	"fmt"
+	"github.com/ppxl/sagemine/core"
+	"io"
+)
+
+type textWriter struct {
+	options Options
+}
+
+// Write prints every pipeline followed by one line per date with tab-separated time slots, ready to be typed into
+// Sage. Non-working days are marked at the end of the line.
+func (tw *textWriter) Write(w io.Writer, crunched *core.CrunchedOutput) error {
+	for _, pipelineName := range crunched.SortedKeys() {
+		pipeline := crunched.NamedDaySageValues[core.PipelineName(pipelineName)]
+		_, err := fmt.Fprintf(w, "%v\n", pipelineName)
+		if err != nil {
+			return err
+		}
+
+		for _, date := range pipeline.SortedKeys() {
+			line := fmt.Sprintf("%s\t", date)
+			for _, timeslot := range pipeline.TimeSlots(date) {
+				line += fmt.Sprintf("%s\t", timeslot.String())
+			}
+			if reason, nonWorking := describeDay(tw.options, date); nonWorking {
+				line += fmt.Sprintf("(%s)", reason)
+			}
+
+			_, err = fmt.Fprintln(w, line)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,24 @@
+package output
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_textWriter_Write(t *testing.T) {
+	sut, _ := New(FormatText, Options{Days: weekendDescriber{}})
+	buffer := &bytes.Buffer{}
+
+	err := sut.Write(buffer, createCrunchedOutput())
+
+	require.NoError(t, err)
+	expected := "Pipeline A\n" +
+		"2021-05-06\t- - -\t\n" +
+		"2021-05-07\t08:00 - 12:00\t\n" +
+		"2021-05-08\t08:00 - 08:45\t(Saturday)\n" +
+		"Pipeline B\n" +
+		"2021-05-07\t13:00 - 14:30\t\n"
+	assert.Equal(t, expected, buffer.String())
+}
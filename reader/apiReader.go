@@ -0,0 +1,163 @@
+package reader
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	timeEntriesPath    = "/time_entries.json"
+	timeEntriesPerPage = 100
+	apiKeyHeader       = "X-Redmine-API-Key"
+	apiTimeout         = 30 * time.Second
+	redactedPassword   = "xxxxx"
+)
+
+type timeEntriesPage struct {
+	TimeEntries []timeEntry `json:"time_entries"`
+	TotalCount  int         `json:"total_count"`
+}
+
+type timeEntry struct {
+	Project struct {
+		Name string `json:"name"`
+	} `json:"project"`
+	Hours   float64 `json:"hours"`
+	SpentOn string  `json:"spent_on"`
+}
+
+type apiReader struct {
+	options APIOptions
+	client  *http.Client
+}
+
+func newAPIReader(options APIOptions) *apiReader {
+	return &apiReader{options: options, client: &http.Client{Timeout: apiTimeout}}
+}
+
+// Read reads the time entries of the configured date range page by page and sums up the hours per project and day.
+// Each Redmine project becomes a pipeline.
+func (ar *apiReader) Read() (*core.PipelineData, error) {
+	if ar.options.RedmineURL == "" {
+		return nil, errors.New("Redmine URL must not be empty")
+	}
+	if ar.options.Credentials.IsEmpty() {
+		return nil, errors.Errorf("no credentials found for %s", ar.displayURL())
+	}
+
+	result := core.NewPipelineData()
+	// Redmine may return less entries than requested, so the offset advances by the entries of each page
+	for offset := 0; ; {
+		page, err := ar.readPage(offset)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range page.TimeEntries {
+			err = putTimeEntry(result, entry)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		offset += len(page.TimeEntries)
+		if len(page.TimeEntries) == 0 || offset >= page.TotalCount {
+			break
+		}
+	}
+
+	log.Infof("read %d pipelines from %s", result.Entries(), ar.displayURL())
+	return result, nil
+}
+
+func (ar *apiReader) readPage(offset int) (*timeEntriesPage, error) {
+	request, err := http.NewRequest(http.MethodGet, ar.pageURL(offset), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid Redmine URL %s", ar.displayURL())
+	}
+	request.Header.Set("Accept", "application/json")
+
+	creds := ar.options.Credentials
+	if creds.APIKey != "" {
+		request.Header.Set(apiKeyHeader, creds.APIKey.Reveal())
+	} else {
+		request.SetBasicAuth(creds.User, creds.Password.Reveal())
+	}
+
+	response, err := ar.client.Do(request)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not query time entries of %s", ar.displayURL())
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("could not query time entries of %s: %s", ar.displayURL(), response.Status)
+	}
+
+	page := &timeEntriesPage{}
+	err = json.NewDecoder(response.Body).Decode(page)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse time entries of %s", ar.displayURL())
+	}
+
+	return page, nil
+}
+
+func (ar *apiReader) pageURL(offset int) string {
+	query := url.Values{}
+	query.Set("limit", strconv.Itoa(timeEntriesPerPage))
+	query.Set("offset", strconv.Itoa(offset))
+	if from := ar.options.DateRange.FromDate(); from != "" {
+		query.Set("from", from)
+	}
+	if to := ar.options.DateRange.ToDate(); to != "" {
+		query.Set("to", to)
+	}
+	if ar.options.UserID != "" {
+		query.Set("user_id", ar.options.UserID)
+	}
+
+	return strings.TrimSuffix(ar.options.RedmineURL, "/") + timeEntriesPath + "?" + query.Encode()
+}
+
+// displayURL returns the Redmine URL without a password so that it can be logged.
+func (ar *apiReader) displayURL() string {
+	parsed, err := url.Parse(ar.options.RedmineURL)
+	if err != nil {
+		return "the configured Redmine URL"
+	}
+	if _, hasPassword := parsed.User.Password(); hasPassword {
+		parsed.User = url.UserPassword(parsed.User.Username(), redactedPassword)
+	}
+
+	return parsed.String()
+}
+
+func putTimeEntry(pdata *core.PipelineData, entry timeEntry) error {
+	pipelineName := entry.Project.Name
+	if pipelineName == "" {
+		return errors.Errorf("time entry of %s has no project", entry.SpentOn)
+	}
+
+	_, err := time.Parse(core.DateLayout, entry.SpentOn)
+	if err != nil {
+		return errors.Wrapf(err, "time entry of %s has an invalid date", pipelineName)
+	}
+
+	pipeline, ok := pdata.NamedDayRedmineValues[core.PipelineName(pipelineName)]
+	if !ok {
+		pipeline, err = pdata.AddPipeline(pipelineName)
+		if err != nil {
+			return err
+		}
+	}
+	pipeline.PutWorkTime(entry.SpentOn, entry.Hours)
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package reader
+
+import (
+	"fmt"
+	"github.com/ppxl/sagemine/core"
+	"github.com/ppxl/sagemine/credentials"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func Test_apiReader_Read(t *testing.T) {
+	t.Run("should sum up time entries of all pages per project and day", func(t *testing.T) {
+		queries := []string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/redmine/time_entries.json", r.URL.Path)
+			assert.Equal(t, "0123456789abcdef", r.Header.Get(apiKeyHeader))
+			queries = append(queries, r.URL.RawQuery)
+			if r.URL.Query().Get("offset") == "0" {
+				_, _ = fmt.Fprint(w, `{"time_entries":[
+					{"project":{"name":"Pipeline A"},"hours":2.5,"spent_on":"2021-05-03"},
+					{"project":{"name":"Pipeline A"},"hours":2.5,"spent_on":"2021-05-03"}],"total_count":3}`)
+				return
+			}
+			_, _ = fmt.Fprint(w, `{"time_entries":[{"project":{"name":"Pipeline B/2"},"hours":1.5,"spent_on":"2021-05-04"}],"total_count":3}`)
+		}))
+		defer server.Close()
+		dateRange, _ := core.NewDateRange("2021-05-03", "2021-05-07")
+		sut := newAPIReader(APIOptions{
+			RedmineURL:  server.URL + "/redmine/",
+			Credentials: credentials.Credentials{APIKey: "0123456789abcdef"},
+			UserID:      "me",
+			DateRange:   dateRange,
+		})
+
+		// when
+		actual, err := sut.Read()
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, queries, 2)
+		assert.Equal(t, "from=2021-05-03&limit=100&offset=0&to=2021-05-07&user_id=me", queries[0])
+		assert.Contains(t, queries[1], "offset=2")
+		assert.Equal(t, 5.0, actual.NamedDayRedmineValues[pipelineA].WorkTime("2021-05-03"))
+		assert.Equal(t, 1.5, actual.NamedDayRedmineValues["Pipeline B/2"].WorkTime("2021-05-04"))
+	})
+	t.Run("should authenticate with user and password", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, password, ok := r.BasicAuth()
+			if !ok || user != "jdoe" || password != "s3cret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = fmt.Fprint(w, `{"time_entries":[],"total_count":0}`)
+		}))
+		defer server.Close()
+		sut := newAPIReader(APIOptions{RedmineURL: server.URL, Credentials: credentials.Credentials{User: "jdoe", Password: "s3cret"}})
+
+		// when
+		actual, err := sut.Read()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 0, actual.Entries())
+	})
+	t.Run("should not reveal credentials in errors", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+		sut := newAPIReader(APIOptions{
+			RedmineURL:  "http://jdoe:s3cret@" + server.Listener.Addr().String(),
+			Credentials: credentials.Credentials{APIKey: "0123456789abcdef"},
+		})
+
+		// when
+		_, err := sut.Read()
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "401")
+		assert.NotContains(t, err.Error(), "s3cret")
+		assert.NotContains(t, err.Error(), "0123456789abcdef")
+	})
+	t.Run("should fail without credentials", func(t *testing.T) {
+		sut := newAPIReader(APIOptions{RedmineURL: "http://redmine.example.com"})
+
+		// when
+		_, err := sut.Read()
+
+		// then
+		require.Error(t, err)
+	})
+}
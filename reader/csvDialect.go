@@ -0,0 +1,189 @@
+package reader
+
+import (
+	"bytes"
+	"encoding/csv"
+	"github.com/pkg/errors"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encoding names which can be detected or set with CSVOptions.Encoding.
+const (
+	EncodingUTF8        = "utf-8"
+	EncodingUTF16LE     = "utf-16le"
+	EncodingUTF16BE     = "utf-16be"
+	EncodingWindows1252 = "windows-1252"
+)
+
+// sniffedLines limits the number of lines which are inspected to detect the column delimiter.
+const sniffedLines = 10
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+
+	delimiterCandidates = []rune{';', ',', '\t', '|'}
+
+	commaDecimalPattern = regexp.MustCompile(`^-?\d+,\d+$`)
+	dotDecimalPattern   = regexp.MustCompile(`^-?\d+\.\d+$`)
+)
+
+// windows1252 maps the bytes 0x80 to 0x9F to their characters. All other bytes match the ISO-8859-1 and Unicode code
+// points of the same value.
+var windows1252 = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\u008D', 'Ž', '\u008F',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\u009D', 'ž', 'Ÿ',
+}
+
+// Dialect describes how a CSV file was read.
+type Dialect struct {
+	Encoding         string
+	ColumnDelimiter  string
+	DecimalDelimiter string
+}
+
+// decodeText converts the raw content into UTF-8 and strips a byte order mark. An empty encoding detects the
+// encoding from the byte order mark or the content: content which is no valid UTF-8 is read as Windows-1252, a
+// superset of ISO-8859-1.
+func decodeText(raw []byte, encoding string) (string, string, error) {
+	if encoding == "" {
+		encoding = detectEncoding(raw)
+	}
+
+	switch strings.ToLower(encoding) {
+	case EncodingUTF8, "utf8":
+		return string(bytes.TrimPrefix(raw, bomUTF8)), EncodingUTF8, nil
+	case EncodingUTF16LE:
+		return decodeUTF16(bytes.TrimPrefix(raw, bomUTF16LE), false), EncodingUTF16LE, nil
+	case EncodingUTF16BE:
+		return decodeUTF16(bytes.TrimPrefix(raw, bomUTF16BE), true), EncodingUTF16BE, nil
+	case EncodingWindows1252, "iso-8859-1", "latin1":
+		return decodeWindows1252(raw), EncodingWindows1252, nil
+	default:
+		return "", "", errors.Errorf("unsupported encoding '%s' (supported encodings: %s, %s, %s, %s)",
+			encoding, EncodingUTF8, EncodingUTF16LE, EncodingUTF16BE, EncodingWindows1252)
+	}
+}
+
+func detectEncoding(raw []byte) string {
+	switch {
+	case bytes.HasPrefix(raw, bomUTF8):
+		return EncodingUTF8
+	case bytes.HasPrefix(raw, bomUTF16LE):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(raw, bomUTF16BE):
+		return EncodingUTF16BE
+	case utf8.Valid(raw):
+		return EncodingUTF8
+	default:
+		return EncodingWindows1252
+	}
+}
+
+func decodeUTF16(raw []byte, bigEndian bool) string {
+	units := make([]uint16, 0, len(raw)/2)
+	for i := 0; i+1 < len(raw); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+		} else {
+			units = append(units, uint16(raw[i+1])<<8|uint16(raw[i]))
+		}
+	}
+
+	return string(utf16.Decode(units))
+}
+
+func decodeWindows1252(raw []byte) string {
+	var result strings.Builder
+	for _, b := range raw {
+		if b >= 0x80 && b <= 0x9F {
+			result.WriteRune(windows1252[b-0x80])
+			continue
+		}
+		result.WriteRune(rune(b))
+	}
+
+	return result.String()
+}
+
+// sniffColumnDelimiter returns the candidate which splits the first lines into the same number of columns with the
+// most columns. Ties are resolved by the order of the candidates which favours the German semicolon.
+func sniffColumnDelimiter(content string) (rune, bool) {
+	best := delimiterCandidates[0]
+	bestColumns := 1
+
+	for _, candidate := range delimiterCandidates {
+		columns, consistent := countColumns(content, candidate)
+		if consistent && columns > bestColumns {
+			best = candidate
+			bestColumns = columns
+		}
+	}
+
+	return best, bestColumns > 1
+}
+
+func countColumns(content string, delimiter rune) (int, bool) {
+	r := csv.NewReader(strings.NewReader(content))
+	r.Comma = delimiter
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	columns := 0
+	for i := 0; i < sniffedLines; i++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, false
+		}
+
+		if columns == 0 {
+			columns = len(record)
+		} else if len(record) != columns {
+			return 0, false
+		}
+	}
+
+	return columns, columns > 0
+}
+
+// sniffDecimalDelimiter returns the decimal delimiter which most of the value cells use. The header line and the
+// pipeline column are not inspected. A comma column delimiter always implies a decimal point.
+func sniffDecimalDelimiter(data [][]string, columnDelimiter rune) string {
+	if columnDelimiter == ',' {
+		return "."
+	}
+
+	commaDecimals := 0
+	dotDecimals := 0
+	for currentLine, line := range data {
+		if currentLine == 0 {
+			continue
+		}
+		for currentColumn, cell := range line {
+			if currentColumn == 0 {
+				continue
+			}
+			cell = strings.TrimSpace(cell)
+			if commaDecimalPattern.MatchString(cell) {
+				commaDecimals++
+			} else if dotDecimalPattern.MatchString(cell) {
+				dotDecimals++
+			}
+		}
+	}
+
+	if commaDecimals > dotDecimals {
+		return ","
+	}
+
+	return "."
+}
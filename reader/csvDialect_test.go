@@ -0,0 +1,78 @@
+package reader
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_decodeText(t *testing.T) {
+	t.Run("should strip UTF-8 BOM", func(t *testing.T) {
+		actual, encoding, err := decodeText([]byte("\xEF\xBB\xBFPipeline Ä"), "")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Pipeline Ä", actual)
+		assert.Equal(t, EncodingUTF8, encoding)
+	})
+	t.Run("should convert Windows-1252", func(t *testing.T) {
+		actual, encoding, err := decodeText([]byte("Pipeline \xC4 \x80 \x96"), "")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Pipeline Ä € –", actual)
+		assert.Equal(t, EncodingWindows1252, encoding)
+	})
+	t.Run("should convert UTF-16LE with BOM", func(t *testing.T) {
+		actual, encoding, err := decodeText([]byte{0xFF, 0xFE, 'A', 0, ';', 0, 0xC4, 0}, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, "A;Ä", actual)
+		assert.Equal(t, EncodingUTF16LE, encoding)
+	})
+	t.Run("should use configured encoding", func(t *testing.T) {
+		actual, encoding, err := decodeText([]byte("\xC3\x84"), "ISO-8859-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Ã„", actual)
+		assert.Equal(t, EncodingWindows1252, encoding)
+	})
+	t.Run("should fail for unknown encoding", func(t *testing.T) {
+		_, _, err := decodeText([]byte("A"), "ebcdic")
+
+		require.Error(t, err)
+	})
+}
+
+func Test_sniffColumnDelimiter(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    rune
+	}{
+		{name: "german export", content: "Pipeline;2021-05-03;2021-05-04\nPipeline A;7,50;1,00\n", want: ';'},
+		{name: "english export", content: "Pipeline,2021-05-03,2021-05-04\nPipeline A,7.50,\"1.00\"\n", want: ','},
+		{name: "tab separated", content: "Pipeline\t2021-05-03\nPipeline A\t7,5\n", want: '\t'},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, detected := sniffColumnDelimiter(tt.content)
+
+			assert.True(t, detected)
+			assert.Equal(t, string(tt.want), string(actual))
+		})
+	}
+	t.Run("should fall back to semicolon", func(t *testing.T) {
+		actual, detected := sniffColumnDelimiter("Pipeline\n")
+
+		assert.False(t, detected)
+		assert.Equal(t, ";", string(actual))
+	})
+}
+
+func Test_sniffDecimalDelimiter(t *testing.T) {
+	german := [][]string{{"Pipeline", "2021-05-03", "2021-05-04"}, {"Pipeline A", "7,50", ""}, {"Pipeline 1.5", "1", "2,25"}}
+	english := [][]string{{"Pipeline", "2021-05-03"}, {"Pipeline A", "7.50"}}
+
+	assert.Equal(t, ",", sniffDecimalDelimiter(german, ';'))
+	assert.Equal(t, ".", sniffDecimalDelimiter(english, ';'))
+	assert.Equal(t, ".", sniffDecimalDelimiter(german, ','))
+}
@@ -2,131 +2,110 @@ package reader
 
 import (
 	"encoding/csv"
-	"fmt"
 	"github.com/pkg/errors"
 	"github.com/ppxl/sagemine/core"
-	"github.com/ppxl/sagemine/logging"
-	"os"
-	"strconv"
+	"io/ioutil"
 	"strings"
 )
 
-const (
-	CSV = iota
-	RestAPI
-)
-
-var log = logging.Logger()
-
 type CSVOptions struct {
-	Filename         string
-	CSVDelimiter     string
+	Filename string
+	// Content is read instead of the file if it is set. Filename then only names the source in errors.
+	Content []byte
+	// Encoding of the file, f. i. "windows-1252". Empty detects the encoding.
+	Encoding string
+	// CSVDelimiter separates the columns. Empty detects the delimiter.
+	CSVDelimiter string
+	// DecimalDelimiter separates the decimals of the work time. Empty detects the delimiter.
 	DecimalDelimiter string
 	SkipColumnNames  []string
-	SkipSummaryLine  bool
-}
-
-type APIOptions struct {
-	RedmineURL      string
-	RedmineUser     string
-	RedminePassword string
-}
-
-type Options struct {
-	Type       int
-	CSVOptions CSVOptions
-	APIOptions APIOptions
-}
-
-type RedmineDataReader interface {
-	Read() (*core.PipelineData, error)
+	// SkipRowNames contains pipelines which are ignored, f. i. a summary row.
+	SkipRowNames    []string
+	SkipSummaryLine bool
+	// DateLayouts contains Go time layouts of date column headers which are converted to the format YYYY-MM-DD.
+	DateLayouts []string
 }
 
 type csvReader struct {
 	options CSVOptions
-}
-
-func New(options Options) *csvReader {
-	switch options.Type {
-	case CSV:
-		return newCSVReader(options.CSVOptions)
-	case RestAPI:
-		fallthrough
-	default:
-		log.Panicf("unsupported Redmine reader type %d", options.Type)
-	}
-	return nil
+	dialect Dialect
 }
 
 func newCSVReader(options CSVOptions) *csvReader {
 	return &csvReader{options: options}
 }
 
+// Read reads a Redmine CSV export. Encoding, column delimiter and decimal delimiter are detected from the content
+// unless they are configured.
 func (cr *csvReader) Read() (*core.PipelineData, error) {
-	fileReader, err := os.OpenFile(cr.options.Filename, os.O_RDWR|os.O_CREATE, os.ModePerm)
-	if err != nil {
-		panic(err)
+	raw := cr.options.Content
+	if raw == nil {
+		var err error
+		raw, err = ioutil.ReadFile(cr.options.Filename)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read CSV file %s", cr.options.Filename)
+		}
 	}
-	defer fileReader.Close()
-	commaRunes := []rune(cr.options.CSVDelimiter)
 
-	r := csv.NewReader(fileReader)
-	r.Comma = commaRunes[0]
-	r.Comment = '#'
+	content, encoding, err := decodeText(raw, cr.options.Encoding)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not decode CSV file %s", cr.options.Filename)
+	}
 
-	data, err := r.ReadAll()
+	comma, err := columnDelimiter(cr.options, content)
 	if err != nil {
 		return nil, err
 	}
 
-	result := core.NewPipelineData()
-	columnHeaders := []string{}
-	columnsToSkip := []int{}
-
-	for currentLine, line := range data {
-		var pipeline *core.RedmineWorkPerDay
-
-		if currentLine == 0 {
-			columnHeaders = line
-			columnsToSkip = buildSkipColumns(columnHeaders, cr.options.SkipColumnNames)
-			continue
-		}
-
-		if cr.options.SkipSummaryLine && isLastLine(currentLine, data) {
-			break
-		}
-
-		for currentColumn, cell := range line {
-			fmt.Printf("%s\t", cell)
-
-			if currentColumn == 0 {
-				pipeline, err = result.AddPipeline(cell)
-				if err != nil {
-					return nil, errors.Wrapf(err, "failed to read line %d from CSV: error while adding pipeline %s", currentLine, cell)
-				}
-				continue
-			}
+	r := csv.NewReader(strings.NewReader(content))
+	r.Comma = comma
+	r.Comment = '#'
 
-			if skipColumn(currentColumn, columnsToSkip) {
-				continue
-			}
+	data, err := r.ReadAll()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse CSV file %s", cr.options.Filename)
+	}
 
-			workTimeRaw := formatDecimal(cell, cr.options.DecimalDelimiter)
+	decimalDelimiter := cr.options.DecimalDelimiter
+	if decimalDelimiter == "" {
+		decimalDelimiter = sniffDecimalDelimiter(data, comma)
+	}
 
-			workTime, err := strconv.ParseFloat(workTimeRaw, 64)
-			if err != nil {
-				return nil, errors.Wrapf(err, "could not cast value '%s' to float (line %d, column %d)", cell, currentLine, currentColumn)
-			}
+	cr.dialect = Dialect{Encoding: encoding, ColumnDelimiter: string(comma), DecimalDelimiter: decimalDelimiter}
+	log.Warnf("reading %s with encoding %s, column delimiter %q and decimal delimiter %q",
+		cr.options.Filename, encoding, string(comma), decimalDelimiter)
+
+	return parseTable(data, tableOptions{
+		Source:           cr.options.Filename,
+		DecimalDelimiter: decimalDelimiter,
+		SkipColumnNames:  cr.options.SkipColumnNames,
+		SkipRowNames:     cr.options.SkipRowNames,
+		SkipSummaryLine:  cr.options.SkipSummaryLine,
+		DateLayouts:      cr.options.DateLayouts,
+	})
+}
 
-			currentDay := columnHeaders[currentColumn]
+// Dialect returns the encoding and delimiters of the last read.
+func (cr *csvReader) Dialect() Dialect {
+	return cr.dialect
+}
 
-			pipeline.PutWorkTime(currentDay, workTime)
+// columnDelimiter returns the configured column delimiter or else detects it from the content.
+func columnDelimiter(options CSVOptions, content string) (rune, error) {
+	if options.CSVDelimiter != "" {
+		commaRunes := []rune(options.CSVDelimiter)
+		if len(commaRunes) != 1 {
+			return 0, errors.Errorf("CSV delimiter must be a single character: '%s'", options.CSVDelimiter)
 		}
+		return commaRunes[0], nil
+	}
 
-		fmt.Println()
+	comma, detected := sniffColumnDelimiter(content)
+	if !detected {
+		log.Warnf("could not detect the column delimiter of %s, using %q", options.Filename, string(comma))
 	}
 
-	return result, nil
+	return comma, nil
 }
 
 func isLastLine(currentLine int, data [][]string) bool {
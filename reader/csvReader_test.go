@@ -2,6 +2,7 @@ package reader
 
 import (
 	"bufio"
+	"github.com/pkg/errors"
 	"github.com/ppxl/sagemine/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,7 +15,7 @@ import (
 const pipelineA = "Pipeline A"
 
 func Test_csvReader_Read(t *testing.T) {
-	t.Run("should return single line for german Remine CSV", func(t *testing.T) {
+	t.Run("should fail with the position of a column header which is no date", func(t *testing.T) {
 		file, _ := ioutil.TempFile(os.TempDir(), "redmineCSV-")
 		path := file.Name()
 		defer os.Remove(path)
@@ -34,30 +35,13 @@ Gesamtzeit;7,50;6,00;"";4,50;18,00
 		})
 
 		//when
-		actual, err := sut.Read()
+		_, err = sut.Read()
 
 		// then
-		require.NoError(t, err)
-		require.Equal(t, 2, actual.Entries())
-
-		expected := core.NewPipelineData()
-
-		expectedEntry, err := expected.AddPipeline(pipelineA)
-		require.NoError(t, err)
-		expectedEntry.PutWorkTime("2021-05-03", 7.50)
-		expectedEntry.PutWorkTime("2021-05-04", 6)
-		expectedEntry.PutWorkTime("2021-05-05", 0)
-		expectedEntry.PutWorkTime("2021-05-06", 4.50)
-		expectedEntry.PutWorkTime("Gesamtzeit", 18)
-
-		expectedSums, err := expected.AddPipeline("Gesamtzeit")
-		require.NoError(t, err)
-		expectedSums.PutWorkTime("2021-05-03", 7.50)
-		expectedSums.PutWorkTime("2021-05-04", 6)
-		expectedSums.PutWorkTime("2021-05-05", 0)
-		expectedSums.PutWorkTime("2021-05-06", 4.50)
-		expectedSums.PutWorkTime("Gesamtzeit", 18)
-		assert.Equal(t, expected, actual)
+		require.Error(t, err)
+		var dateError *core.DateError
+		require.True(t, errors.As(err, &dateError))
+		assert.Equal(t, core.DateError{Date: "Gesamtzeit", Source: path, Line: 1, Column: 6, Err: dateError.Err}, *dateError)
 	})
 
 	t.Run("should cut away selected columns from german Remine CSV", func(t *testing.T) {
@@ -120,6 +104,7 @@ Gesamtzeit;7,50;6,00;"";4,50;18,00
 			Filename:         path,
 			CSVDelimiter:     ";",
 			DecimalDelimiter: ",",
+			SkipColumnNames:  []string{"Gesamtzeit"},
 			SkipSummaryLine:  true,
 		})
 
@@ -129,7 +114,7 @@ Gesamtzeit;7,50;6,00;"";4,50;18,00
 		// then
 		require.NoError(t, err)
 		require.Equal(t, 1, actual.Entries())
-		require.Equal(t, actual.NamedDayRedmineValues[pipelineA].Days(), 5)
+		require.Equal(t, actual.NamedDayRedmineValues[pipelineA].Days(), 4)
 
 		expected := core.NewPipelineData()
 		expectedEntry, _ := expected.AddPipeline(pipelineA)
@@ -137,10 +122,77 @@ Gesamtzeit;7,50;6,00;"";4,50;18,00
 		expectedEntry.PutWorkTime("2021-05-04", 6)
 		expectedEntry.PutWorkTime("2021-05-05", 0)
 		expectedEntry.PutWorkTime("2021-05-06", 4.50)
-		expectedEntry.PutWorkTime("Gesamtzeit", 18)
 
 		assert.Equal(t, expected, actual)
 	})
+	t.Run("should read the given content instead of the file", func(t *testing.T) {
+		sut := newCSVReader(CSVOptions{
+			Filename: "request body",
+			Content: []byte(`Anforderungspipeline;2021-05-03;2021-05-04
+Pipeline A;7,50;6,00
+`),
+		})
+
+		//when
+		actual, err := sut.Read()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 7.5, actual.NamedDayRedmineValues[pipelineA].WorkTime("2021-05-03"))
+		assert.Equal(t, 6.0, actual.NamedDayRedmineValues[pipelineA].WorkTime("2021-05-04"))
+	})
+}
+
+func Test_csvReader_ReadWithDetection(t *testing.T) {
+	t.Run("should detect dialect of german Windows-1252 export", func(t *testing.T) {
+		file, _ := ioutil.TempFile(os.TempDir(), "redmineCSV-")
+		path := file.Name()
+		defer os.Remove(path)
+		_, err := file.Write([]byte("Anforderungspipeline;2021-05-03;2021-05-04;Gesamtzeit\nPipeline \xC4;7,50;\"\";7,50\nGesamtzeit;7,50;\"\";7,50\n"))
+		require.NoError(t, err)
+
+		sut := newCSVReader(CSVOptions{
+			Filename:        path,
+			SkipColumnNames: []string{"Gesamtzeit"},
+			SkipSummaryLine: true,
+		})
+
+		//when
+		actual, err := sut.Read()
+
+		// then
+		require.NoError(t, err)
+		expected := core.NewPipelineData()
+		expectedEntry, _ := expected.AddPipeline("Pipeline Ä")
+		expectedEntry.PutWorkTime("2021-05-03", 7.50)
+		expectedEntry.PutWorkTime("2021-05-04", 0)
+		assert.Equal(t, expected, actual)
+		assert.Equal(t, Dialect{Encoding: EncodingWindows1252, ColumnDelimiter: ";", DecimalDelimiter: ","}, sut.Dialect())
+	})
+	t.Run("should detect dialect of english UTF-8 export with BOM", func(t *testing.T) {
+		file, _ := ioutil.TempFile(os.TempDir(), "redmineCSV-")
+		path := file.Name()
+		defer os.Remove(path)
+		_, err := file.Write([]byte("\xEF\xBB\xBFProject,2021-05-03,2021-05-04\nPipeline A,7.50,1.25\n"))
+		require.NoError(t, err)
+
+		sut := newCSVReader(CSVOptions{Filename: path})
+
+		//when
+		actual, err := sut.Read()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 1.25, actual.NamedDayRedmineValues[pipelineA].WorkTime("2021-05-04"))
+		assert.Equal(t, Dialect{Encoding: EncodingUTF8, ColumnDelimiter: ",", DecimalDelimiter: "."}, sut.Dialect())
+	})
+	t.Run("should fail for missing file", func(t *testing.T) {
+		sut := newCSVReader(CSVOptions{Filename: "/does/not/exist.csv"})
+
+		_, err := sut.Read()
+
+		require.Error(t, err)
+	})
 }
 
 func Test_formatDecimal(t *testing.T) {
@@ -0,0 +1,109 @@
+package reader
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"strings"
+)
+
+// Source is a named Redmine data reader, f. i. one that reads a single file.
+type Source struct {
+	Name   string
+	Reader RedmineDataReader
+}
+
+// MergeConflict describes a pipeline that has work time on the same date in more than one source.
+type MergeConflict struct {
+	Pipeline string
+	Date     string
+	// Origins contains the work time per source in the order the sources were read.
+	Origins []Origin
+}
+
+// Origin contains the work time a single source contributed to a merged value.
+type Origin struct {
+	Source   string
+	WorkTime float64
+}
+
+func (mc MergeConflict) String() string {
+	origins := []string{}
+	sum := 0.0
+	for _, origin := range mc.Origins {
+		origins = append(origins, fmt.Sprintf("%s (%.2f)", origin.Source, origin.WorkTime))
+		sum += origin.WorkTime
+	}
+
+	return fmt.Sprintf("%s on %s appears in %s: summed up to %.2f", mc.Pipeline, mc.Date, strings.Join(origins, ", "), sum)
+}
+
+// ConflictReporter is implemented by readers which merge several sources, f. i. the reader of NewMultiReader.
+//
+// Example:
+//  if reporter, ok := redmineReader.(reader.ConflictReporter); ok {
+//    conflicts := reporter.Conflicts()
+//  }
+type ConflictReporter interface {
+	// Conflicts returns the merge conflicts of the last read, ordered by pipeline and date.
+	Conflicts() []MergeConflict
+}
+
+type multiReader struct {
+	sources   []Source
+	conflicts []MergeConflict
+}
+
+// NewMultiReader creates a reader that reads all sources and sums up their work time into a single pipeline data set.
+// The reader implements ConflictReporter.
+func NewMultiReader(sources []Source) RedmineDataReader {
+	return &multiReader{sources: sources}
+}
+
+// Read reads all sources in their order. Work time of the same pipeline and date is summed up and reported as a
+// conflict if more than one source contributed to it.
+func (mr *multiReader) Read() (*core.PipelineData, error) {
+	result := core.NewPipelineData()
+	origins := map[string]map[string][]Origin{}
+	mr.conflicts = []MergeConflict{}
+
+	for _, source := range mr.sources {
+		data, err := source.Reader.Read()
+		if err != nil {
+			return nil, errors.Wrapf(err, "error while reading from %s", source.Name)
+		}
+
+		for _, pipelineName := range data.SortedKeys() {
+			target, ok := result.NamedDayRedmineValues[core.PipelineName(pipelineName)]
+			if !ok {
+				target, err = result.AddPipeline(pipelineName)
+				if err != nil {
+					return nil, errors.Wrapf(err, "error while merging data from %s", source.Name)
+				}
+				origins[pipelineName] = map[string][]Origin{}
+			}
+
+			for date, workTime := range data.NamedDayRedmineValues[core.PipelineName(pipelineName)].WorkPerDay {
+				target.PutWorkTime(date, workTime)
+				if workTime != 0 {
+					origins[pipelineName][date] = append(origins[pipelineName][date], Origin{Source: source.Name, WorkTime: workTime})
+				}
+			}
+		}
+	}
+
+	for _, pipelineName := range result.SortedKeys() {
+		for _, date := range result.NamedDayRedmineValues[core.PipelineName(pipelineName)].SortedKeys() {
+			if len(origins[pipelineName][date]) > 1 {
+				mr.conflicts = append(mr.conflicts, MergeConflict{Pipeline: pipelineName, Date: date, Origins: origins[pipelineName][date]})
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Conflicts returns the merge conflicts of the last read, ordered by pipeline and date.
+func (mr *multiReader) Conflicts() []MergeConflict {
+	return mr.conflicts
+}
@@ -0,0 +1,66 @@
+package reader
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type staticReader struct {
+	data *core.PipelineData
+	err  error
+}
+
+func (sr *staticReader) Read() (*core.PipelineData, error) {
+	return sr.data, sr.err
+}
+
+func Test_multiReader_Read(t *testing.T) {
+	t.Run("should merge sources and report conflicts", func(t *testing.T) {
+		first := core.NewPipelineData()
+		firstA, _ := first.AddPipeline(pipelineA)
+		firstA.PutWorkTime("2021-05-03", 2)
+		firstA.PutWorkTime("2021-05-04", 0)
+		second := core.NewPipelineData()
+		secondA, _ := second.AddPipeline(pipelineA)
+		secondA.PutWorkTime("2021-05-03", 1.5)
+		secondA.PutWorkTime("2021-05-04", 3)
+		secondB, _ := second.AddPipeline("Pipeline B")
+		secondB.PutWorkTime("2021-05-05", 1)
+
+		sut := NewMultiReader([]Source{
+			{Name: "a.csv", Reader: &staticReader{data: first}},
+			{Name: "b.csv", Reader: &staticReader{data: second}},
+		})
+
+		// when
+		actual, err := sut.Read()
+
+		// then
+		require.NoError(t, err)
+		expected := core.NewPipelineData()
+		expectedA, _ := expected.AddPipeline(pipelineA)
+		expectedA.PutWorkTime("2021-05-03", 3.5)
+		expectedA.PutWorkTime("2021-05-04", 3)
+		expectedB, _ := expected.AddPipeline("Pipeline B")
+		expectedB.PutWorkTime("2021-05-05", 1)
+		assert.Equal(t, expected, actual)
+
+		require.Implements(t, (*ConflictReporter)(nil), sut)
+		conflicts := sut.(ConflictReporter).Conflicts()
+		require.Len(t, conflicts, 1)
+		conflict := conflicts[0]
+		assert.Equal(t, MergeConflict{Pipeline: pipelineA, Date: "2021-05-03", Origins: []Origin{{"a.csv", 2}, {"b.csv", 1.5}}}, conflict)
+		assert.Equal(t, "Pipeline A on 2021-05-03 appears in a.csv (2.00), b.csv (1.50): summed up to 3.50", conflict.String())
+	})
+	t.Run("should name failing source", func(t *testing.T) {
+		sut := NewMultiReader([]Source{{Name: "broken.csv", Reader: &staticReader{err: errors.New("oops")}}})
+
+		_, err := sut.Read()
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "broken.csv")
+	})
+}
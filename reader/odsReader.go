@@ -0,0 +1,141 @@
+package reader
+
+import (
+	"archive/zip"
+	"github.com/ppxl/sagemine/core"
+	"strings"
+)
+
+const (
+	odsContent        = "content.xml"
+	odsValueTypeFloat = "float"
+	odsValueTypeDate  = "date"
+)
+
+type odsContentXML struct {
+	Tables []odsTableXML `xml:"body>spreadsheet>table"`
+}
+
+type odsTableXML struct {
+	Name       string      `xml:"urn:oasis:names:tc:opendocument:xmlns:table:1.0 name,attr"`
+	HeaderRows []odsRowXML `xml:"table-header-rows>table-row"`
+	Rows       []odsRowXML `xml:"table-row"`
+}
+
+type odsRowXML struct {
+	Repeated int          `xml:"urn:oasis:names:tc:opendocument:xmlns:table:1.0 number-rows-repeated,attr"`
+	Cells    []odsCellXML `xml:"table-cell"`
+}
+
+type odsCellXML struct {
+	Repeated   int               `xml:"urn:oasis:names:tc:opendocument:xmlns:table:1.0 number-columns-repeated,attr"`
+	ValueType  string            `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 value-type,attr"`
+	Value      string            `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 value,attr"`
+	DateValue  string            `xml:"urn:oasis:names:tc:opendocument:xmlns:office:1.0 date-value,attr"`
+	Paragraphs []odsParagraphXML `xml:"p"`
+}
+
+type odsParagraphXML struct {
+	Text  string   `xml:",chardata"`
+	Spans []string `xml:"span"`
+}
+
+func (cell odsCellXML) String() string {
+	switch cell.ValueType {
+	case odsValueTypeFloat:
+		return cell.Value
+	case odsValueTypeDate:
+		if len(cell.DateValue) >= len(core.DateLayout) {
+			return cell.DateValue[:len(core.DateLayout)]
+		}
+		return cell.DateValue
+	}
+
+	paragraphs := []string{}
+	for _, paragraph := range cell.Paragraphs {
+		paragraphs = append(paragraphs, paragraph.Text+strings.Join(paragraph.Spans, ""))
+	}
+
+	return strings.Join(paragraphs, "\n")
+}
+
+type odsReader struct {
+	options SpreadsheetOptions
+}
+
+func newODSReader(options SpreadsheetOptions) *odsReader {
+	return &odsReader{options: options}
+}
+
+// Read reads the selected sheet of an OpenDocument spreadsheet.
+func (or *odsReader) Read() (*core.PipelineData, error) {
+	return readSpreadsheet(or.options, readODSSheets)
+}
+
+func readODSSheets(archive *zip.Reader) ([]sheet, error) {
+	content := odsContentXML{}
+	err := readZippedXML(archive, odsContent, &content)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []sheet{}
+	for _, table := range content.Tables {
+		rows := expandODSRows(append(table.HeaderRows, table.Rows...))
+		result = append(result, sheet{name: table.Name, rows: rows})
+	}
+
+	return result, nil
+}
+
+// expandODSRows resolves repeated rows. Spreadsheet applications fill up sheets with huge amounts of repeated empty
+// rows, so empty rows are left out.
+func expandODSRows(odsRows []odsRowXML) [][]string {
+	result := [][]string{}
+
+	for _, odsRow := range odsRows {
+		row := expandODSCells(odsRow.Cells)
+		if isEmptyRow(row) {
+			continue
+		}
+
+		for i := 0; i < repetitions(odsRow.Repeated); i++ {
+			result = append(result, row)
+		}
+	}
+
+	return result
+}
+
+// expandODSCells resolves repeated cells. Repeated empty cells are only expanded if content follows them.
+func expandODSCells(odsCells []odsCellXML) []string {
+	result := []string{}
+	pendingEmptyCells := 0
+
+	for _, odsCell := range odsCells {
+		value := odsCell.String()
+		repeated := repetitions(odsCell.Repeated)
+
+		if value == "" {
+			pendingEmptyCells += repeated
+			continue
+		}
+
+		for ; pendingEmptyCells > 0; pendingEmptyCells-- {
+			result = append(result, "")
+		}
+		for i := 0; i < repeated; i++ {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+func repetitions(repeated int) int {
+	if repeated < 1 {
+		return 1
+	}
+
+	return repeated
+}
@@ -0,0 +1,69 @@
+package reader
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+const odsContentXMLContent = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+    xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+    xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+  <office:body>
+    <office:spreadsheet>
+      <table:table table:name="Timelog">
+        <table:table-row>
+          <table:table-cell office:value-type="string"><text:p>Anforderungspipeline</text:p></table:table-cell>
+          <table:table-cell office:value-type="date" office:date-value="2021-05-03"><text:p>03.05.21</text:p></table:table-cell>
+          <table:table-cell office:value-type="date" office:date-value="2021-05-04T00:00:00"><text:p>04.05.21</text:p></table:table-cell>
+          <table:table-cell office:value-type="string"><text:p>Gesamtzeit</text:p></table:table-cell>
+          <table:table-cell table:number-columns-repeated="1020"/>
+        </table:table-row>
+        <table:table-row>
+          <table:table-cell office:value-type="string"><text:p>Pipeline <text:span>A</text:span></text:p></table:table-cell>
+          <table:table-cell office:value-type="float" office:value="7.5"><text:p>7,50</text:p></table:table-cell>
+          <table:table-cell/>
+          <table:table-cell office:value-type="float" office:value="7.5"><text:p>7,50</text:p></table:table-cell>
+        </table:table-row>
+        <table:table-row table:number-rows-repeated="2">
+          <table:table-cell office:value-type="string"><text:p>Pipeline B</text:p></table:table-cell>
+          <table:table-cell office:value-type="float" office:value="1"><text:p>1,00</text:p></table:table-cell>
+          <table:table-cell office:value-type="float" office:value="0.5" table:number-columns-repeated="2"><text:p>0,50</text:p></table:table-cell>
+        </table:table-row>
+        <table:table-row table:number-rows-repeated="1048570">
+          <table:table-cell table:number-columns-repeated="1024"/>
+        </table:table-row>
+      </table:table>
+    </office:spreadsheet>
+  </office:body>
+</office:document-content>`
+
+func Test_odsReader_Read(t *testing.T) {
+	path := writeZipFile(t, map[string]string{"content.xml": odsContentXMLContent})
+	defer os.Remove(path)
+
+	t.Run("should read sheet like a CSV export and expand repeated cells", func(t *testing.T) {
+		sut := newODSReader(SpreadsheetOptions{
+			Filename:        path,
+			Sheet:           "1",
+			SkipColumnNames: []string{"Gesamtzeit"},
+		})
+
+		// when
+		actual, err := sut.Read()
+
+		// then
+		require.NoError(t, err)
+		expected := core.NewPipelineData()
+		expectedA, _ := expected.AddPipeline(pipelineA)
+		expectedA.PutWorkTime("2021-05-03", 7.5)
+		expectedA.PutWorkTime("2021-05-04", 0)
+		expectedB, _ := expected.AddPipeline("Pipeline B")
+		expectedB.PutWorkTime("2021-05-03", 1)
+		expectedB.PutWorkTime("2021-05-04", 0.5)
+		assert.Equal(t, expected, actual)
+	})
+}
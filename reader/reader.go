@@ -0,0 +1,163 @@
+package reader
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"github.com/ppxl/sagemine/credentials"
+	"github.com/ppxl/sagemine/logging"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	CSV = iota
+	RestAPI
+	XLSX
+	ODS
+	Sage
+)
+
+// Input format names which select a reader type.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+	FormatODS  = "ods"
+	// FormatAPI selects the Redmine REST API reader which reads no files.
+	FormatAPI = "api"
+)
+
+var log = logging.Logger()
+
+// APIOptions contain the options for reading time entries from the Redmine REST API.
+type APIOptions struct {
+	RedmineURL string
+	// Credentials authenticate by API key or by user and password. Secrets are redacted in logs and errors.
+	Credentials credentials.Credentials
+	// UserID narrows the time entries to a single user, f. i. "me". Empty queries the time entries of all users.
+	UserID string
+	// DateRange narrows the queried time entries. An unbounded range queries all time entries.
+	DateRange core.DateRange
+}
+
+// SpreadsheetOptions contain the options for reading Excel (.xlsx) and OpenDocument (.ods) spreadsheets.
+type SpreadsheetOptions struct {
+	Filename string
+	// Sheet selects the sheet by its name or by its 1-based position. Empty selects the first sheet.
+	Sheet string
+	// DecimalDelimiter is only used for numbers which the spreadsheet stores as text.
+	DecimalDelimiter string
+	SkipColumnNames  []string
+	SkipRowNames     []string
+	SkipSummaryLine  bool
+	DateLayouts      []string
+}
+
+type Options struct {
+	Type               int
+	CSVOptions         CSVOptions
+	APIOptions         APIOptions
+	SpreadsheetOptions SpreadsheetOptions
+}
+
+type RedmineDataReader interface {
+	Read() (*core.PipelineData, error)
+}
+
+// Factory creates a reader from the options. File readers take the file name from the options of their format.
+type Factory func(options Options) (RedmineDataReader, error)
+
+var registry = map[string]Factory{
+	FormatCSV:  func(options Options) (RedmineDataReader, error) { return newCSVReader(options.CSVOptions), nil },
+	FormatXLSX: func(options Options) (RedmineDataReader, error) { return newXLSXReader(options.SpreadsheetOptions), nil },
+	FormatODS:  func(options Options) (RedmineDataReader, error) { return newODSReader(options.SpreadsheetOptions), nil },
+	FormatAPI:  func(options Options) (RedmineDataReader, error) { return newAPIReader(options.APIOptions), nil },
+	FormatSage: func(options Options) (RedmineDataReader, error) { return newSageReader(options.CSVOptions), nil },
+}
+
+// typeNames maps the reader types to the names of their registered readers.
+var typeNames = map[int]string{CSV: FormatCSV, RestAPI: FormatAPI, XLSX: FormatXLSX, ODS: FormatODS, Sage: FormatSage}
+
+// ErrUnsupportedReader is the cause of all errors about unknown reader types and names. Check for it with errors.Is.
+var ErrUnsupportedReader = errors.New("unsupported reader")
+
+// UnsupportedReaderError describes a reader which is not registered.
+type UnsupportedReaderError struct {
+	// Name contains the requested reader name. It is empty if an unknown Type was requested.
+	Name string
+	Type int
+}
+
+func (ure *UnsupportedReaderError) Error() string {
+	if ure.Name == "" {
+		return fmt.Sprintf("unsupported reader type %d", ure.Type)
+	}
+
+	return fmt.Sprintf("unsupported reader '%s' (supported readers: %s)", ure.Name, strings.Join(Names(), ", "))
+}
+
+// Is matches ErrUnsupportedReader.
+func (ure *UnsupportedReaderError) Is(target error) bool {
+	return target == ErrUnsupportedReader
+}
+
+// New creates the reader of the options' type. Use Create to select a reader by name.
+func New(options Options) (RedmineDataReader, error) {
+	name, ok := typeNames[options.Type]
+	if !ok {
+		return nil, &UnsupportedReaderError{Type: options.Type}
+	}
+
+	return Create(name, options)
+}
+
+// Register adds a reader which is selected by its name or by files with the name as extension. Third-party readers
+// register themselves in an init function of their package.
+//
+// Example:
+//  err := reader.Register("json", func(options reader.Options) (reader.RedmineDataReader, error) { ... })
+func Register(name string, factory Factory) error {
+	if name == "" || factory == nil {
+		return errors.New("reader registration needs a name and a factory")
+	}
+	name = strings.ToLower(name)
+	if _, ok := registry[name]; ok {
+		return errors.Errorf("reader '%s' is already registered", name)
+	}
+
+	registry[name] = factory
+	return nil
+}
+
+// Create returns the registered reader of the given name.
+func Create(name string, options Options) (RedmineDataReader, error) {
+	factory, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return nil, &UnsupportedReaderError{Name: name}
+	}
+
+	return factory(options)
+}
+
+// Names returns the names of all registered readers in lexical order.
+func Names() []string {
+	result := make([]string, 0, len(registry))
+	for name := range registry {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// NameByFilename returns the name of the registered reader which matches the file extension. Unknown extensions are
+// read as CSV.
+func NameByFilename(filename string) string {
+	extension := strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+	if _, ok := registry[extension]; !ok || extension == FormatAPI {
+		return FormatCSV
+	}
+
+	return extension
+}
@@ -0,0 +1,61 @@
+package reader
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRegister(t *testing.T) {
+	t.Run("should create a registered reader by name and extension", func(t *testing.T) {
+		defer delete(registry, "json")
+		err := Register("JSON", func(options Options) (RedmineDataReader, error) { return &staticReader{}, nil })
+		require.NoError(t, err)
+
+		// when
+		actual, err := Create(NameByFilename("timelog.json"), Options{})
+
+		// then
+		require.NoError(t, err)
+		assert.IsType(t, &staticReader{}, actual)
+		assert.Contains(t, Names(), "json")
+	})
+	t.Run("should fail for names which are already registered", func(t *testing.T) {
+		// when
+		err := Register(FormatCSV, func(options Options) (RedmineDataReader, error) { return &staticReader{}, nil })
+
+		// then
+		require.Error(t, err)
+	})
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("should fail for unknown readers", func(t *testing.T) {
+		// when
+		_, err := Create("pdf", Options{})
+
+		// then
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrUnsupportedReader))
+		assert.Contains(t, err.Error(), "unsupported reader 'pdf' (supported readers: api, csv, ods, sage, xlsx)")
+	})
+}
+
+func TestNameByFilename(t *testing.T) {
+	assert.Equal(t, FormatXLSX, NameByFilename("timelog.XLSX"))
+	assert.Equal(t, FormatCSV, NameByFilename("timelog.txt"))
+	assert.Equal(t, FormatCSV, NameByFilename("timelog.api"))
+}
+
+func TestNew(t *testing.T) {
+	t.Run("should fail for unknown types", func(t *testing.T) {
+		// when
+		_, err := New(Options{Type: 42})
+
+		// then
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrUnsupportedReader))
+		assert.Equal(t, "unsupported reader type 42", err.Error())
+	})
+}
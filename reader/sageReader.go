@@ -0,0 +1,215 @@
+package reader
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// FormatSage selects the reader of time slots which were entered into Sage, f. i. the CSV or JSON output of RedSage.
+const FormatSage = "sage"
+
+// sageColumns contains the accepted column headers of a Sage time slot export in lower case.
+var sageColumns = map[string][]string{
+	"pipeline": {"pipeline", "project", "projekt"},
+	"date":     {"date", "datum"},
+	"start":    {"start", "beginn", "von", "from"},
+	"end":      {"end", "ende", "bis", "to"},
+}
+
+// sageDocument contains the parts of the JSON output of RedSage which describe the time slots.
+type sageDocument struct {
+	Pipelines []struct {
+		Name string `json:"name"`
+		Days []struct {
+			Date  string `json:"date"`
+			Slots []struct {
+				Start string `json:"start"`
+				End   string `json:"end"`
+			} `json:"slots"`
+		} `json:"days"`
+	} `json:"pipelines"`
+}
+
+type sageReader struct {
+	options CSVOptions
+}
+
+func newSageReader(options CSVOptions) *sageReader {
+	return &sageReader{options: options}
+}
+
+// Read converts time slots back into the hours per pipeline and day. JSON files are read like the JSON output of
+// RedSage, all other files as CSV with the columns pipeline, date, start and end in any order. Other columns are
+// ignored.
+func (sr *sageReader) Read() (*core.PipelineData, error) {
+	raw, err := ioutil.ReadFile(sr.options.Filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read Sage file %s", sr.options.Filename)
+	}
+
+	content, _, err := decodeText(raw, sr.options.Encoding)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not decode Sage file %s", sr.options.Filename)
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(content), "{") {
+		return sr.readJSON(content)
+	}
+
+	return sr.readCSV(content)
+}
+
+func (sr *sageReader) readJSON(content string) (*core.PipelineData, error) {
+	document := sageDocument{}
+	err := json.Unmarshal([]byte(content), &document)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse Sage file %s", sr.options.Filename)
+	}
+
+	result := core.NewPipelineData()
+	for _, pipeline := range document.Pipelines {
+		workPerDay, err := addPipeline(result, pipeline.Name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read Sage file %s", sr.options.Filename)
+		}
+
+		for _, day := range pipeline.Days {
+			date, err := sr.normalizeDate(day.Date)
+			if err != nil {
+				return nil, err
+			}
+			workPerDay.PutWorkTime(date, 0)
+
+			for _, slot := range day.Slots {
+				hours, err := slotHours(core.TimeSlot{Start: slot.Start, End: slot.End})
+				if err != nil {
+					return nil, errors.Wrapf(err, "invalid time slot of pipeline %s on %s in %s", pipeline.Name, date, sr.options.Filename)
+				}
+				workPerDay.PutWorkTime(date, hours)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (sr *sageReader) readCSV(content string) (*core.PipelineData, error) {
+	comma, err := columnDelimiter(sr.options, content)
+	if err != nil {
+		return nil, err
+	}
+
+	r := csv.NewReader(strings.NewReader(content))
+	r.Comma = comma
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+
+	data, err := r.ReadAll()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse Sage file %s", sr.options.Filename)
+	}
+	if len(data) == 0 {
+		return core.NewPipelineData(), nil
+	}
+
+	columns, err := sr.findColumns(data[0])
+	if err != nil {
+		return nil, err
+	}
+
+	result := core.NewPipelineData()
+	for index, line := range data[1:] {
+		lineNumber := index + 2
+		if len(line) <= columns["end"] || len(line) <= columns["start"] || len(line) <= columns["date"] || len(line) <= columns["pipeline"] {
+			return nil, errors.Errorf("line %d of Sage file %s has too few columns", lineNumber, sr.options.Filename)
+		}
+
+		workPerDay, err := addPipeline(result, line[columns["pipeline"]])
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read line %d of Sage file %s", lineNumber, sr.options.Filename)
+		}
+
+		date, err := sr.normalizeDate(line[columns["date"]])
+		if err != nil {
+			dateError := &core.DateError{}
+			if errors.As(err, &dateError) {
+				dateError.Line = lineNumber
+				dateError.Column = columns["date"] + 1
+			}
+			return nil, err
+		}
+
+		hours, err := slotHours(core.TimeSlot{Start: strings.TrimSpace(line[columns["start"]]), End: strings.TrimSpace(line[columns["end"]])})
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid time slot in line %d of Sage file %s", lineNumber, sr.options.Filename)
+		}
+		workPerDay.PutWorkTime(date, hours)
+	}
+
+	return result, nil
+}
+
+// findColumns returns the positions of the pipeline, date, start and end columns.
+func (sr *sageReader) findColumns(headers []string) (map[string]int, error) {
+	result := map[string]int{}
+	for index, header := range headers {
+		for column, names := range sageColumns {
+			if _, found := result[column]; !found && core.ContainsString(names, strings.ToLower(strings.TrimSpace(header))) {
+				result[column] = index
+			}
+		}
+	}
+
+	for _, column := range []string{"pipeline", "date", "start", "end"} {
+		if _, found := result[column]; !found {
+			return nil, errors.Errorf("Sage file %s misses a %s column (expected one of: %s)",
+				sr.options.Filename, column, strings.Join(sageColumns[column], ", "))
+		}
+	}
+
+	return result, nil
+}
+
+// normalizeDate converts dates which match one of the configured layouts into the format YYYY-MM-DD.
+func (sr *sageReader) normalizeDate(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	parsed, err := time.Parse(core.DateLayout, value)
+	if err == nil {
+		return parsed.Format(core.DateLayout), nil
+	}
+	for _, layout := range sr.options.DateLayouts {
+		if parsed, layoutErr := time.Parse(layout, value); layoutErr == nil {
+			return parsed.Format(core.DateLayout), nil
+		}
+	}
+
+	return "", &core.DateError{Date: value, Source: sr.options.Filename, Err: err}
+}
+
+// addPipeline returns the pipeline of the given name and adds it if it does not exist yet.
+func addPipeline(data *core.PipelineData, name string) (*core.RedmineWorkPerDay, error) {
+	name = strings.TrimSpace(name)
+	if workPerDay, ok := data.NamedDayRedmineValues[core.PipelineName(name)]; ok {
+		return workPerDay, nil
+	}
+
+	return data.AddPipeline(name)
+}
+
+// slotHours returns the duration of a time slot in hours. Empty time slots last zero hours.
+func slotHours(slot core.TimeSlot) (float64, error) {
+	duration, err := slot.Duration()
+	if err != nil {
+		return 0, err
+	}
+	if duration < 0 {
+		return 0, errors.Errorf("time slot %s ends before it starts", slot.String())
+	}
+
+	return duration.Hours(), nil
+}
@@ -0,0 +1,102 @@
+package reader
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_sageReader_Read(t *testing.T) {
+	dir, _ := ioutil.TempDir(os.TempDir(), "sage-")
+	defer os.RemoveAll(dir)
+
+	t.Run("should sum up the time slots of the RedSage CSV output", func(t *testing.T) {
+		path := filepath.Join(dir, "sage.csv")
+		_ = ioutil.WriteFile(path, []byte(`Pipeline;Date;Start;End;Hours;Note
+Pipeline A;2021-05-03;08:00;12:00;4;
+Pipeline A;2021-05-03;13:00;13:30;0.5;
+Pipeline B;2021-05-08;08:00;08:45;0.75;Saturday
+`), 0600)
+
+		// when
+		actual, err := Create(FormatSage, Options{CSVOptions: CSVOptions{Filename: path}})
+		require.NoError(t, err)
+		data, err := actual.Read()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 4.5, data.NamedDayRedmineValues[pipelineA].WorkTime("2021-05-03"))
+		assert.Equal(t, 0.75, data.NamedDayRedmineValues["Pipeline B"].WorkTime("2021-05-08"))
+	})
+	t.Run("should find columns of Sage exports by their names", func(t *testing.T) {
+		path := filepath.Join(dir, "export.csv")
+		_ = ioutil.WriteFile(path, []byte(`Datum;Von;Bis;Projekt
+03.05.2021;08:00;09:30;Pipeline A
+`), 0600)
+		sut := newSageReader(CSVOptions{Filename: path, DateLayouts: []string{"02.01.2006"}})
+
+		// when
+		actual, err := sut.Read()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 1.5, actual.NamedDayRedmineValues[pipelineA].WorkTime("2021-05-03"))
+	})
+	t.Run("should read the RedSage JSON output", func(t *testing.T) {
+		path := filepath.Join(dir, "sage.json")
+		_ = ioutil.WriteFile(path, []byte(`{"pipelines": [{"name": "Pipeline A", "days": [
+  {"date": "2021-05-03", "hours": 4.5, "slots": [{"start": "08:00", "end": "12:00"}, {"start": "13:00", "end": "13:30"}]},
+  {"date": "2021-05-04", "hours": 0, "slots": []}
+]}]}`), 0600)
+		sut := newSageReader(CSVOptions{Filename: path})
+
+		// when
+		actual, err := sut.Read()
+
+		// then
+		require.NoError(t, err)
+		expected := map[string]float64{"2021-05-03": 4.5, "2021-05-04": 0}
+		assert.Equal(t, expected, actual.NamedDayRedmineValues[pipelineA].WorkPerDay)
+	})
+	t.Run("should fail for missing columns", func(t *testing.T) {
+		path := filepath.Join(dir, "missing.csv")
+		_ = ioutil.WriteFile(path, []byte("Pipeline;Date;Start\nPipeline A;2021-05-03;08:00\n"), 0600)
+
+		// when
+		_, err := newSageReader(CSVOptions{Filename: path}).Read()
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "misses a end column")
+	})
+	t.Run("should fail for invalid dates with their position", func(t *testing.T) {
+		path := filepath.Join(dir, "date.csv")
+		_ = ioutil.WriteFile(path, []byte("Pipeline;Date;Start;End\nPipeline A;3rd of May;08:00;09:00\n"), 0600)
+
+		// when
+		_, err := newSageReader(CSVOptions{Filename: path}).Read()
+
+		// then
+		require.Error(t, err)
+		var dateError *core.DateError
+		require.True(t, errors.As(err, &dateError))
+		assert.Equal(t, 2, dateError.Line)
+		assert.Equal(t, 2, dateError.Column)
+	})
+	t.Run("should fail for time slots which end before they start", func(t *testing.T) {
+		path := filepath.Join(dir, "slot.csv")
+		_ = ioutil.WriteFile(path, []byte("Pipeline;Date;Start;End\nPipeline A;2021-05-03;10:00;09:00\n"), 0600)
+
+		// when
+		_, err := newSageReader(CSVOptions{Filename: path}).Read()
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "time slot 10:00 - 09:00 ends before it starts")
+	})
+}
@@ -0,0 +1,154 @@
+package reader
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// spreadsheetEpoch is the origin of serial date numbers in spreadsheets (respecting the Lotus 1-2-3 leap year bug).
+var spreadsheetEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// plausible serial date numbers lie between 1954 and 2118
+const (
+	minSerialDate = 20000
+	maxSerialDate = 80000
+)
+
+var errMissingZippedFile = errors.New("file is missing in archive")
+
+// sheet contains the cells of a single spreadsheet sheet.
+type sheet struct {
+	name string
+	rows [][]string
+}
+
+// selectSheet returns the sheet with the given name or 1-based position. An empty selection returns the first sheet.
+func selectSheet(sheets []sheet, selection string, filename string) (sheet, error) {
+	if len(sheets) == 0 {
+		return sheet{}, errors.Errorf("found no sheets in %s", filename)
+	}
+	if selection == "" {
+		return sheets[0], nil
+	}
+
+	names := []string{}
+	for _, candidate := range sheets {
+		if candidate.name == selection {
+			return candidate, nil
+		}
+		names = append(names, candidate.name)
+	}
+
+	position, err := strconv.Atoi(selection)
+	if err == nil && position >= 1 && position <= len(sheets) {
+		return sheets[position-1], nil
+	}
+
+	return sheet{}, errors.Errorf("found no sheet '%s' in %s (available sheets: %s)", selection, filename, strings.Join(names, ", "))
+}
+
+// normalizeRows removes empty rows and pads all rows to the length of the header row so that the rows can be parsed
+// like a CSV file.
+func normalizeRows(rows [][]string) [][]string {
+	result := [][]string{}
+	width := 0
+
+	for _, row := range rows {
+		if isEmptyRow(row) {
+			continue
+		}
+
+		if len(result) == 0 {
+			width = len(row)
+			for width > 0 && row[width-1] == "" {
+				width--
+			}
+		}
+
+		normalized := make([]string, width)
+		copy(normalized, row)
+		result = append(result, normalized)
+	}
+
+	return result
+}
+
+func isEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// serialToDate converts a spreadsheet serial date number into a date of the format YYYY-MM-DD. Values which do not
+// look like serial dates are returned as they are.
+func serialToDate(value string) string {
+	serial, err := strconv.ParseFloat(value, 64)
+	if err != nil || serial < minSerialDate || serial > maxSerialDate {
+		return value
+	}
+
+	return spreadsheetEpoch.AddDate(0, 0, int(serial)).Format(core.DateLayout)
+}
+
+func readZippedXML(archive *zip.Reader, name string, target interface{}) error {
+	for _, file := range archive.File {
+		if file.Name != name {
+			continue
+		}
+
+		content, err := file.Open()
+		if err != nil {
+			return errors.Wrapf(err, "could not open %s", name)
+		}
+		defer content.Close()
+
+		err = xml.NewDecoder(content).Decode(target)
+		if err != nil {
+			return errors.Wrapf(err, "could not parse %s", name)
+		}
+		return nil
+	}
+
+	return errors.Wrapf(errMissingZippedFile, "could not find %s", name)
+}
+
+func readSpreadsheet(options SpreadsheetOptions, readSheets func(archive *zip.Reader) ([]sheet, error)) (*core.PipelineData, error) {
+	archive, err := zip.OpenReader(options.Filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open spreadsheet %s", options.Filename)
+	}
+	defer archive.Close()
+
+	sheets, err := readSheets(&archive.Reader)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read spreadsheet %s", options.Filename)
+	}
+
+	selected, err := selectSheet(sheets, options.Sheet, options.Filename)
+	if err != nil {
+		return nil, err
+	}
+
+	decimalDelimiter := options.DecimalDelimiter
+	if decimalDelimiter == "" {
+		decimalDelimiter = "."
+	}
+
+	return parseTable(normalizeRows(selected.rows), tableOptions{
+		Source:           options.Filename,
+		DecimalDelimiter: decimalDelimiter,
+		SkipColumnNames:  options.SkipColumnNames,
+		SkipRowNames:     options.SkipRowNames,
+		SkipSummaryLine:  options.SkipSummaryLine,
+		DateLayouts:      options.DateLayouts,
+	})
+}
@@ -0,0 +1,82 @@
+package reader
+
+import (
+	"archive/zip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// writeZipFile creates a temporary zip archive with the given file contents and returns its path.
+func writeZipFile(t *testing.T, files map[string]string) string {
+	file, err := ioutil.TempFile(os.TempDir(), "redmineSpreadsheet-")
+	require.NoError(t, err)
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	for name, content := range files {
+		writer, err := archive.Create(name)
+		require.NoError(t, err)
+		_, err = writer.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+
+	return file.Name()
+}
+
+func Test_selectSheet(t *testing.T) {
+	sheets := []sheet{{name: "Week 18"}, {name: "Week 19"}}
+
+	t.Run("should select first sheet by default", func(t *testing.T) {
+		actual, err := selectSheet(sheets, "", "test.xlsx")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Week 18", actual.name)
+	})
+	t.Run("should select sheet by name", func(t *testing.T) {
+		actual, err := selectSheet(sheets, "Week 19", "test.xlsx")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Week 19", actual.name)
+	})
+	t.Run("should select sheet by position", func(t *testing.T) {
+		actual, err := selectSheet(sheets, "2", "test.xlsx")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Week 19", actual.name)
+	})
+	t.Run("should list available sheets for unknown sheet", func(t *testing.T) {
+		_, err := selectSheet(sheets, "Week 20", "test.xlsx")
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Week 18, Week 19")
+	})
+}
+
+func Test_normalizeRows(t *testing.T) {
+	rows := [][]string{
+		{"Pipeline", "2021-05-03", "2021-05-04", ""},
+		{},
+		{"Pipeline A", "1"},
+		{"", ""},
+		{"Pipeline B", "1", "2", "3"},
+	}
+
+	actual := normalizeRows(rows)
+
+	expected := [][]string{
+		{"Pipeline", "2021-05-03", "2021-05-04"},
+		{"Pipeline A", "1", ""},
+		{"Pipeline B", "1", "2"},
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func Test_serialToDate(t *testing.T) {
+	assert.Equal(t, "2021-05-03", serialToDate("44319"))
+	assert.Equal(t, "7.5", serialToDate("7.5"))
+	assert.Equal(t, "Gesamtzeit", serialToDate("Gesamtzeit"))
+}
@@ -0,0 +1,120 @@
+package reader
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tableOptions contain the options which all tabular Redmine exports have in common.
+type tableOptions struct {
+	// Source names the table in errors, f. i. the file name.
+	Source           string
+	DecimalDelimiter string
+	SkipColumnNames  []string
+	SkipRowNames     []string
+	SkipSummaryLine  bool
+	DateLayouts      []string
+}
+
+// parseTable converts rows of cells into pipeline data. The first row contains the dates, the first column contains
+// the pipeline names. Errors contain the 1-based line and column of the cell.
+func parseTable(data [][]string, options tableOptions) (*core.PipelineData, error) {
+	result := core.NewPipelineData()
+	columnHeaders := []string{}
+	columnsToSkip := []int{}
+	var err error
+
+	for currentLine, line := range data {
+		var pipeline *core.RedmineWorkPerDay
+
+		if currentLine == 0 {
+			columnsToSkip = buildSkipColumns(line, options.SkipColumnNames)
+			columnHeaders = normalizeDateHeaders(line, options.DateLayouts)
+			err = checkDateHeaders(columnHeaders, columnsToSkip, options.Source)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if options.SkipSummaryLine && isLastLine(currentLine, data) {
+			break
+		}
+
+		if len(line) > 0 && core.ContainsString(options.SkipRowNames, line[0]) {
+			continue
+		}
+
+		log.Tracef("reading row %d: %v", currentLine, line)
+		for currentColumn, cell := range line {
+			if currentColumn == 0 {
+				pipeline, err = result.AddPipeline(cell)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to read line %d: error while adding pipeline %s", currentLine+1, cell)
+				}
+				continue
+			}
+
+			if skipColumn(currentColumn, columnsToSkip) {
+				continue
+			}
+
+			if currentColumn >= len(columnHeaders) {
+				return nil, errors.Errorf("found value '%s' without column header (line %d, column %d)", cell, currentLine+1, currentColumn+1)
+			}
+
+			currentDay := columnHeaders[currentColumn]
+
+			workTimeRaw := formatDecimal(cell, options.DecimalDelimiter)
+
+			workTime, err := strconv.ParseFloat(workTimeRaw, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not cast value '%s' to float (line %d, column %d)", cell, currentLine+1, currentColumn+1)
+			}
+
+			pipeline.PutWorkTime(currentDay, workTime)
+		}
+	}
+
+	return result, nil
+}
+
+// normalizeDateHeaders converts date column headers which match one of the layouts into the format YYYY-MM-DD.
+// Other headers are kept as they are.
+func normalizeDateHeaders(headers []string, layouts []string) []string {
+	result := make([]string, len(headers))
+	for i, header := range headers {
+		result[i] = header
+		if i == 0 {
+			continue
+		}
+
+		for _, layout := range layouts {
+			parsed, err := time.Parse(layout, strings.TrimSpace(header))
+			if err == nil {
+				result[i] = parsed.Format(core.DateLayout)
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+// checkDateHeaders returns a DateError with the position of the first column header which is no date because it
+// cannot be crunched. Skipped columns are not checked.
+func checkDateHeaders(headers []string, columnsToSkip []int, source string) error {
+	for index, header := range headers {
+		if index == 0 || skipColumn(index, columnsToSkip) {
+			continue
+		}
+		if _, err := time.Parse(core.DateLayout, header); err != nil {
+			return &core.DateError{Date: header, Source: source, Line: 1, Column: index + 1, Err: err}
+		}
+	}
+
+	return nil
+}
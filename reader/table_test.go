@@ -0,0 +1,41 @@
+package reader
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_parseTable(t *testing.T) {
+	t.Run("should skip summary row by name and normalize date headers", func(t *testing.T) {
+		data := [][]string{
+			{"Anforderungspipeline", "03.05.2021", "2021-05-04", "Gesamtzeit"},
+			{"Gesamtzeit", "7,50", "1,00", "8,50"},
+			{pipelineA, "7,50", "1,00", "8,50"},
+		}
+
+		// when
+		actual, err := parseTable(data, tableOptions{
+			DecimalDelimiter: ",",
+			SkipColumnNames:  []string{"Gesamtzeit"},
+			SkipRowNames:     []string{"Gesamtzeit"},
+			DateLayouts:      []string{"02.01.2006"},
+		})
+
+		// then
+		require.NoError(t, err)
+		expected := core.NewPipelineData()
+		expectedEntry, _ := expected.AddPipeline(pipelineA)
+		expectedEntry.PutWorkTime("2021-05-03", 7.5)
+		expectedEntry.PutWorkTime("2021-05-04", 1)
+		assert.Equal(t, expected, actual)
+	})
+	t.Run("should fail for value without header", func(t *testing.T) {
+		data := [][]string{{"Pipeline", "2021-05-03"}, {pipelineA, "1", "2"}}
+
+		_, err := parseTable(data, tableOptions{DecimalDelimiter: "."})
+
+		require.Error(t, err)
+	})
+}
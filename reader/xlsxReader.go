@@ -0,0 +1,208 @@
+package reader
+
+import (
+	"archive/zip"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	xlsxWorkbook      = "xl/workbook.xml"
+	xlsxRelationships = "xl/_rels/workbook.xml.rels"
+	xlsxSharedStrings = "xl/sharedStrings.xml"
+
+	xlsxCellTypeSharedString = "s"
+	xlsxCellTypeInlineString = "inlineStr"
+	xlsxCellTypeDate         = "d"
+)
+
+type xlsxWorkbookXML struct {
+	Sheets []struct {
+		Name           string `xml:"name,attr"`
+		RelationshipID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationshipsXML struct {
+	Relationships []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxStringItemXML struct {
+	Text string `xml:"t"`
+	Runs []struct {
+		Text string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (si xlsxStringItemXML) String() string {
+	result := si.Text
+	for _, run := range si.Runs {
+		result += run.Text
+	}
+
+	return result
+}
+
+type xlsxSharedStringsXML struct {
+	Items []xlsxStringItemXML `xml:"si"`
+}
+
+type xlsxWorksheetXML struct {
+	Rows []struct {
+		Number int `xml:"r,attr"`
+		Cells  []struct {
+			Reference string            `xml:"r,attr"`
+			Type      string            `xml:"t,attr"`
+			Value     string            `xml:"v"`
+			Inline    xlsxStringItemXML `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+type xlsxReader struct {
+	options SpreadsheetOptions
+}
+
+func newXLSXReader(options SpreadsheetOptions) *xlsxReader {
+	return &xlsxReader{options: options}
+}
+
+// Read reads the selected sheet of an Excel workbook. Date headers which are stored as serial numbers are converted
+// into dates.
+func (xr *xlsxReader) Read() (*core.PipelineData, error) {
+	return readSpreadsheet(xr.options, readXLSXSheets)
+}
+
+func readXLSXSheets(archive *zip.Reader) ([]sheet, error) {
+	workbook := xlsxWorkbookXML{}
+	err := readZippedXML(archive, xlsxWorkbook, &workbook)
+	if err != nil {
+		return nil, err
+	}
+
+	relationships := xlsxRelationshipsXML{}
+	err = readZippedXML(archive, xlsxRelationships, &relationships)
+	if err != nil {
+		return nil, err
+	}
+	targets := map[string]string{}
+	for _, relationship := range relationships.Relationships {
+		targets[relationship.ID] = relationship.Target
+	}
+
+	sharedStrings := xlsxSharedStringsXML{}
+	err = readZippedXML(archive, xlsxSharedStrings, &sharedStrings)
+	// workbooks without any text cells omit the shared strings
+	if err != nil && errors.Cause(err) != errMissingZippedFile {
+		return nil, err
+	}
+
+	result := []sheet{}
+	for _, workbookSheet := range workbook.Sheets {
+		target, ok := targets[workbookSheet.RelationshipID]
+		if !ok {
+			return nil, errors.Errorf("could not find worksheet of sheet '%s'", workbookSheet.Name)
+		}
+
+		rows, err := readXLSXWorksheet(archive, worksheetPath(target), sharedStrings)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read sheet '%s'", workbookSheet.Name)
+		}
+		result = append(result, sheet{name: workbookSheet.Name, rows: rows})
+	}
+
+	return result, nil
+}
+
+// worksheetPath resolves a relationship target which is either absolute or relative to the workbook.
+func worksheetPath(target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(target, "/")
+	}
+
+	return path.Join(path.Dir(xlsxWorkbook), target)
+}
+
+func readXLSXWorksheet(archive *zip.Reader, name string, sharedStrings xlsxSharedStringsXML) ([][]string, error) {
+	worksheet := xlsxWorksheetXML{}
+	err := readZippedXML(archive, name, &worksheet)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := [][]string{}
+	for rowIndex, row := range worksheet.Rows {
+		rowNumber := row.Number
+		if rowNumber == 0 {
+			rowNumber = rowIndex + 1
+		}
+		for len(rows) < rowNumber {
+			rows = append(rows, []string{})
+		}
+
+		cells := []string{}
+		for cellIndex, cell := range row.Cells {
+			column := cellIndex
+			if cell.Reference != "" {
+				column = columnIndex(cell.Reference)
+			}
+			for len(cells) <= column {
+				cells = append(cells, "")
+			}
+
+			value, err := xlsxCellValue(cell.Type, cell.Value, cell.Inline, sharedStrings)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not read cell %s", cell.Reference)
+			}
+			if rowNumber == 1 && column > 0 {
+				value = serialToDate(value)
+			}
+			cells[column] = value
+		}
+		rows[rowNumber-1] = cells
+	}
+
+	return rows, nil
+}
+
+func xlsxCellValue(cellType string, value string, inline xlsxStringItemXML, sharedStrings xlsxSharedStringsXML) (string, error) {
+	switch cellType {
+	case xlsxCellTypeSharedString:
+		index, err := strconv.Atoi(value)
+		if err != nil {
+			return "", errors.Wrapf(err, "invalid shared string index '%s'", value)
+		}
+		if index < 0 || index >= len(sharedStrings.Items) {
+			return "", errors.Errorf("shared string index %d out of range", index)
+		}
+		return sharedStrings.Items[index].String(), nil
+	case xlsxCellTypeInlineString:
+		return inline.String(), nil
+	case xlsxCellTypeDate:
+		if len(value) >= len(core.DateLayout) {
+			return value[:len(core.DateLayout)], nil
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+}
+
+// columnIndex converts the column letters of a cell reference like "AB12" into a 0-based index.
+func columnIndex(reference string) int {
+	result := 0
+	for _, letter := range reference {
+		if letter < 'A' || letter > 'Z' {
+			break
+		}
+		result = result*26 + int(letter-'A') + 1
+	}
+
+	return result - 1
+}
@@ -0,0 +1,116 @@
+package reader
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+const xlsxWorkbookContent = `<?xml version="1.0" encoding="UTF-8"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+  <sheets>
+    <sheet name="Summary" sheetId="1" r:id="rId1"/>
+    <sheet name="Timelog" sheetId="2" r:id="rId2"/>
+  </sheets>
+</workbook>`
+
+const xlsxRelationshipsContent = `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+  <Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+  <Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/>
+</Relationships>`
+
+const xlsxSharedStringsContent = `<?xml version="1.0" encoding="UTF-8"?>
+<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <si><t>Anforderungspipeline</t></si>
+  <si><t>Pipeline A</t></si>
+  <si><r><t>Gesamt</t></r><r><t>zeit</t></r></si>
+  <si><t>2021-05-04</t></si>
+</sst>`
+
+const xlsxTimelogSheetContent = `<?xml version="1.0" encoding="UTF-8"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+  <sheetData>
+    <row r="1">
+      <c r="A1" t="s"><v>0</v></c>
+      <c r="B1"><v>44319</v></c>
+      <c r="C1" t="s"><v>3</v></c>
+      <c r="D1" t="s"><v>2</v></c>
+    </row>
+    <row r="2">
+      <c r="A2" t="s"><v>1</v></c>
+      <c r="B2"><v>7.5</v></c>
+      <c r="D2"><v>7.5</v></c>
+    </row>
+    <row r="4">
+      <c r="A4" t="inlineStr"><is><t>Pipeline B</t></is></c>
+      <c r="B4" t="str"><v>1,5</v></c>
+      <c r="C4"><v>2</v></c>
+      <c r="D4"><v>3.5</v></c>
+    </row>
+    <row r="5">
+      <c r="A5" t="s"><v>2</v></c>
+      <c r="B5"><v>9</v></c>
+      <c r="C5"><v>2</v></c>
+      <c r="D5"><v>11</v></c>
+    </row>
+  </sheetData>
+</worksheet>`
+
+func Test_xlsxReader_Read(t *testing.T) {
+	path := writeZipFile(t, map[string]string{
+		"xl/workbook.xml":            xlsxWorkbookContent,
+		"xl/_rels/workbook.xml.rels": xlsxRelationshipsContent,
+		"xl/sharedStrings.xml":       xlsxSharedStringsContent,
+		"xl/worksheets/sheet1.xml":   `<worksheet><sheetData/></worksheet>`,
+		"xl/worksheets/sheet2.xml":   xlsxTimelogSheetContent,
+	})
+	defer os.Remove(path)
+
+	t.Run("should read selected sheet like a CSV export", func(t *testing.T) {
+		sut := newXLSXReader(SpreadsheetOptions{
+			Filename:         path,
+			Sheet:            "Timelog",
+			DecimalDelimiter: ",",
+			SkipColumnNames:  []string{"Gesamtzeit"},
+			SkipSummaryLine:  true,
+		})
+
+		// when
+		actual, err := sut.Read()
+
+		// then
+		require.NoError(t, err)
+		expected := core.NewPipelineData()
+		expectedA, _ := expected.AddPipeline(pipelineA)
+		expectedA.PutWorkTime("2021-05-03", 7.5)
+		expectedA.PutWorkTime("2021-05-04", 0)
+		expectedB, _ := expected.AddPipeline("Pipeline B")
+		expectedB.PutWorkTime("2021-05-03", 1.5)
+		expectedB.PutWorkTime("2021-05-04", 2)
+		assert.Equal(t, expected, actual)
+	})
+	t.Run("should read empty first sheet by default", func(t *testing.T) {
+		sut := newXLSXReader(SpreadsheetOptions{Filename: path})
+
+		actual, err := sut.Read()
+
+		require.NoError(t, err)
+		assert.Equal(t, 0, actual.Entries())
+	})
+	t.Run("should fail for missing file", func(t *testing.T) {
+		sut := newXLSXReader(SpreadsheetOptions{Filename: "/does/not/exist.xlsx"})
+
+		_, err := sut.Read()
+
+		require.Error(t, err)
+	})
+}
+
+func Test_columnIndex(t *testing.T) {
+	assert.Equal(t, 0, columnIndex("A1"))
+	assert.Equal(t, 3, columnIndex("D12"))
+	assert.Equal(t, 27, columnIndex("AB3"))
+}
@@ -1,22 +1,40 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/calendar"
+	"github.com/ppxl/sagemine/config"
 	"github.com/ppxl/sagemine/core"
+	"github.com/ppxl/sagemine/credentials"
 	"github.com/ppxl/sagemine/cruncher"
+	"github.com/ppxl/sagemine/history"
+	"github.com/ppxl/sagemine/ledger"
+	"github.com/ppxl/sagemine/locale"
 	"github.com/ppxl/sagemine/logging"
+	"github.com/ppxl/sagemine/output"
 	"github.com/ppxl/sagemine/reader"
+	"github.com/ppxl/sagemine/redsage"
+	"github.com/ppxl/sagemine/report"
 	"github.com/ppxl/sagemine/transformer"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli/v2"
+	"io"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 const (
 	flagGlobalLogLevel           = "log-level"
+	flagGlobalLogFormat          = "log-format"
+	flagGlobalConfigLong         = "config"
 	flagLunchBreakInMinutesLong  = "break"
 	flagLunchBreakInMinutesShort = "b"
+	flagLunchStartLong           = "lunch-start"
 	flagSinglePipelinesLong      = "pipeline-single"
 	flagSinglePipelinesShort     = "p"
 	flagCSVColumnDelimiterLong   = "csv-column-delimiter"
@@ -27,22 +45,114 @@ const (
 	flagIgnoreSummaryLineShort   = "i"
 	flagSkipColumnsLong          = "skip-column"
 	flagSkipColumnsShort         = "s"
+	flagTransformStepsLong       = "transform"
+	flagTransformStepsShort      = "t"
+	flagFromDateLong             = "from"
+	flagToDateLong               = "to"
+	flagWeekLong                 = "week"
+	flagMonthLong                = "month"
+	flagHolidayStateLong         = "holiday-state"
+	flagHolidayFileLong          = "holiday-file"
+	flagNonWorkingDaysLong       = "non-working-days"
+	flagInputFormatLong          = "input-format"
+	flagSheetLong                = "sheet"
+	flagEncodingLong             = "encoding"
+	flagLocaleLong               = "locale"
+	flagLocaleShort              = "l"
+	flagLocaleFileLong           = "locale-file"
+	flagOutputFormatLong         = "output-format"
+	flagOutputFormatShort        = "o"
+	flagRedmineURLLong           = "redmine-url"
+	flagRedmineUserLong          = "redmine-user"
+	flagRedmineUserIDLong        = "redmine-user-id"
+	flagPasswordCommandLong      = "redmine-password-command"
+	flagNetrcFileLong            = "netrc-file"
+	flagExplainLong              = "explain"
+	flagExplainFormatLong        = "explain-format"
+	flagTargetHoursLong          = "target-hours"
+	flagLedgerLong               = "ledger"
+	flagHistoryLong              = "history"
+	flagReaderLong               = "reader"
+	flagCruncherLong             = "cruncher"
+	flagChunkLong                = "chunk"
+	flagInterleaveLong           = "interleave"
+	flagAnchorLong               = "anchor"
+	flagMinSlotLong              = "min-slot"
+	flagMergeIntoLong            = "merge-into"
+	flagConsolidateLong          = "consolidate"
+)
+
+const inputFormatAuto = "auto"
+
+const runArgsUsage = "redmine CSV, XLSX or ODS file [more files or glob patterns...] (optional with --" + flagRedmineURLLong + ")"
+
+// Formats of the --explain trace.
+const (
+	explainFormatText = "text"
+	explainFormatJSON = "json"
 )
 
 var (
 	// Version of the application
 	Version string
-	log     = logrus.New()
+	log     = logging.Logger()
 )
 
 type runArgs struct {
-	lunchBreakInMin  int
+	lunchBreakInMin int
+	lunchStartTime  string
+	// pipelineOrder contains the preferred order of pipelines per date.
+	pipelineOrder    map[string][]string
 	singlePipelines  []string
-	filename         string
+	filenames        []string
 	csvDelimiter     string
 	decimalDelimiter string
 	skipColumnNames  []string
 	skipSummaryLine  bool
+	transformSteps   []transformer.StepDefinition
+	dateRange        core.DateRange
+	holidayState     string
+	holidayFile      string
+	nonWorkingDays   string
+	inputFormat      string
+	sheet            string
+	encoding         string
+	skipRowNames     []string
+	dateLayouts      []string
+	locale           string
+	localeFile       string
+	locales          []locale.Preset
+	outputFormat     string
+	outputOptions    output.Options
+	redmine          redmineArgs
+	explain          bool
+	explainFormat    string
+	// targetHours contains the expected hours of each working day.
+	targetHours float64
+	// ledgerFile enables the overtime ledger if it is set.
+	ledgerFile string
+	// historyDir enables storing the crunched weeks if it is set.
+	historyDir string
+	// cruncherName selects a registered cruncher.
+	cruncherName string
+	// chunkMinutes and interleaveMode configure the interleaved cruncher.
+	chunkMinutes   int
+	interleaveMode string
+	// anchors contains pipelines with fixed times of day.
+	anchors []cruncher.Anchor
+	// minSlotMinutes, mergeInto and consolidate avoid short and scattered time slots.
+	minSlotMinutes int
+	mergeInto      string
+	consolidate    bool
+}
+
+// redmineArgs select the Redmine REST API as an additional source. They never contain secrets.
+type redmineArgs struct {
+	url             string
+	user            string
+	userID          string
+	passwordCommand string
+	netrcFile       string
 }
 
 func createGlobalFlags() []cli.Flag {
@@ -52,19 +162,28 @@ func createGlobalFlags() []cli.Flag {
 			Usage: "define log level",
 			Value: "warning",
 		},
+		&cli.StringFlag{
+			Name:  flagGlobalLogFormat,
+			Usage: "log format: text or json. Log messages are written to stderr",
+			Value: logging.FormatText,
+		},
+		&cli.StringFlag{
+			Name: flagGlobalConfigLong,
+			Usage: "read the options from this config file instead of " + strings.Join(config.DefaultFiles(), " and ") +
+				" (optional). Flags and environment variables take precedence",
+		},
 	}
 }
 
 func configureLogging(cliCtx *cli.Context) error {
-	logLevel := cliCtx.String("log-level")
+	logLevel := cliCtx.String(flagGlobalLogLevel)
 	logLevelParsed, err := logrus.ParseLevel(logLevel)
 	if err != nil {
 		log.Errorf("could not parse log level %s to logrus level. Defaulting to WARN", logLevel)
 		logLevelParsed = logrus.WarnLevel
 	}
 
-	err = logging.Init(logLevelParsed)
-	log = logging.Logger()
+	err = logging.Init(logLevelParsed, cliCtx.String(flagGlobalLogFormat))
 	if err != nil {
 		return errors.Wrap(err, "could not initialize logging")
 	}
@@ -82,7 +201,7 @@ func configureApplication(cliCtx *cli.Context) error {
 
 func checkMainError(err error) {
 	if err != nil {
-		println("%+s\n", err)
+		_, _ = fmt.Fprintf(os.Stderr, "%s\n", err)
 		os.Exit(1)
 	}
 }
@@ -93,7 +212,7 @@ func main() {
 	app.Name = "redsage"
 	app.Usage = "Maintain sanity while combining Redmine activity times and Sage project times"
 	app.Version = Version
-	app.Commands = []*cli.Command{run()}
+	app.Commands = []*cli.Command{run(), tuiCommand(), reportCommand(), diffCommand(), compareCommand(), batchCommand(), serveCommand(), configCommand()}
 
 	app.Flags = createGlobalFlags()
 	app.Before = configureApplication
@@ -106,158 +225,879 @@ func run() *cli.Command {
 		Name:      "run",
 		Usage:     "read Redmine work time data and convert them to Sage-compatible data",
 		Action:    doCliRun,
-		ArgsUsage: "redmine CSV file",
-		Flags: []cli.Flag{
-			&cli.IntFlag{
-				Name:    flagLunchBreakInMinutesLong,
-				Aliases: []string{flagLunchBreakInMinutesShort},
-				Usage:   "lunch break time in minutes (optional)",
-				Value:   60,
-			},
-			&cli.StringSliceFlag{
-				Name:    flagSinglePipelinesLong,
-				Aliases: []string{flagSinglePipelinesShort},
-				Usage: "These pipelines will receive their own pipeline and will not be joint into a single pseudo-pipeline (optional). " +
-					"All other pipelines will be merged into a single pseudo-pipeline.",
-			},
-			&cli.StringSliceFlag{
-				Name:    flagSkipColumnsLong,
-				Aliases: []string{flagSkipColumnsShort},
-				Usage:   "columns with these headers will be ignored (optional)",
-			},
-			&cli.StringFlag{
-				Name:    flagCSVColumnDelimiterLong,
-				Aliases: []string{flagCSVColumnDelimiterShort},
-				Usage:   "this delimiter will be used to parse CSV columns (optional)",
-				Value:   ";",
-			},
-			&cli.StringFlag{
-				Name:    flagDecimalDelimiterLong,
-				Aliases: []string{flagDecimalDelimiterShort},
-				Usage:   "Set the decimal delimiter if the decimals in the CSV export uses a different format than '2.75' (optional)",
-				Value:   ".",
-			},
-			&cli.BoolFlag{
-				Name:    flagIgnoreSummaryLineLong,
-				Aliases: []string{flagIgnoreSummaryLineShort},
-				Usage:   "Set if the last line in the CSV export should be included or nto (optional)",
-				Value:   true,
-			},
+		ArgsUsage: runArgsUsage,
+		Flags:     append(runFlags(), watchFlags()...),
+	}
+}
+
+// runFlags returns the flags of all commands which run the whole pipeline.
+func runFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name: flagRedmineURLLong,
+			Usage: "read the time entries of the date range from the REST API of this Redmine instance (optional). " +
+				"Credentials are read from $" + credentials.EnvAPIKey + ", $" + credentials.EnvUser + "/$" +
+				credentials.EnvPassword + ", the password command or the netrc file",
+		},
+		&cli.StringFlag{
+			Name:  flagRedmineUserLong,
+			Usage: "Redmine user which authenticates against the REST API (optional, default: API key)",
+		},
+		&cli.StringFlag{
+			Name:  flagRedmineUserIDLong,
+			Usage: "only read the time entries of this Redmine user ID, f. i. 'me' (optional, default: all users)",
+		},
+		&cli.StringFlag{
+			Name:  flagPasswordCommandLong,
+			Usage: "shell command which prints the Redmine password or API key, f. i. 'pass show redmine' (optional)",
+		},
+		&cli.StringFlag{
+			Name:  flagNetrcFileLong,
+			Usage: "netrc file with the Redmine credentials which must only be readable by you (optional, default: ~/.netrc)",
+		},
+		&cli.IntFlag{
+			Name:    flagLunchBreakInMinutesLong,
+			Aliases: []string{flagLunchBreakInMinutesShort},
+			Usage:   "lunch break time in minutes (optional)",
+			Value:   60,
+		},
+		&cli.StringFlag{
+			Name:  flagLunchStartLong,
+			Usage: "start of the lunch break in 24-hour format (optional)",
+			Value: cruncher.DefaultLunchStartTime,
+		},
+		&cli.StringSliceFlag{
+			Name:    flagSinglePipelinesLong,
+			Aliases: []string{flagSinglePipelinesShort},
+			Usage: "These pipelines will receive their own pipeline and will not be joint into a single pseudo-pipeline (optional). " +
+				"All other pipelines will be merged into a single pseudo-pipeline.",
+		},
+		&cli.StringSliceFlag{
+			Name:    flagTransformStepsLong,
+			Aliases: []string{flagTransformStepsShort},
+			Usage: "Transformation step of the form name[:option=value,...] (optional, repeatable). Steps run in the given order. " +
+				"Multiple option values are separated by '|', f. i. 'filter:exclude=ACME|Internal'. " +
+				"Available transformers: " + strings.Join(transformer.Names(), ", ") + ". " +
+				"Without any step all pipelines except the single pipelines will be joined.",
+		},
+		&cli.StringFlag{
+			Name:    flagLocaleLong,
+			Aliases: []string{flagLocaleShort},
+			Usage: "locale preset which sets CSV delimiter, decimal delimiter, summary labels, date header formats and " +
+				"output number format at once, f. i. de, en or fr (optional). Explicit flags take precedence",
+		},
+		&cli.StringFlag{
+			Name:  flagLocaleFileLong,
+			Usage: "YAML file with additional locale presets (optional)",
+		},
+		&cli.StringFlag{
+			Name:    flagOutputFormatLong,
+			Aliases: []string{flagOutputFormatShort},
+			Usage:   "output format: " + strings.Join(output.Formats(), ", ") + " (optional)",
+			Value:   output.FormatText,
+		},
+		&cli.BoolFlag{
+			Name:  flagExplainLong,
+			Usage: "write how each time slot was produced to stderr (optional)",
+		},
+		&cli.StringFlag{
+			Name:  flagExplainFormatLong,
+			Usage: "format of the --" + flagExplainLong + " trace: text or json (optional)",
+			Value: explainFormatText,
+		},
+		&cli.Float64Flag{
+			Name:  flagTargetHoursLong,
+			Usage: "expected work hours of each working day in reports and the ledger (optional)",
+			Value: report.DefaultTargetHoursPerDay,
+		},
+		&cli.StringFlag{
+			Name:  flagLedgerLong,
+			Usage: "JSON file which keeps the overtime balance across runs, updated by each run (optional)",
+		},
+		&cli.StringFlag{
+			Name:  flagHistoryLong,
+			Usage: "directory which keeps each crunched week to compare it with 'diff' later (optional)",
+		},
+		&cli.StringFlag{
+			Name:    flagInputFormatLong,
+			Aliases: []string{flagReaderLong},
+			Usage: "reader of the input files: auto (by file extension), " + strings.Join(reader.Names(), ", ") +
+				" (optional, " + reader.FormatAPI + " reads --" + flagRedmineURLLong + " only)",
+			Value: inputFormatAuto,
+		},
+		&cli.StringFlag{
+			Name:  flagCruncherLong,
+			Usage: "cruncher which places the work time into time slots: " + strings.Join(cruncher.Names(), ", ") + " (optional)",
+			Value: cruncher.SequentialName,
+		},
+		&cli.IntFlag{
+			Name:  flagChunkLong,
+			Usage: "minutes which the " + cruncher.InterleavedName + " cruncher places of a pipeline before it turns to the next one (optional)",
+			Value: cruncher.DefaultChunkMinutes,
+		},
+		&cli.StringFlag{
+			Name: flagInterleaveLong,
+			Usage: "how the " + cruncher.InterleavedName + " cruncher mixes the pipelines of a day: " +
+				cruncher.InterleaveRoundRobin + " (in chunks), " + cruncher.InterleaveHalfDays + " (by weight into morning and afternoon) (optional)",
+			Value: cruncher.InterleaveRoundRobin,
+		},
+		&cli.StringSliceFlag{
+			Name: flagAnchorLong,
+			Usage: "places a pipeline at a fixed time of day before all other pipelines, f. i. " +
+				"\"Pipeline A=09:00-09:15@mon-fri\" (optional, repeatable, weekdays optional)",
+		},
+		&cli.IntFlag{
+			Name: flagMinSlotLong,
+			Usage: "minimum minutes of a time slot: shorter work times of a day are merged into another pipeline and the " +
+				"lunch break moves instead of splitting off a shorter slot (optional)",
+		},
+		&cli.StringFlag{
+			Name:  flagMergeIntoLong,
+			Usage: "pipeline which receives the work times below --" + flagMinSlotLong + " (optional, default: the pipeline placed before them)",
+		},
+		&cli.BoolFlag{
+			Name:  flagConsolidateLong,
+			Usage: "moves the lunch break behind a time slot instead of splitting it (optional)",
+		},
+		&cli.StringFlag{
+			Name:  flagSheetLong,
+			Usage: "name or 1-based position of the spreadsheet sheet to read from XLSX or ODS files (optional, default: first sheet)",
+		},
+		&cli.StringFlag{
+			Name:  flagFromDateLong,
+			Usage: "only dates on or after this date (YYYY-MM-DD) will be crunched (optional)",
+		},
+		&cli.StringFlag{
+			Name:  flagToDateLong,
+			Usage: "only dates on or before this date (YYYY-MM-DD) will be crunched (optional)",
+		},
+		&cli.StringFlag{
+			Name:  flagWeekLong,
+			Usage: "only dates of this ISO week (f. i. 2021-W18) will be crunched (optional)",
+		},
+		&cli.StringFlag{
+			Name:  flagMonthLong,
+			Usage: "only dates of this month (f. i. 2021-05) will be crunched (optional)",
+		},
+		&cli.StringFlag{
+			Name:  flagHolidayStateLong,
+			Usage: "consider the public holidays of this German state, f. i. BY or NW (optional). Nationwide holidays are always considered",
+		},
+		&cli.StringFlag{
+			Name:  flagHolidayFileLong,
+			Usage: "file with custom holidays, one 'YYYY-MM-DD name' or 'MM-DD name' per line (optional)",
+		},
+		&cli.StringFlag{
+			Name:  flagNonWorkingDaysLong,
+			Usage: "how to treat work time on weekends and holidays: ignore, warn or relocate to the previous working day (optional)",
+			Value: redsage.NonWorkingDaysWarn,
+		},
+		&cli.StringSliceFlag{
+			Name:    flagSkipColumnsLong,
+			Aliases: []string{flagSkipColumnsShort},
+			Usage:   "columns with these headers will be ignored (optional)",
+		},
+		&cli.StringFlag{
+			Name:    flagCSVColumnDelimiterLong,
+			Aliases: []string{flagCSVColumnDelimiterShort},
+			Usage:   "this delimiter will be used to parse CSV columns (optional, detected by default)",
+		},
+		&cli.StringFlag{
+			Name:    flagDecimalDelimiterLong,
+			Aliases: []string{flagDecimalDelimiterShort},
+			Usage:   "Set the decimal delimiter if the decimals in the CSV export uses a different format than '2.75' (optional, detected by default)",
+		},
+		&cli.StringFlag{
+			Name:  flagEncodingLong,
+			Usage: "encoding of the CSV export: utf-8, utf-16le, utf-16be or windows-1252 (optional, detected by default)",
+		},
+		&cli.BoolFlag{
+			Name:    flagIgnoreSummaryLineLong,
+			Aliases: []string{flagIgnoreSummaryLineShort},
+			Usage:   "Set if the last line in the CSV export should be included or nto (optional)",
+			Value:   true,
 		},
 	}
 }
 
 func doCliRun(cliCtx *cli.Context) error {
-	if cliCtx.Args().Len() < 1 {
-		_ = cli.ShowAppHelp(cliCtx)
-		return errors.New("filename argument missed")
+	args, err := buildRunArgs(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	if cliCtx.Bool(flagWatchLong) {
+		return doCliWatch(cliCtx, args)
+	}
+
+	return doRun(args)
+}
+
+// buildRunArgs merges the flags with the config files and environment variables, flags taking precedence.
+func buildRunArgs(cliCtx *cli.Context) (runArgs, error) {
+	return buildArgs(cliCtx, true)
+}
+
+// buildArgs works like buildRunArgs but only requires input files or a Redmine URL if needsInput is set.
+func buildArgs(cliCtx *cli.Context, needsInput bool) (runArgs, error) {
+	cfg, err := config.Load(cliCtx.String(flagGlobalConfigLong))
+	if err != nil {
+		return runArgs{}, err
+	}
+	for _, source := range cfg.Sources {
+		log.Infof("using configuration from %s", source)
+	}
+
+	redmine := redmineArgs{
+		url:             configuredString(cliCtx, flagRedmineURLLong, cfg.Redmine.URL),
+		user:            configuredString(cliCtx, flagRedmineUserLong, cfg.Redmine.User),
+		userID:          configuredString(cliCtx, flagRedmineUserIDLong, cfg.Redmine.UserID),
+		passwordCommand: configuredString(cliCtx, flagPasswordCommandLong, cfg.Redmine.PasswordCommand),
+		netrcFile:       configuredString(cliCtx, flagNetrcFileLong, cfg.Redmine.NetrcFile),
 	}
-	if cliCtx.Args().Len() > 1 {
+	if needsInput && cliCtx.Args().Len() < 1 && redmine.url == "" {
 		_ = cli.ShowAppHelp(cliCtx)
-		return fmt.Errorf("found more arguments than expected: '%v'", cliCtx.Args().Slice()[1:])
+		return runArgs{}, errors.New("filename argument missed")
+	}
+
+	filenames, err := expandFilenames(cliCtx.Args().Slice())
+	if err != nil {
+		return runArgs{}, err
+	}
+	dateRange, err := parseDateRange(cliCtx)
+	if err != nil {
+		return runArgs{}, err
 	}
 
-	filename := cliCtx.Args().First()
-	lunchBreakInMin := cliCtx.Int(flagLunchBreakInMinutesLong)
-	csvColumnDelimiter := cliCtx.String(flagCSVColumnDelimiterLong)
-	decimalDelimiter := cliCtx.String(flagDecimalDelimiterLong)
-	ignoreSummaryLine := cliCtx.Bool(flagIgnoreSummaryLineLong)
-	singlePipelines := cliCtx.StringSlice(flagSinglePipelinesLong)
-	skipColumns := cliCtx.StringSlice(flagSkipColumnsLong)
+	transformSteps, err := configuredSteps(cliCtx, cfg.Transform.Steps)
+	if err != nil {
+		return runArgs{}, err
+	}
+
+	anchors, err := configuredAnchors(cliCtx, cfg.Cruncher.Anchors)
+	if err != nil {
+		return runArgs{}, err
+	}
 
 	args := runArgs{
-		lunchBreakInMin:  lunchBreakInMin,
-		singlePipelines:  singlePipelines,
-		filename:         filename,
-		csvDelimiter:     csvColumnDelimiter,
-		decimalDelimiter: decimalDelimiter,
-		skipColumnNames:  skipColumns,
-		skipSummaryLine:  ignoreSummaryLine,
+		lunchBreakInMin:  configuredInt(cliCtx, flagLunchBreakInMinutesLong, cfg.Cruncher.LunchBreakInMin),
+		lunchStartTime:   configuredString(cliCtx, flagLunchStartLong, cfg.Cruncher.LunchStart),
+		singlePipelines:  configuredStrings(cliCtx, flagSinglePipelinesLong, cfg.Transform.SinglePipelines),
+		filenames:        filenames,
+		csvDelimiter:     configuredString(cliCtx, flagCSVColumnDelimiterLong, cfg.Reader.CSVDelimiter),
+		decimalDelimiter: configuredString(cliCtx, flagDecimalDelimiterLong, cfg.Reader.DecimalDelimiter),
+		skipColumnNames:  configuredStrings(cliCtx, flagSkipColumnsLong, cfg.Reader.SkipColumns),
+		skipSummaryLine:  configuredBool(cliCtx, flagIgnoreSummaryLineLong, cfg.Reader.IgnoreSummaryLine),
+		transformSteps:   transformSteps,
+		dateRange:        dateRange,
+		holidayState:     configuredString(cliCtx, flagHolidayStateLong, cfg.Calendar.HolidayState),
+		holidayFile:      configuredString(cliCtx, flagHolidayFileLong, cfg.Calendar.HolidayFile),
+		nonWorkingDays:   configuredString(cliCtx, flagNonWorkingDaysLong, cfg.Calendar.NonWorkingDays),
+		inputFormat:      configuredString(cliCtx, flagInputFormatLong, cfg.Reader.InputFormat),
+		sheet:            configuredString(cliCtx, flagSheetLong, cfg.Reader.Sheet),
+		encoding:         configuredString(cliCtx, flagEncodingLong, cfg.Reader.Encoding),
+		outputFormat:     configuredString(cliCtx, flagOutputFormatLong, cfg.Output.Format),
+		locale:           configuredString(cliCtx, flagLocaleLong, cfg.Reader.Locale),
+		localeFile:       configuredString(cliCtx, flagLocaleFileLong, cfg.Reader.LocaleFile),
+		locales:          cfg.Locales,
+		redmine:          redmine,
+		explain:          cliCtx.Bool(flagExplainLong),
+		explainFormat:    cliCtx.String(flagExplainFormatLong),
+		targetHours:      configuredFloat(cliCtx, flagTargetHoursLong, cfg.Report.TargetHoursPerDay),
+		ledgerFile:       configuredString(cliCtx, flagLedgerLong, cfg.Ledger.File),
+		historyDir:       configuredString(cliCtx, flagHistoryLong, cfg.History.Dir),
+		cruncherName:     configuredString(cliCtx, flagCruncherLong, cfg.Cruncher.Name),
+		chunkMinutes:     configuredInt(cliCtx, flagChunkLong, cfg.Cruncher.ChunkMinutes),
+		interleaveMode:   configuredString(cliCtx, flagInterleaveLong, cfg.Cruncher.Interleave),
+		anchors:          anchors,
+		minSlotMinutes:   configuredInt(cliCtx, flagMinSlotLong, cfg.Cruncher.MinSlotMinutes),
+		mergeInto:        configuredString(cliCtx, flagMergeIntoLong, cfg.Cruncher.MergeInto),
+		consolidate:      configuredBool(cliCtx, flagConsolidateLong, cfg.Cruncher.Consolidate),
 	}
 
-	return doRun(args)
+	err = applyLocale(&args)
+	if err != nil {
+		return runArgs{}, err
+	}
+
+	return args, nil
+}
+
+// expandFilenames resolves glob patterns and removes duplicate files while keeping the order of the arguments.
+// Arguments without matches are kept so that missing files will be reported when they are read.
+func expandFilenames(arguments []string) ([]string, error) {
+	result := []string{}
+	seen := map[string]bool{}
+
+	for _, argument := range arguments {
+		matches, err := filepath.Glob(argument)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid file pattern '%s'", argument)
+		}
+		if len(matches) == 0 {
+			matches = []string{argument}
+		}
+
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				result = append(result, match)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// configuredString returns the flag value if the flag was set, otherwise the configured value. Without both the
+// flag's default value is returned.
+func configuredString(cliCtx *cli.Context, flagName string, configured string) string {
+	if cliCtx.IsSet(flagName) || configured == "" {
+		return cliCtx.String(flagName)
+	}
+
+	return configured
+}
+
+func configuredStrings(cliCtx *cli.Context, flagName string, configured []string) []string {
+	if cliCtx.IsSet(flagName) || len(configured) == 0 {
+		return cliCtx.StringSlice(flagName)
+	}
+
+	return configured
+}
+
+func configuredInt(cliCtx *cli.Context, flagName string, configured *int) int {
+	if cliCtx.IsSet(flagName) || configured == nil {
+		return cliCtx.Int(flagName)
+	}
+
+	return *configured
+}
+
+func configuredBool(cliCtx *cli.Context, flagName string, configured *bool) bool {
+	if cliCtx.IsSet(flagName) || configured == nil {
+		return cliCtx.Bool(flagName)
+	}
+
+	return *configured
+}
+
+func configuredFloat(cliCtx *cli.Context, flagName string, configured *float64) float64 {
+	if cliCtx.IsSet(flagName) || configured == nil {
+		return cliCtx.Float64(flagName)
+	}
+
+	return *configured
+}
+
+// configuredSteps returns the transformation steps of the flags if any were set, otherwise the configured steps.
+func configuredSteps(cliCtx *cli.Context, configured []transformer.StepDefinition) ([]transformer.StepDefinition, error) {
+	specs := cliCtx.StringSlice(flagTransformStepsLong)
+	if len(specs) == 0 {
+		return configured, nil
+	}
+
+	result := []transformer.StepDefinition{}
+	for _, spec := range specs {
+		definition, err := transformer.ParseStep(spec)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, definition)
+	}
+
+	return result, nil
+}
+
+func configuredAnchors(cliCtx *cli.Context, configured []cruncher.Anchor) ([]cruncher.Anchor, error) {
+	specs := cliCtx.StringSlice(flagAnchorLong)
+	if len(specs) == 0 {
+		return configured, nil
+	}
+
+	result := []cruncher.Anchor{}
+	for _, spec := range specs {
+		anchor, err := cruncher.ParseAnchor(spec)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, anchor)
+	}
+
+	return result, nil
+}
+
+// parseDateRange returns the date range selected by either --week, --month or --from/--to.
+func parseDateRange(cliCtx *cli.Context) (core.DateRange, error) {
+	week := cliCtx.String(flagWeekLong)
+	month := cliCtx.String(flagMonthLong)
+	from := cliCtx.String(flagFromDateLong)
+	to := cliCtx.String(flagToDateLong)
+
+	selections := 0
+	for _, selected := range []bool{week != "", month != "", from != "" || to != ""} {
+		if selected {
+			selections++
+		}
+	}
+	if selections > 1 {
+		return core.DateRange{}, fmt.Errorf("only one of --%s, --%s or --%s/--%s may be used", flagWeekLong, flagMonthLong, flagFromDateLong, flagToDateLong)
+	}
+
+	switch {
+	case week != "":
+		return core.ParseISOWeek(week)
+	case month != "":
+		return core.ParseMonth(month)
+	default:
+		return core.NewDateRange(from, to)
+	}
 }
 
 func doRun(args runArgs) error {
-	data, err := readRedmineData(args)
+	pipeline, result, err := processRedmineData(args)
 	if err != nil {
 		return err
 	}
 
-	joinedData, err := joinRedmineData(data, args)
+	err = pipeline.Write(context.Background(), os.Stdout, result.Crunched)
 	if err != nil {
 		return err
 	}
 
-	crunched, err := crunch(joinedData, args)
+	if args.historyDir != "" {
+		weeks, err := history.NewStore(args.historyDir).Record(result.Data, result.Crunched, time.Now())
+		if err != nil {
+			return err
+		}
+		for _, week := range weeks {
+			log.Infof("stored week %s in history %s", week, args.historyDir)
+		}
+	}
+
+	if args.ledgerFile == "" {
+		return nil
+	}
+	return updateLedger(os.Stderr, result.Data, result.Crunched, pipeline.Calendar(), args, time.Now())
+}
+
+// updateLedger records the target and booked hours of the crunched days and writes the overtime balance.
+func updateLedger(w io.Writer, data *core.PipelineData, crunched *core.CrunchedOutput, workCalendar *calendar.Calendar, args runArgs, now time.Time) error {
+	summary, err := report.New(data, crunched, report.Options{TargetHoursPerDay: args.targetHours, Days: workCalendar})
 	if err != nil {
 		return err
 	}
 
-	printResults(crunched)
+	// the ledger books the Redmine hours like the report, but expects the target hours of every working day of the
+	// processed range, even if the export contains no hours for it
+	booked := map[string]float64{}
+	for _, day := range summary.Days {
+		booked[day.Date] = day.Total
+	}
 
-	return nil
+	days := []ledger.Day{}
+	processed, ok := processedRange(data, args.dateRange)
+	for date := processed.From; ok && !date.After(processed.To); date = date.AddDate(0, 0, 1) {
+		target := 0.0
+		if workCalendar.IsWorkingDay(date) {
+			target = args.targetHours
+		}
+		name := date.Format(core.DateLayout)
+		days = append(days, ledger.Day{Date: name, Target: target, Booked: booked[name]})
+	}
+
+	book, err := ledger.Load(args.ledgerFile)
+	if err != nil {
+		return err
+	}
+	weeks, err := book.Record(days)
+	if err != nil {
+		return err
+	}
+	err = book.Save(args.ledgerFile)
+	if err != nil {
+		return err
+	}
+
+	return book.WriteSummary(w, weeks, now)
 }
 
-func printResults(crunched *core.CrunchedOutput) {
-	for i, pipeline := range crunched.NamedDaySageValues {
-		fmt.Printf("%v\n", i)
-		for _, date := range pipeline.SortedKeys() {
-			fmt.Printf("%s\t", date)
-			for _, timeslot := range pipeline.TimeSlots(date) {
-				fmt.Printf("%s\t", timeslot.String())
-			}
-			fmt.Println()
+// processedRange returns the selected date range. Open sides are completed by the ISO weeks of the first and the
+// last date of the data. False is returned if an open range contains no dates.
+func processedRange(data *core.PipelineData, dateRange core.DateRange) (core.DateRange, bool) {
+	result := dateRange
+	if !result.From.IsZero() && !result.To.IsZero() {
+		return result, true
+	}
+
+	dates := []time.Time{}
+	for _, date := range data.SortedDates() {
+		parsed, err := time.Parse(core.DateLayout, date)
+		if err == nil {
+			dates = append(dates, parsed)
+		}
+	}
+	if len(dates) == 0 {
+		return core.DateRange{}, false
+	}
+
+	if result.From.IsZero() {
+		firstWeek, _ := core.ParseISOWeek(core.FormatISOWeek(dates[0]))
+		result.From = firstWeek.From
+	}
+	if result.To.IsZero() {
+		lastWeek, _ := core.ParseISOWeek(core.FormatISOWeek(dates[len(dates)-1]))
+		result.To = lastWeek.To
+	}
+
+	return result, true
+}
+
+// processRedmineData reads, transforms and crunches the Redmine data with the pipeline of the run arguments and
+// writes the explanation of the crunch if requested.
+func processRedmineData(args runArgs) (*redsage.Pipeline, *redsage.Result, error) {
+	redmineReader, err := createRedmineReader(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	crunchConfig := cruncherConfig(args)
+	if args.explain {
+		crunchConfig.Trace = cruncher.NewTrace()
+	}
+	pipeline, err := createPipeline(args, redmineReader, crunchConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := pipeline.Process(context.Background())
+	logConflicts(redmineReader)
+	if errors.Is(err, core.ErrInvalidDate) {
+		return nil, nil, errors.Wrapf(err, "error while processing Redmine data (skip columns without dates with --%s)", flagSkipColumnsLong)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if args.explain {
+		err = writeExplanation(os.Stderr, crunchConfig.Trace, args.explainFormat)
+		if err != nil {
+			return nil, nil, err
 		}
 	}
+
+	return pipeline, result, nil
+}
+
+// prepareRedmineData executes the stages of the pipeline before crunching so that the data can be crunched
+// repeatedly, f. i. with other schedules.
+func prepareRedmineData(args runArgs) (*core.PipelineData, *calendar.Calendar, error) {
+	redmineReader, err := createRedmineReader(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	pipeline, err := createPipeline(args, redmineReader, cruncherConfig(args))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx := context.Background()
+	data, err := pipeline.Read(ctx)
+	logConflicts(redmineReader)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err = pipeline.Transform(ctx, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err = pipeline.HandleNonWorkingDays(ctx, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, pipeline.Calendar(), nil
 }
 
-func joinRedmineData(data *core.PipelineData, args runArgs) (*core.PipelineData, error) {
-	trans := transformer.New()
-	joinConfig := transformer.Config{
-		SinglePipelineNames: args.singlePipelines,
+// createPipeline returns the library pipeline with all stages of the run arguments.
+func createPipeline(args runArgs, redmineReader reader.RedmineDataReader, crunchConfig cruncher.Config) (*redsage.Pipeline, error) {
+	workCalendar, err := createCalendar(args)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := createTransformSteps(args)
+	if err != nil {
+		return nil, err
+	}
+
+	crunch, err := cruncher.Create(args.cruncherName)
+	if err != nil {
+		return nil, err
 	}
 
-	joinedData, err := trans.Transform(data, joinConfig)
+	writer, err := createWriter(workCalendar, args)
 	if err != nil {
-		return nil, errors.Wrap(err, "error while transforming pipelines")
+		return nil, err
 	}
 
-	return joinedData, nil
+	return redsage.New(redsage.Options{
+		Reader:         redmineReader,
+		Steps:          steps,
+		Calendar:       workCalendar,
+		NonWorkingDays: args.nonWorkingDays,
+		Cruncher:       crunch,
+		CruncherConfig: crunchConfig,
+		Writer:         writer,
+		DateRange:      args.dateRange,
+	})
 }
 
-func readRedmineData(args runArgs) (*core.PipelineData, error) {
+func writeResults(w io.Writer, crunched *core.CrunchedOutput, workCalendar *calendar.Calendar, args runArgs) error {
+	writer, err := createWriter(workCalendar, args)
+	if err != nil {
+		return err
+	}
+
+	err = writer.Write(w, crunched)
+	if err != nil {
+		return errors.Wrap(err, "error while writing results")
+	}
+
+	return nil
+}
+
+// createWriter returns the writer of the selected output format which knows the working days of the calendar.
+func createWriter(workCalendar *calendar.Calendar, args runArgs) (output.Writer, error) {
+	options := args.outputOptions
+	options.Days = workCalendar
+
+	return output.New(args.outputFormat, options)
+}
+
+// applyLocale sets all options of the selected locale preset which were not set explicitly by flags, environment
+// variables or config files.
+func applyLocale(args *runArgs) error {
+	if args.locale == "" {
+		return nil
+	}
+
+	presets := locale.BuiltIn()
+	err := presets.AddAll(args.locales)
+	if err != nil {
+		return err
+	}
+	if args.localeFile != "" {
+		err = presets.LoadFile(args.localeFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	preset, err := presets.Lookup(args.locale)
+	if err != nil {
+		return err
+	}
+
+	if args.csvDelimiter == "" {
+		args.csvDelimiter = preset.CSVDelimiter
+	}
+	if args.decimalDelimiter == "" {
+		args.decimalDelimiter = preset.DecimalDelimiter
+	}
+	args.dateLayouts = preset.DateHeaderLayouts
+	if preset.SummaryColumnLabel != "" {
+		args.skipColumnNames = append(args.skipColumnNames, preset.SummaryColumnLabel)
+	}
+	if preset.SummaryRowLabel != "" {
+		args.skipRowNames = append(args.skipRowNames, preset.SummaryRowLabel)
+	}
+	args.outputOptions.DecimalSeparator = preset.OutputDecimalSeparator
+	args.outputOptions.CSVDelimiter = preset.CSVDelimiter
+
+	return nil
+}
+
+func createCalendar(args runArgs) (*calendar.Calendar, error) {
+	workCalendar, err := calendar.New(args.holidayState)
+	if err != nil {
+		return nil, err
+	}
+
+	if args.holidayFile != "" {
+		err = workCalendar.LoadHolidayFile(args.holidayFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return workCalendar, nil
+}
+
+// createTransformSteps returns the declared transformation steps. Without any declared step all pipelines are joined
+// except for the single pipelines.
+func createTransformSteps(args runArgs) ([]transformer.Step, error) {
+	if len(args.transformSteps) == 0 {
+		return redsage.DefaultSteps(args.singlePipelines), nil
+	}
+
+	return transformer.NewSteps(args.transformSteps)
+}
+
+// createRedmineReader returns a reader of all input files and of the Redmine REST API if its URL is given.
+func createRedmineReader(args runArgs) (reader.RedmineDataReader, error) {
+	if args.inputFormat == reader.FormatAPI && (len(args.filenames) > 0 || args.redmine.url == "") {
+		return nil, errors.Errorf("reader %s reads no files but needs --%s", reader.FormatAPI, flagRedmineURLLong)
+	}
+
+	sources := []reader.Source{}
+	for _, filename := range args.filenames {
+		options := reader.Options{
+			CSVOptions: reader.CSVOptions{
+				Filename:         filename,
+				Encoding:         args.encoding,
+				CSVDelimiter:     args.csvDelimiter,
+				DecimalDelimiter: args.decimalDelimiter,
+				SkipColumnNames:  args.skipColumnNames,
+				SkipRowNames:     args.skipRowNames,
+				SkipSummaryLine:  args.skipSummaryLine,
+				DateLayouts:      args.dateLayouts,
+			},
+			APIOptions: reader.APIOptions{
+				DateRange: args.dateRange,
+			},
+			SpreadsheetOptions: reader.SpreadsheetOptions{
+				Filename:         filename,
+				Sheet:            args.sheet,
+				DecimalDelimiter: args.decimalDelimiter,
+				SkipColumnNames:  args.skipColumnNames,
+				SkipRowNames:     args.skipRowNames,
+				SkipSummaryLine:  args.skipSummaryLine,
+				DateLayouts:      args.dateLayouts,
+			},
+		}
+		fileReader, err := reader.Create(readerName(filename, args.inputFormat), options)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, reader.Source{Name: filename, Reader: fileReader})
+	}
+	if args.redmine.url != "" {
+		apiSource, err := redmineSource(args)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, apiSource)
+	}
+	return reader.NewMultiReader(sources), nil
+}
+
+// logConflicts warns about work time of the same pipeline and day in more than one source.
+func logConflicts(redmineReader reader.RedmineDataReader) {
+	reporter, ok := redmineReader.(reader.ConflictReporter)
+	if !ok {
+		return
+	}
+	for _, conflict := range reporter.Conflicts() {
+		log.Warnf("merge conflict: %s", conflict)
+	}
+}
+
+// redmineSource returns a source which reads the time entries of the date range from the Redmine REST API.
+func redmineSource(args runArgs) (reader.Source, error) {
+	redmineURL, err := url.Parse(args.redmine.url)
+	if err != nil {
+		return reader.Source{}, errors.New("invalid Redmine URL")
+	}
+
+	creds, err := credentials.Resolve(credentials.Options{
+		Host:            redmineURL.Hostname(),
+		User:            args.redmine.user,
+		PasswordCommand: args.redmine.passwordCommand,
+		NetrcFile:       args.redmine.netrcFile,
+	})
+	if err != nil {
+		return reader.Source{}, errors.Wrap(err, "could not read Redmine credentials")
+	}
+
 	options := reader.Options{
-		Type: reader.CSV,
-		CSVOptions: reader.CSVOptions{
-			Filename:         args.filename,
-			CSVDelimiter:     args.csvDelimiter,
-			DecimalDelimiter: args.decimalDelimiter,
-			SkipColumnNames:  args.skipColumnNames,
-			SkipSummaryLine:  args.skipSummaryLine,
+		APIOptions: reader.APIOptions{
+			RedmineURL:  args.redmine.url,
+			Credentials: creds,
+			UserID:      args.redmine.userID,
+			DateRange:   args.dateRange,
 		},
-		APIOptions: reader.APIOptions{},
 	}
-	redmineReader := reader.New(options)
-
-	data, err := redmineReader.Read()
+	apiReader, err := reader.Create(reader.FormatAPI, options)
 	if err != nil {
-		return nil, errors.Wrapf(err, "error while reading from %s", options.CSVOptions.Filename)
+		return reader.Source{}, err
+	}
+
+	return reader.Source{Name: redmineURL.Host, Reader: apiReader}, nil
+}
+
+// readerName returns the given input format or detects the reader by the file extension.
+func readerName(filename string, inputFormat string) string {
+	if inputFormat == "" || inputFormat == inputFormatAuto {
+		return reader.NameByFilename(filename)
 	}
 
-	return data, nil
+	return inputFormat
 }
 
 func crunch(data *core.PipelineData, args runArgs) (*core.CrunchedOutput, error) {
-	crunchConfig := cruncher.Config{
-		LunchBreakInMin: args.lunchBreakInMin,
+	crunchConfig := cruncherConfig(args)
+	if args.explain {
+		crunchConfig.Trace = cruncher.NewTrace()
+	}
+	crunch, err := cruncher.Create(args.cruncherName)
+	if err != nil {
+		return nil, err
 	}
-	crunch := cruncher.New()
 
 	crunched, err := crunch.Crunch(data, crunchConfig)
+	if errors.Is(err, core.ErrInvalidDate) {
+		return nil, errors.Wrapf(err, "error while crunching data (skip columns without dates with --%s)", flagSkipColumnsLong)
+	}
 	if err != nil {
 		return nil, errors.Wrapf(err, "error while crunching data")
 	}
 
+	if args.explain {
+		err = writeExplanation(os.Stderr, crunchConfig.Trace, args.explainFormat)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return crunched, nil
 }
+
+// cruncherConfig returns the schedule of the run arguments.
+func cruncherConfig(args runArgs) cruncher.Config {
+	return cruncher.Config{
+		LunchBreakInMin:  args.lunchBreakInMin,
+		LunchStartTime:   args.lunchStartTime,
+		PipelineOrder:    args.pipelineOrder,
+		ChunkMinutes:     args.chunkMinutes,
+		InterleaveMode:   args.interleaveMode,
+		Anchors:          args.anchors,
+		MinSlotMinutes:   args.minSlotMinutes,
+		MergeInto:        args.mergeInto,
+		ConsolidateSlots: args.consolidate,
+	}
+}
+
+// writeExplanation writes the crunch trace in the given format.
+func writeExplanation(w io.Writer, trace *cruncher.Trace, format string) error {
+	switch format {
+	case explainFormatText, "":
+		return trace.WriteText(w)
+	case explainFormatJSON:
+		return trace.WriteJSON(w)
+	default:
+		return fmt.Errorf("unsupported value '%s' for --%s (allowed: %s, %s)", format, flagExplainFormatLong,
+			explainFormatText, explainFormatJSON)
+	}
+}
@@ -0,0 +1,254 @@
+// Package redsage embeds the whole RedSage flow into other Go programs: read Redmine work time, transform the
+// pipelines, handle work time on non-working days, crunch them into Sage time slots and write them.
+//
+// Example:
+//  csvReader, err := reader.Create(reader.FormatCSV, reader.Options{CSVOptions: reader.CSVOptions{Filename: "timelog.csv"}})
+//  pipeline, err := redsage.New(redsage.Options{
+//    Reader: csvReader,
+//    CruncherConfig: cruncher.Config{LunchBreakInMin: 60},
+//  })
+//  result, err := pipeline.Run(ctx, os.Stdout)
+package redsage
+
+import (
+	"context"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/calendar"
+	"github.com/ppxl/sagemine/core"
+	"github.com/ppxl/sagemine/cruncher"
+	"github.com/ppxl/sagemine/logging"
+	"github.com/ppxl/sagemine/output"
+	"github.com/ppxl/sagemine/reader"
+	"github.com/ppxl/sagemine/transformer"
+	"io"
+)
+
+var log = logging.Logger()
+
+// Handlings of work time on weekends and holidays.
+const (
+	// NonWorkingDaysIgnore keeps the work time without notice.
+	NonWorkingDaysIgnore = "ignore"
+	// NonWorkingDaysWarn keeps the work time and logs a warning per day.
+	NonWorkingDaysWarn = "warn"
+	// NonWorkingDaysRelocate moves the work time to the previous working day.
+	NonWorkingDaysRelocate = "relocate"
+)
+
+// Options contain the stages of a pipeline. Only the reader is required.
+type Options struct {
+	// Reader reads the Redmine work time, f. i. reader.New or reader.NewMultiReader.
+	Reader reader.RedmineDataReader
+	// Steps transform the pipeline data in their order. Nil joins all pipelines like DefaultSteps, an empty slice
+	// leaves the data as they are.
+	Steps []transformer.Step
+	// Calendar knows the weekends and holidays. Nil uses a calendar without holidays.
+	Calendar *calendar.Calendar
+	// NonWorkingDays handles work time on weekends and holidays after the transformation. Empty uses
+	// NonWorkingDaysWarn.
+	NonWorkingDays string
+	// Cruncher places the work time into time slots. Nil uses cruncher.New.
+	Cruncher cruncher.Cruncher
+	// CruncherConfig modifies the crunching behaviour, f. i. the lunch break.
+	CruncherConfig cruncher.Config
+	// Writer writes the time slots. Nil uses the text format of output.New with the days of the calendar.
+	Writer output.Writer
+	// DateRange narrows the read work time before it is transformed. The zero value keeps all dates.
+	DateRange core.DateRange
+}
+
+// Result contains the data of the last two stages of a pipeline run.
+type Result struct {
+	// Data contains the transformed work time which was crunched.
+	Data *core.PipelineData
+	// Crunched contains the time slots which were written.
+	Crunched *core.CrunchedOutput
+}
+
+// Pipeline executes the stages read, transform, non-working days, crunch and write in this order.
+type Pipeline struct {
+	options Options
+}
+
+// DefaultSteps returns the transformation of the command line tool without declared steps: all pipelines are joined
+// into a single pseudo-pipeline except for the single pipelines.
+func DefaultSteps(singlePipelines []string) []transformer.Step {
+	return []transformer.Step{{
+		Name:        transformer.JoinName,
+		Transformer: transformer.New(),
+		Config:      transformer.Config{SinglePipelineNames: singlePipelines},
+	}}
+}
+
+// New creates a pipeline and fills the missing stages with their defaults.
+func New(options Options) (*Pipeline, error) {
+	if options.Reader == nil {
+		return nil, errors.New("pipeline needs a Redmine data reader")
+	}
+	if options.Steps == nil {
+		options.Steps = DefaultSteps(nil)
+	}
+	if options.Calendar == nil {
+		workCalendar, err := calendar.New("")
+		if err != nil {
+			return nil, err
+		}
+		options.Calendar = workCalendar
+	}
+	switch options.NonWorkingDays {
+	case "":
+		options.NonWorkingDays = NonWorkingDaysWarn
+	case NonWorkingDaysIgnore, NonWorkingDaysWarn, NonWorkingDaysRelocate:
+	default:
+		return nil, errors.Errorf("unsupported handling '%s' of non-working days (allowed: %s, %s, %s)",
+			options.NonWorkingDays, NonWorkingDaysIgnore, NonWorkingDaysWarn, NonWorkingDaysRelocate)
+	}
+	if options.Cruncher == nil {
+		options.Cruncher = cruncher.New()
+	}
+	if options.Writer == nil {
+		writer, err := output.New(output.FormatText, output.Options{Days: options.Calendar})
+		if err != nil {
+			return nil, err
+		}
+		options.Writer = writer
+	}
+
+	return &Pipeline{options: options}, nil
+}
+
+// Calendar returns the calendar which decides about working days, f. i. to write or report the crunched days.
+func (p *Pipeline) Calendar() *calendar.Calendar {
+	return p.options.Calendar
+}
+
+// Run executes all stages and writes the time slots. The context is checked before each stage, a running stage is
+// not interrupted.
+func (p *Pipeline) Run(ctx context.Context, w io.Writer) (*Result, error) {
+	result, err := p.Process(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.Write(ctx, w, result.Crunched)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// Process executes all stages except for writing.
+func (p *Pipeline) Process(ctx context.Context) (*Result, error) {
+	data, err := p.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = p.Transform(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err = p.HandleNonWorkingDays(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	crunched, err := p.Crunch(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{Data: data, Crunched: crunched}, nil
+}
+
+// Read reads the Redmine work time within the date range.
+func (p *Pipeline) Read(ctx context.Context) (*core.PipelineData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	data, err := p.options.Reader.Read()
+	if err != nil {
+		return nil, errors.Wrap(err, "error while reading Redmine data")
+	}
+
+	if !p.options.DateRange.IsUnbounded() {
+		data = data.FilterDates(p.options.DateRange)
+	}
+
+	return data, nil
+}
+
+// Transform executes the transformation steps in their order.
+func (p *Pipeline) Transform(ctx context.Context, data *core.PipelineData) (*core.PipelineData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	chain := &transformer.Chain{}
+	for _, step := range p.options.Steps {
+		chain.Add(step)
+	}
+
+	result, err := chain.Transform(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while transforming pipelines")
+	}
+
+	return result, nil
+}
+
+// HandleNonWorkingDays ignores, warns about or relocates work time on weekends and holidays.
+func (p *Pipeline) HandleNonWorkingDays(ctx context.Context, data *core.PipelineData) (*core.PipelineData, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	switch p.options.NonWorkingDays {
+	case NonWorkingDaysRelocate:
+		relocated, relocations, err := p.options.Calendar.Relocate(data)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while relocating work time of non-working days")
+		}
+		for _, relocation := range relocations {
+			log.Infof("moved %.2f hours from %s (%s) to %s", relocation.WorkTime, relocation.Date, relocation.Reason, relocation.TargetDate)
+		}
+		return relocated, nil
+	case NonWorkingDaysWarn:
+		for _, day := range p.options.Calendar.FindNonWorkingDays(data) {
+			log.Warnf("%.2f hours were booked on %s which is no working day (%s)", day.WorkTime, day.Date, day.Reason)
+		}
+	}
+
+	return data, nil
+}
+
+// Crunch places the work time into time slots.
+func (p *Pipeline) Crunch(ctx context.Context, data *core.PipelineData) (*core.CrunchedOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := p.options.Cruncher.Crunch(data, p.options.CruncherConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while crunching data")
+	}
+
+	return result, nil
+}
+
+// Write writes the time slots with the configured writer.
+func (p *Pipeline) Write(ctx context.Context, w io.Writer, crunched *core.CrunchedOutput) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	err := p.options.Writer.Write(w, crunched)
+	if err != nil {
+		return errors.Wrap(err, "error while writing results")
+	}
+
+	return nil
+}
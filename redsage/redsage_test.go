@@ -0,0 +1,148 @@
+package redsage
+
+import (
+	"bytes"
+	"context"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/calendar"
+	"github.com/ppxl/sagemine/core"
+	"github.com/ppxl/sagemine/cruncher"
+	"github.com/ppxl/sagemine/transformer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type dataReader struct {
+	data *core.PipelineData
+	err  error
+}
+
+func (dr *dataReader) Read() (*core.PipelineData, error) {
+	return dr.data, dr.err
+}
+
+func createPipelineData() *core.PipelineData {
+	data := core.NewPipelineData()
+	acme, _ := data.AddPipeline("ACME")
+	acme.PutWorkTime("2021-05-03", 2)
+	acme.PutWorkTime("2021-05-10", 1)
+	pipelineA, _ := data.AddPipeline("Pipeline A")
+	pipelineA.PutWorkTime("2021-05-03", 3)
+
+	return data
+}
+
+func TestPipeline_Run(t *testing.T) {
+	t.Run("should join, crunch and write the pipelines", func(t *testing.T) {
+		sut, err := New(Options{Reader: &dataReader{data: createPipelineData()}, CruncherConfig: cruncher.Config{LunchBreakInMin: 60}})
+		require.NoError(t, err)
+		buffer := &bytes.Buffer{}
+
+		// when
+		actual, err := sut.Run(context.Background(), buffer)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ACME-joined"}, actual.Crunched.SortedKeys())
+		assert.Equal(t, "ACME-joined\n2021-05-03\t08:00 - 12:00\t13:00 - 14:00\t\n2021-05-10\t08:00 - 09:00\t\n", buffer.String())
+	})
+	t.Run("should keep the pipelines without steps within the date range", func(t *testing.T) {
+		week, _ := core.ParseISOWeek("2021-W18")
+		sut, err := New(Options{Reader: &dataReader{data: createPipelineData()}, Steps: []transformer.Step{}, DateRange: week})
+		require.NoError(t, err)
+
+		// when
+		actual, err := sut.Process(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ACME", "Pipeline A"}, actual.Crunched.SortedKeys())
+		assert.Equal(t, []string{"2021-05-03"}, actual.Data.NamedDayRedmineValues["ACME"].SortedKeys())
+	})
+	t.Run("should stop at a cancelled context", func(t *testing.T) {
+		sut, err := New(Options{Reader: &dataReader{data: createPipelineData()}})
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// when
+		_, err = sut.Run(ctx, &bytes.Buffer{})
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, context.Canceled, err)
+	})
+	t.Run("should wrap errors of the stages", func(t *testing.T) {
+		sut, err := New(Options{Reader: &dataReader{err: errors.New("file not found")}})
+		require.NoError(t, err)
+
+		// when
+		_, err = sut.Run(context.Background(), &bytes.Buffer{})
+
+		// then
+		require.Error(t, err)
+		assert.Equal(t, "error while reading Redmine data: file not found", err.Error())
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Run("should fail without reader", func(t *testing.T) {
+		// when
+		_, err := New(Options{})
+
+		// then
+		require.Error(t, err)
+	})
+	t.Run("should fail for unsupported handlings of non-working days", func(t *testing.T) {
+		// when
+		_, err := New(Options{Reader: &dataReader{data: createPipelineData()}, NonWorkingDays: "shrug"})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported handling 'shrug' of non-working days")
+	})
+}
+
+func TestPipeline_HandleNonWorkingDays(t *testing.T) {
+	input := core.NewPipelineData()
+	pipelineA, _ := input.AddPipeline("Pipeline A")
+	pipelineA.PutWorkTime("2021-05-07", 4)
+	pipelineA.PutWorkTime("2021-05-08", 2)
+
+	t.Run("should keep data when warning", func(t *testing.T) {
+		sut, err := New(Options{Reader: &dataReader{data: input}})
+		require.NoError(t, err)
+
+		// when
+		actual, err := sut.HandleNonWorkingDays(context.Background(), input)
+
+		// then
+		require.NoError(t, err)
+		require.Same(t, input, actual)
+	})
+	t.Run("should relocate work time", func(t *testing.T) {
+		sut, err := New(Options{Reader: &dataReader{data: input}, NonWorkingDays: NonWorkingDaysRelocate})
+		require.NoError(t, err)
+
+		// when
+		actual, err := sut.HandleNonWorkingDays(context.Background(), input)
+
+		// then
+		require.NoError(t, err)
+		require.Equal(t, 6.0, actual.NamedDayRedmineValues["Pipeline A"].WorkTime("2021-05-07"))
+	})
+	t.Run("should relocate holidays of the calendar before crunching", func(t *testing.T) {
+		workCalendar, _ := calendar.New("")
+		_ = workCalendar.AddHoliday("2021-05-07", "company day")
+		sut, err := New(Options{Reader: &dataReader{data: input}, Calendar: workCalendar, NonWorkingDays: NonWorkingDaysRelocate})
+		require.NoError(t, err)
+
+		// when
+		actual, err := sut.Process(context.Background())
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 6.0, actual.Data.NamedDayRedmineValues["Pipeline A-joined"].WorkTime("2021-05-06"))
+	})
+}
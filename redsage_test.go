@@ -2,10 +2,24 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"flag"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/calendar"
+	"github.com/ppxl/sagemine/core"
+	"github.com/ppxl/sagemine/credentials"
+	"github.com/ppxl/sagemine/cruncher"
+	"github.com/ppxl/sagemine/locale"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v2"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func Test_doRun(t *testing.T) {
@@ -22,7 +36,7 @@ Gesamtzeit;7,50;6,00;"";4,50;18,00
 		args := runArgs{
 			lunchBreakInMin:  60,
 			singlePipelines:  []string{},
-			filename:         path,
+			filenames:        []string{path},
 			csvDelimiter:     ";",
 			decimalDelimiter: ",",
 			skipColumnNames:  []string{"Gesamtzeit"},
@@ -35,4 +49,265 @@ Gesamtzeit;7,50;6,00;"";4,50;18,00
 		// then
 		require.NoError(t, actual)
 	})
+	t.Run("should crunch only the selected week", func(t *testing.T) {
+		file, _ := ioutil.TempFile(os.TempDir(), "redmineCSV-")
+		path := file.Name()
+		defer os.Remove(path)
+		_, _ = file.WriteString(`Anforderungspipeline;2021-05-07;2021-05-10
+Pipeline A;7,50;6,00
+`)
+		week, _ := core.ParseISOWeek("2021-W19")
+		args := runArgs{
+			lunchBreakInMin:  60,
+			filenames:        []string{path},
+			csvDelimiter:     ";",
+			decimalDelimiter: ",",
+			dateRange:        week,
+		}
+
+		// when
+		actual := doRun(args)
+
+		// then
+		require.NoError(t, actual)
+	})
+	t.Run("should fail with the position of an invalid date", func(t *testing.T) {
+		file, _ := ioutil.TempFile(os.TempDir(), "redmineCSV-")
+		path := file.Name()
+		defer os.Remove(path)
+		_, _ = file.WriteString(`Anforderungspipeline;2021-05-03;2021-13-03
+Pipeline A;7,50;6,00
+`)
+		args := runArgs{
+			lunchBreakInMin:  60,
+			filenames:        []string{path},
+			csvDelimiter:     ";",
+			decimalDelimiter: ",",
+		}
+
+		// when
+		actual := doRun(args)
+
+		// then
+		require.Error(t, actual)
+		var dateError *core.DateError
+		require.True(t, errors.As(actual, &dateError))
+		assert.Equal(t, "2021-13-03", dateError.Date)
+		assert.Equal(t, path, dateError.Source)
+		assert.Equal(t, 1, dateError.Line)
+		assert.Equal(t, 3, dateError.Column)
+	})
+}
+
+func Test_createRedmineReader(t *testing.T) {
+	t.Run("should read time entries from the Redmine API with credentials of the environment", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Redmine-API-Key") != "0123456789abcdef" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"time_entries":[{"project":{"name":"Pipeline A"},"hours":7.5,"spent_on":"2021-05-03"}],"total_count":1}`))
+		}))
+		defer server.Close()
+		_ = os.Setenv(credentials.EnvAPIKey, "0123456789abcdef")
+		defer os.Unsetenv(credentials.EnvAPIKey)
+
+		// when
+		redmineReader, err := createRedmineReader(runArgs{redmine: redmineArgs{url: server.URL}})
+		require.NoError(t, err)
+		actual, err := redmineReader.Read()
+
+		// then
+		require.NoError(t, err)
+		require.Equal(t, 7.5, actual.NamedDayRedmineValues["Pipeline A"].WorkTime("2021-05-03"))
+	})
+	t.Run("should fail for the API reader without Redmine URL", func(t *testing.T) {
+		// when
+		_, err := createRedmineReader(runArgs{inputFormat: "api", filenames: []string{"timelog.csv"}})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "reader api reads no files but needs --redmine-url")
+	})
+	t.Run("should fail for unknown readers", func(t *testing.T) {
+		// when
+		_, err := createRedmineReader(runArgs{inputFormat: "pdf", filenames: []string{"timelog.pdf"}})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported reader 'pdf'")
+	})
+}
+
+func Test_crunch(t *testing.T) {
+	t.Run("should fail for unknown crunchers", func(t *testing.T) {
+		// when
+		_, err := crunch(core.NewPipelineData(), runArgs{cruncherName: "random"})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported cruncher 'random'")
+	})
+	t.Run("should pass the chunk size to the interleaved cruncher", func(t *testing.T) {
+		data := core.NewPipelineData()
+		pipelineA, _ := data.AddPipeline("Pipeline A")
+		pipelineA.PutWorkTime("2021-05-03", 1)
+		pipelineB, _ := data.AddPipeline("Pipeline B")
+		pipelineB.PutWorkTime("2021-05-03", 1)
+
+		// when
+		actual, err := crunch(data, runArgs{cruncherName: cruncher.InterleavedName, chunkMinutes: 30})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []core.TimeSlot{{Start: "08:00", End: "08:30"}, {Start: "09:00", End: "09:30"}},
+			actual.NamedDaySageValues["Pipeline A"].TimeSlots("2021-05-03"))
+	})
+}
+
+func Test_updateLedger(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "ledger-")
+	defer os.RemoveAll(dir)
+	data := core.NewPipelineData()
+	pipeline, _ := data.AddPipeline("ACME")
+	pipeline.PutWorkTime("2021-05-07", 9)
+	pipeline.PutWorkTime("2021-05-08", 1)
+	args := runArgs{lunchBreakInMin: 60, targetHours: 8, ledgerFile: filepath.Join(dir, "ledger.json")}
+	crunched, err := crunch(data, args)
+	require.NoError(t, err)
+	workCalendar, _ := calendar.New("")
+	now := time.Date(2021, time.May, 19, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should keep the balance when the same week is processed twice", func(t *testing.T) {
+		for run := 0; run < 2; run++ {
+			buffer := &bytes.Buffer{}
+
+			// when
+			err := updateLedger(buffer, data, crunched, workCalendar, args, now)
+
+			// then
+			require.NoError(t, err)
+			expected := `2021-W18: booked 10.00 h of 40.00 h, overtime -30.00 h
+overtime balance: -30.00 h over 1 weeks
+weeks not processed yet: 2021-W19
+`
+			assert.Equal(t, expected, buffer.String())
+		}
+	})
+	t.Run("should expect the target hours of all working days of the processed week", func(t *testing.T) {
+		partialWeek := core.NewPipelineData()
+		pipeline, _ := partialWeek.AddPipeline("ACME")
+		pipeline.PutWorkTime("2021-05-03", 9)
+		pipeline.PutWorkTime("2021-05-04", 7)
+		args := args
+		args.ledgerFile = filepath.Join(dir, "partial.json")
+		args.dateRange, _ = core.ParseISOWeek("2021-W18")
+		buffer := &bytes.Buffer{}
+
+		// when
+		err = updateLedger(buffer, partialWeek, nil, workCalendar, args, now)
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, buffer.String(), "2021-W18: booked 16.00 h of 40.00 h, overtime -24.00 h\n")
+	})
+}
+
+func Test_writeExplanation(t *testing.T) {
+	input := core.NewPipelineData()
+	pipelineA, _ := input.AddPipeline("Pipeline A")
+	pipelineA.PutWorkTime("2021-05-03", 4.5)
+	trace := cruncher.NewTrace()
+	_, err := cruncher.New().Crunch(input, cruncher.Config{LunchBreakInMin: 60, Trace: trace})
+	require.NoError(t, err)
+
+	t.Run("should write the trace as JSON", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := writeExplanation(buffer, trace, explainFormatJSON)
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, buffer.String(), `"kind": "split"`)
+	})
+	t.Run("should fail for unknown formats", func(t *testing.T) {
+		// when
+		err := writeExplanation(&bytes.Buffer{}, trace, "yaml")
+
+		// then
+		require.Error(t, err)
+	})
+}
+
+func Test_expandFilenames(t *testing.T) {
+	t.Run("should expand globs, keep unmatched arguments and remove duplicates", func(t *testing.T) {
+		dir, _ := ioutil.TempDir(os.TempDir(), "redsage-")
+		defer os.RemoveAll(dir)
+		weekA := filepath.Join(dir, "week-18.csv")
+		weekB := filepath.Join(dir, "week-19.csv")
+		_ = ioutil.WriteFile(weekA, []byte{}, 0600)
+		_ = ioutil.WriteFile(weekB, []byte{}, 0600)
+		missing := filepath.Join(dir, "missing.csv")
+
+		// when
+		actual, err := expandFilenames([]string{weekB, filepath.Join(dir, "week-*.csv"), missing})
+
+		// then
+		require.NoError(t, err)
+		require.Equal(t, []string{weekB, weekA, missing}, actual)
+	})
+}
+
+func Test_applyLocale(t *testing.T) {
+	t.Run("should apply preset unless options are set explicitly", func(t *testing.T) {
+		args := runArgs{locale: "de", decimalDelimiter: ".", skipColumnNames: []string{"Kommentar"}}
+
+		// when
+		err := applyLocale(&args)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, ";", args.csvDelimiter)
+		assert.Equal(t, ".", args.decimalDelimiter)
+		assert.Equal(t, []string{"Kommentar", "Gesamtzeit"}, args.skipColumnNames)
+		assert.Equal(t, []string{"Gesamtzeit"}, args.skipRowNames)
+		assert.Equal(t, ",", args.outputOptions.DecimalSeparator)
+	})
+	t.Run("should apply configured presets", func(t *testing.T) {
+		args := runArgs{locale: "de-ch", locales: []locale.Preset{{Name: "de-ch", Extends: "de", DecimalDelimiter: "."}}}
+
+		// when
+		err := applyLocale(&args)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, ";", args.csvDelimiter)
+		assert.Equal(t, ".", args.decimalDelimiter)
+	})
+}
+
+func Test_configuredOptions(t *testing.T) {
+	set := flag.NewFlagSet("test", flag.ContinueOnError)
+	set.String(flagOutputFormatLong, "text", "")
+	set.String(flagSheetLong, "", "")
+	set.Int(flagLunchBreakInMinutesLong, 60, "")
+	set.Bool(flagIgnoreSummaryLineLong, true, "")
+	require.NoError(t, set.Parse([]string{"--" + flagOutputFormatLong, "json"}))
+	cliCtx := cli.NewContext(cli.NewApp(), set, nil)
+	lunchBreakInMin := 30
+	ignoreSummaryLine := false
+
+	t.Run("should prefer set flags over configured values", func(t *testing.T) {
+		assert.Equal(t, "json", configuredString(cliCtx, flagOutputFormatLong, "csv"))
+	})
+	t.Run("should prefer configured values over flag defaults", func(t *testing.T) {
+		assert.Equal(t, "Export", configuredString(cliCtx, flagSheetLong, "Export"))
+		assert.Equal(t, 30, configuredInt(cliCtx, flagLunchBreakInMinutesLong, &lunchBreakInMin))
+		assert.False(t, configuredBool(cliCtx, flagIgnoreSummaryLineLong, &ignoreSummaryLine))
+	})
+	t.Run("should fall back to flag defaults", func(t *testing.T) {
+		assert.Equal(t, 60, configuredInt(cliCtx, flagLunchBreakInMinutesLong, nil))
+		assert.True(t, configuredBool(cliCtx, flagIgnoreSummaryLineLong, nil))
+	})
 }
@@ -0,0 +1,191 @@
+package report
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"github.com/ppxl/sagemine/output"
+	"time"
+)
+
+// Period names which group the days of a report.
+const (
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+)
+
+// DefaultTargetHoursPerDay is used if no target hours are configured.
+const DefaultTargetHoursPerDay = 8.0
+
+// Options contain configuration values that modify the report.
+type Options struct {
+	// TargetHoursPerDay contains the hours which are expected on each working day. Zero uses DefaultTargetHoursPerDay.
+	TargetHoursPerDay float64
+	// Period groups the days by PeriodWeek or PeriodMonth. Empty groups by week.
+	Period string
+	// Days marks non-working days which have no target hours (optional).
+	Days output.DayDescriber
+}
+
+// Report summarizes Redmine work time and booked Sage time slots per day and per period.
+type Report struct {
+	Pipelines []string        `json:"pipelines"`
+	Days      []DaySummary    `json:"days"`
+	Periods   []PeriodSummary `json:"periods"`
+	Total     PeriodSummary   `json:"total"`
+}
+
+// DaySummary contains the totals of a single date.
+type DaySummary struct {
+	Date string `json:"date"`
+	// Note explains why the date is no working day.
+	Note  string             `json:"note,omitempty"`
+	Hours map[string]float64 `json:"hours"`
+	Total float64            `json:"total"`
+	// Booked contains the hours of the crunched time slots which may differ from Total by rounding.
+	Booked   float64 `json:"booked"`
+	Target   float64 `json:"target"`
+	Overtime float64 `json:"overtime"`
+}
+
+// PeriodSummary contains the totals of a week, a month or the whole report.
+type PeriodSummary struct {
+	Name     string             `json:"name"`
+	From     string             `json:"from"`
+	To       string             `json:"to"`
+	Hours    map[string]float64 `json:"hours"`
+	Total    float64            `json:"total"`
+	Booked   float64            `json:"booked"`
+	Target   float64            `json:"target"`
+	Overtime float64            `json:"overtime"`
+	// Shares contains the percentage of each pipeline of the total hours.
+	Shares map[string]float64 `json:"shares"`
+}
+
+// New summarizes the work time per day and per period. Target hours apply to all working days which are part of the
+// data. Crunched may be nil if no time slots were booked.
+func New(pdata *core.PipelineData, crunched *core.CrunchedOutput, options Options) (*Report, error) {
+	if options.TargetHoursPerDay == 0 {
+		options.TargetHoursPerDay = DefaultTargetHoursPerDay
+	}
+	if options.TargetHoursPerDay < 0 {
+		return nil, errors.Errorf("target hours per day must not be negative: %.2f", options.TargetHoursPerDay)
+	}
+
+	periodOf, err := periodFunc(options.Period)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Report{Pipelines: pdata.SortedKeys(), Days: []DaySummary{}, Periods: []PeriodSummary{}}
+	result.Total = PeriodSummary{Name: "Total", Hours: map[string]float64{}}
+
+	for _, date := range pdata.SortedDates() {
+		day, err := summarizeDay(pdata, crunched, date, options)
+		if err != nil {
+			return nil, err
+		}
+		result.Days = append(result.Days, day)
+
+		name, err := periodOf(date)
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Periods) == 0 || result.Periods[len(result.Periods)-1].Name != name {
+			result.Periods = append(result.Periods, PeriodSummary{Name: name, From: date, Hours: map[string]float64{}})
+		}
+		result.Periods[len(result.Periods)-1].add(day)
+		result.Total.add(day)
+	}
+
+	for index := range result.Periods {
+		result.Periods[index].computeShares()
+	}
+	result.Total.computeShares()
+
+	return result, nil
+}
+
+func summarizeDay(pdata *core.PipelineData, crunched *core.CrunchedOutput, date string, options Options) (DaySummary, error) {
+	result := DaySummary{Date: date, Hours: map[string]float64{}, Target: options.TargetHoursPerDay}
+
+	if options.Days != nil {
+		if reason, nonWorking := options.Days.Describe(date); nonWorking {
+			result.Note = reason
+			result.Target = 0
+		}
+	}
+
+	for _, pipeline := range pdata.SortedKeys() {
+		hours := pdata.NamedDayRedmineValues[core.PipelineName(pipeline)].WorkTime(date)
+		result.Hours[pipeline] = hours
+		result.Total += hours
+	}
+
+	if crunched != nil {
+		for _, pipeline := range crunched.SortedKeys() {
+			for _, slot := range crunched.NamedDaySageValues[core.PipelineName(pipeline)].TimeSlots(date) {
+				if slot.IsEmpty() {
+					continue
+				}
+				duration, err := slot.Duration()
+				if err != nil {
+					return DaySummary{}, errors.Wrapf(err, "could not summarize %s of %s", pipeline, date)
+				}
+				result.Booked += duration.Hours()
+			}
+		}
+	}
+
+	result.Overtime = result.Total - result.Target
+	return result, nil
+}
+
+func (ps *PeriodSummary) add(day DaySummary) {
+	if ps.From == "" {
+		ps.From = day.Date
+	}
+	ps.To = day.Date
+
+	for pipeline, hours := range day.Hours {
+		ps.Hours[pipeline] += hours
+	}
+	ps.Total += day.Total
+	ps.Booked += day.Booked
+	ps.Target += day.Target
+	ps.Overtime = ps.Total - ps.Target
+}
+
+func (ps *PeriodSummary) computeShares() {
+	ps.Shares = map[string]float64{}
+	for pipeline, hours := range ps.Hours {
+		if ps.Total == 0 {
+			ps.Shares[pipeline] = 0
+			continue
+		}
+		ps.Shares[pipeline] = hours / ps.Total * 100
+	}
+}
+
+// periodFunc returns a function which names the period of a date, f. i. "2021-W18" or "2021-05".
+func periodFunc(period string) (func(date string) (string, error), error) {
+	switch period {
+	case PeriodWeek, "":
+		return func(date string) (string, error) {
+			parsed, err := time.Parse(core.DateLayout, date)
+			if err != nil {
+				return "", errors.Wrapf(err, "invalid date '%s'", date)
+			}
+			return core.FormatISOWeek(parsed), nil
+		}, nil
+	case PeriodMonth:
+		return func(date string) (string, error) {
+			parsed, err := time.Parse(core.DateLayout, date)
+			if err != nil {
+				return "", errors.Wrapf(err, "invalid date '%s'", date)
+			}
+			return parsed.Format("2006-01"), nil
+		}, nil
+	default:
+		return nil, errors.Errorf("unsupported report period '%s' (supported periods: %s, %s)", period, PeriodWeek, PeriodMonth)
+	}
+}
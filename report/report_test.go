@@ -0,0 +1,89 @@
+package report
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const (
+	acme      = "ACME"
+	pipelineA = "Pipeline A"
+)
+
+type weekendDescriber struct{}
+
+func (wd weekendDescriber) Describe(date string) (string, bool) {
+	if date == "2021-05-08" {
+		return "Saturday", true
+	}
+	return "", false
+}
+
+func createReportInput() (*core.PipelineData, *core.CrunchedOutput) {
+	pdata := core.NewPipelineData()
+	acmePipeline, _ := pdata.AddPipeline(acme)
+	acmePipeline.PutWorkTime("2021-05-07", 2)
+	acmePipeline.PutWorkTime("2021-05-08", 1)
+	pipeline, _ := pdata.AddPipeline(pipelineA)
+	pipeline.PutWorkTime("2021-05-07", 7)
+	pipeline.PutWorkTime("2021-05-10", 6)
+
+	crunched := core.NewCrunchedOutput()
+	crunchedA, _ := crunched.AddPipeline(pipelineA)
+	crunchedA.PutTimeSlot("2021-05-07", "08:00", "12:00")
+	crunchedA.PutTimeSlot("2021-05-07", "13:00", "15:59")
+	crunchedA.PutEmptyTimeSlot("2021-05-08")
+
+	return pdata, crunched
+}
+
+func TestNew(t *testing.T) {
+	pdata, crunched := createReportInput()
+
+	t.Run("should summarize days and weeks", func(t *testing.T) {
+		// when
+		actual, err := New(pdata, crunched, Options{Days: weekendDescriber{}})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{acme, pipelineA}, actual.Pipelines)
+		require.Len(t, actual.Days, 3)
+		assert.Equal(t, DaySummary{Date: "2021-05-07", Hours: map[string]float64{acme: 2, pipelineA: 7}, Total: 9,
+			Booked: 4 + 2 + 59.0/60, Target: 8, Overtime: 1}, actual.Days[0])
+		assert.Equal(t, "Saturday", actual.Days[1].Note)
+		assert.Equal(t, 0.0, actual.Days[1].Target)
+
+		require.Len(t, actual.Periods, 2)
+		assert.Equal(t, "2021-W18", actual.Periods[0].Name)
+		assert.Equal(t, "2021-05-07", actual.Periods[0].From)
+		assert.Equal(t, "2021-05-08", actual.Periods[0].To)
+		assert.Equal(t, 10.0, actual.Periods[0].Total)
+		assert.Equal(t, 2.0, actual.Periods[0].Overtime)
+		assert.InDelta(t, 30.0, actual.Periods[0].Shares[acme], 0.001)
+		assert.Equal(t, "2021-W19", actual.Periods[1].Name)
+
+		assert.Equal(t, 16.0, actual.Total.Total)
+		assert.Equal(t, 16.0, actual.Total.Target)
+		assert.Equal(t, 0.0, actual.Total.Overtime)
+	})
+	t.Run("should summarize months with custom target hours", func(t *testing.T) {
+		// when
+		actual, err := New(pdata, nil, Options{Period: PeriodMonth, TargetHoursPerDay: 6})
+
+		// then
+		require.NoError(t, err)
+		require.Len(t, actual.Periods, 1)
+		assert.Equal(t, "2021-05", actual.Periods[0].Name)
+		assert.Equal(t, 18.0, actual.Periods[0].Target)
+		assert.Equal(t, 0.0, actual.Periods[0].Booked)
+	})
+	t.Run("should fail for unknown periods", func(t *testing.T) {
+		// when
+		_, err := New(pdata, crunched, Options{Period: "year"})
+
+		// then
+		require.Error(t, err)
+	})
+}
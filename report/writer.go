@@ -0,0 +1,197 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/output"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Report format names.
+const (
+	FormatText     = "text"
+	FormatCSV      = "csv"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+const (
+	defaultDecimalSeparator = "."
+	defaultCSVDelimiter     = ";"
+)
+
+// WriteOptions contain configuration values that modify the written report.
+type WriteOptions struct {
+	// DecimalSeparator separates the decimals of hours, f. i. ",". Defaults to ".".
+	DecimalSeparator string
+	// CSVDelimiter separates the columns of the CSV format. Defaults to ";".
+	CSVDelimiter string
+}
+
+// table is a titled grid of cells which all formats except JSON render.
+type table struct {
+	title  string
+	header []string
+	rows   [][]string
+}
+
+// Formats returns all supported report format names.
+func Formats() []string {
+	return []string{FormatText, FormatCSV, FormatJSON, FormatMarkdown}
+}
+
+// Write writes the report in the given format. All formats except JSON contain three tables: the days, the periods
+// and the percentage split of the pipelines per period.
+func Write(w io.Writer, report *Report, format string, options WriteOptions) error {
+	if options.DecimalSeparator == "" {
+		options.DecimalSeparator = defaultDecimalSeparator
+	}
+	if options.CSVDelimiter == "" {
+		options.CSVDelimiter = defaultCSVDelimiter
+	}
+
+	var err error
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		err = writeText(w, report.tables(options.DecimalSeparator))
+	case FormatCSV:
+		err = writeCSV(w, report.tables(options.DecimalSeparator), options.CSVDelimiter)
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	case FormatMarkdown:
+		err = writeMarkdown(w, report.tables(options.DecimalSeparator))
+	default:
+		return errors.Errorf("unsupported report format '%s' (supported formats: %s)", format, strings.Join(Formats(), ", "))
+	}
+	if err != nil {
+		return errors.Wrap(err, "error while writing report")
+	}
+
+	return nil
+}
+
+func (r *Report) tables(decimalSeparator string) []table {
+	hours := func(value float64) string {
+		return output.FormatHours(value, decimalSeparator)
+	}
+	share := func(value float64) string {
+		return strings.Replace(fmt.Sprintf("%.1f", value), ".", decimalSeparator, 1)
+	}
+
+	days := table{title: "Days", header: append(append([]string{"Date"}, r.Pipelines...), "Total", "Booked", "Target", "Overtime", "Note")}
+	for _, day := range r.Days {
+		row := []string{day.Date}
+		for _, pipeline := range r.Pipelines {
+			row = append(row, hours(day.Hours[pipeline]))
+		}
+		days.rows = append(days.rows, append(row, hours(day.Total), hours(day.Booked), hours(day.Target), hours(day.Overtime), day.Note))
+	}
+
+	periods := table{title: "Periods", header: append(append([]string{"Period"}, r.Pipelines...), "Total", "Booked", "Target", "Overtime")}
+	shares := table{title: "Shares (%)", header: append([]string{"Period"}, r.Pipelines...)}
+	for _, period := range append(append([]PeriodSummary{}, r.Periods...), r.Total) {
+		periodRow := []string{period.Name}
+		shareRow := []string{period.Name}
+		for _, pipeline := range r.Pipelines {
+			periodRow = append(periodRow, hours(period.Hours[pipeline]))
+			shareRow = append(shareRow, share(period.Shares[pipeline]))
+		}
+		periods.rows = append(periods.rows, append(periodRow, hours(period.Total), hours(period.Booked), hours(period.Target), hours(period.Overtime)))
+		shares.rows = append(shares.rows, shareRow)
+	}
+
+	return []table{days, periods, shares}
+}
+
+func writeText(w io.Writer, tables []table) error {
+	for index, t := range tables {
+		if index > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, t.title); err != nil {
+			return err
+		}
+
+		// empty trailing cells must stay tab-terminated to keep the columns aligned, their padding is trimmed afterwards
+		buffer := &bytes.Buffer{}
+		writer := tabwriter.NewWriter(buffer, 0, 0, 2, ' ', 0)
+		for _, row := range append([][]string{t.header}, t.rows...) {
+			if _, err := fmt.Fprintln(writer, strings.Join(row, "\t")); err != nil {
+				return err
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			return err
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n") {
+			if _, err := fmt.Fprintln(w, strings.TrimRight(line, " ")); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// writeCSV writes the tables one after another. Each table starts with its header row and ends with an empty line.
+func writeCSV(w io.Writer, tables []table, csvDelimiter string) error {
+	delimiter := []rune(csvDelimiter)
+	if len(delimiter) != 1 {
+		return errors.Errorf("CSV delimiter must be a single character: '%s'", csvDelimiter)
+	}
+
+	for index, t := range tables {
+		if index > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+
+		writer := csv.NewWriter(w)
+		writer.Comma = delimiter[0]
+		err := writer.WriteAll(append([][]string{t.header}, t.rows...))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeMarkdown(w io.Writer, tables []table) error {
+	for index, t := range tables {
+		if index > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "### %s\n\n", t.title); err != nil {
+			return err
+		}
+
+		separator := make([]string, len(t.header))
+		for column := range separator {
+			separator[column] = "---"
+		}
+		for _, row := range append([][]string{t.header, separator}, t.rows...) {
+			escaped := make([]string, len(row))
+			for column, cell := range row {
+				escaped[column] = strings.Replace(cell, "|", "\\|", -1)
+			}
+			if _, err := fmt.Fprintf(w, "| %s |\n", strings.Join(escaped, " | ")); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
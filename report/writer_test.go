@@ -0,0 +1,65 @@
+package report
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	pdata, crunched := createReportInput()
+	sut, err := New(pdata, crunched, Options{Days: weekendDescriber{}})
+	require.NoError(t, err)
+
+	t.Run("should write text tables", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := Write(buffer, sut, FormatText, WriteOptions{})
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, buffer.String(), "Days\nDate        ACME  Pipeline A  Total  Booked  Target  Overtime  Note\n")
+		assert.Contains(t, buffer.String(), "2021-05-08  1.00  0.00        1.00   0.00    0.00    1.00      Saturday\n")
+		assert.Contains(t, buffer.String(), "Shares (%)\nPeriod    ACME  Pipeline A\n2021-W18  30.0  70.0\n")
+	})
+	t.Run("should write CSV with the decimal separator", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := Write(buffer, sut, FormatCSV, WriteOptions{DecimalSeparator: ","})
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, buffer.String(), "Period;ACME;Pipeline A;Total;Booked;Target;Overtime\n2021-W18;3,00;7,00;10,00;6,98;8,00;2,00\n")
+		assert.Contains(t, buffer.String(), "Total;3,00;13,00;16,00;6,98;16,00;0,00\n")
+	})
+	t.Run("should write Markdown", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := Write(buffer, sut, FormatMarkdown, WriteOptions{})
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, buffer.String(), "### Periods\n\n| Period | ACME | Pipeline A | Total | Booked | Target | Overtime |\n| --- | --- | --- | --- | --- | --- | --- |\n")
+	})
+	t.Run("should write JSON", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := Write(buffer, sut, FormatJSON, WriteOptions{})
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, buffer.String(), `"name": "2021-W19"`)
+	})
+	t.Run("should fail for unknown formats", func(t *testing.T) {
+		// when
+		err := Write(&bytes.Buffer{}, sut, "pdf", WriteOptions{})
+
+		// then
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"github.com/ppxl/sagemine/config"
+	"github.com/ppxl/sagemine/report"
+	"github.com/urfave/cli/v2"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	flagPeriodLong       = "period"
+	flagReportFormatLong = "report-format"
+)
+
+// reportArgs contains the options of the summary report.
+type reportArgs struct {
+	period string
+	format string
+}
+
+func reportCommand() *cli.Command {
+	flags := append(runFlags(),
+		&cli.StringFlag{
+			Name:  flagPeriodLong,
+			Usage: fmt.Sprintf("Sum up the days per %s or %s (optional)", report.PeriodWeek, report.PeriodMonth),
+			Value: report.PeriodWeek,
+		},
+		&cli.StringFlag{
+			Name:  flagReportFormatLong,
+			Usage: "Set the report format: " + strings.Join(report.Formats(), ", ") + " (optional)",
+			Value: report.FormatText,
+		},
+	)
+
+	return &cli.Command{
+		Name:      "report",
+		Usage:     "summarize the work time per day, per week or month, and the overtime against the target hours",
+		Action:    doCliReport,
+		ArgsUsage: runArgsUsage,
+		Flags:     flags,
+	}
+}
+
+func doCliReport(cliCtx *cli.Context) error {
+	args, err := buildRunArgs(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load(cliCtx.String(flagGlobalConfigLong))
+	if err != nil {
+		return err
+	}
+	reportOptions := reportArgs{
+		period: configuredString(cliCtx, flagPeriodLong, cfg.Report.Period),
+		format: configuredString(cliCtx, flagReportFormatLong, cfg.Report.Format),
+	}
+
+	return doReport(os.Stdout, args, reportOptions)
+}
+
+// doReport crunches the Redmine data like the run command and writes the summary instead of the time slots.
+func doReport(w io.Writer, args runArgs, reportOptions reportArgs) error {
+	pipeline, result, err := processRedmineData(args)
+	if err != nil {
+		return err
+	}
+
+	summary, err := report.New(result.Data, result.Crunched, report.Options{
+		TargetHoursPerDay: args.targetHours,
+		Period:            reportOptions.period,
+		Days:              pipeline.Calendar(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return report.Write(w, summary, reportOptions.format, report.WriteOptions{
+		DecimalSeparator: args.outputOptions.DecimalSeparator,
+		CSVDelimiter:     args.outputOptions.CSVDelimiter,
+	})
+}
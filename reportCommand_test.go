@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func Test_doReport(t *testing.T) {
+	file, _ := ioutil.TempFile(os.TempDir(), "redmineCSV-")
+	path := file.Name()
+	defer os.Remove(path)
+	_, _ = file.WriteString(`Anforderungspipeline;2021-05-07;2021-05-10
+Pipeline A;7,50;6,00
+Pipeline B;1,50;0,00
+`)
+	args := runArgs{
+		lunchBreakInMin:  60,
+		filenames:        []string{path},
+		csvDelimiter:     ";",
+		decimalDelimiter: ",",
+	}
+
+	t.Run("should summarize the weeks", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := doReport(buffer, args, reportArgs{period: "week", format: "csv"})
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, buffer.String(), "Period;Pipeline A-joined;Total;Booked;Target;Overtime\n")
+		assert.Contains(t, buffer.String(), "2021-W18;9.00;9.00;9.00;8.00;1.00\n")
+		assert.Contains(t, buffer.String(), "Total;15.00;15.00;15.00;16.00;-1.00\n")
+	})
+	t.Run("should fail for unknown periods", func(t *testing.T) {
+		// when
+		err := doReport(&bytes.Buffer{}, args, reportArgs{period: "year"})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported report period 'year'")
+	})
+}
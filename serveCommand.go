@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/reader"
+	"github.com/ppxl/sagemine/server"
+	"github.com/urfave/cli/v2"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+)
+
+const (
+	flagListenLong  = "listen"
+	flagMaxBodyLong = "max-body"
+	flagTimeoutLong = "timeout"
+)
+
+// shutdownTimeout limits waiting for running requests when the server stops.
+const shutdownTimeout = 10 * time.Second
+
+func serveCommand() *cli.Command {
+	flags := append(runFlags(),
+		&cli.StringFlag{
+			Name:  flagListenLong,
+			Usage: "address which the HTTP API listens on (optional)",
+			Value: server.DefaultAddress,
+		},
+		&cli.Int64Flag{
+			Name:  flagMaxBodyLong,
+			Usage: "largest request body in bytes (optional)",
+			Value: server.DefaultMaxBodyBytes,
+		},
+		&cli.DurationFlag{
+			Name:  flagTimeoutLong,
+			Usage: "longest time to read, crunch and answer a request (optional)",
+			Value: server.DefaultTimeout,
+		},
+	)
+
+	return &cli.Command{
+		Name: "serve",
+		Usage: "crunch Redmine CSV exports or JSON hours which are posted to an HTTP API, the other flags set the " +
+			"defaults of all requests",
+		Action: doCliServe,
+		Flags:  flags,
+	}
+}
+
+func doCliServe(cliCtx *cli.Context) error {
+	args, err := buildArgs(cliCtx, false)
+	if err != nil {
+		return err
+	}
+	if cliCtx.Int64(flagMaxBodyLong) <= 0 || cliCtx.Duration(flagTimeoutLong) <= 0 {
+		return errors.Errorf("--%s and --%s must be positive", flagMaxBodyLong, flagTimeoutLong)
+	}
+
+	options, err := serverOptions(args)
+	if err != nil {
+		return err
+	}
+	options.MaxBodyBytes = cliCtx.Int64(flagMaxBodyLong)
+	options.Timeout = cliCtx.Duration(flagTimeoutLong)
+
+	stop := make(chan struct{})
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	go func() {
+		<-interrupt
+		close(stop)
+	}()
+
+	_, _ = fmt.Fprintf(os.Stdout, "serving the RedSage API on http://%s\n", cliCtx.String(flagListenLong))
+	return doServe(server.New(cliCtx.String(flagListenLong), options), stop)
+}
+
+// serverOptions returns the run arguments as the defaults of all requests. Invalid transformation steps and
+// calendars fail before the server starts.
+func serverOptions(args runArgs) (server.Options, error) {
+	_, err := createTransformSteps(args)
+	if err != nil {
+		return server.Options{}, err
+	}
+	workCalendar, err := createCalendar(args)
+	if err != nil {
+		return server.Options{}, err
+	}
+
+	return server.Options{
+		Settings: server.Settings{
+			CruncherName:      args.cruncherName,
+			CruncherConfig:    cruncherConfig(args),
+			SinglePipelines:   args.singlePipelines,
+			Steps:             args.transformSteps,
+			Calendar:          workCalendar,
+			NonWorkingDays:    args.nonWorkingDays,
+			TargetHoursPerDay: args.targetHours,
+			CSVOptions: reader.CSVOptions{
+				Encoding:         args.encoding,
+				CSVDelimiter:     args.csvDelimiter,
+				DecimalDelimiter: args.decimalDelimiter,
+				SkipColumnNames:  args.skipColumnNames,
+				SkipRowNames:     args.skipRowNames,
+				SkipSummaryLine:  args.skipSummaryLine,
+				DateLayouts:      args.dateLayouts,
+			},
+			DateRange:     args.dateRange,
+			OutputOptions: args.outputOptions,
+		},
+	}, nil
+}
+
+// doServe answers requests until stop is closed and then waits for the running requests.
+func doServe(srv *http.Server, stop <-chan struct{}) error {
+	failed := make(chan error, 1)
+	go func() {
+		failed <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-failed:
+		return errors.Wrapf(err, "could not serve on %s", srv.Addr)
+	case <-stop:
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		return srv.Shutdown(ctx)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/ppxl/sagemine/redsage"
+	"github.com/ppxl/sagemine/server"
+	"github.com/ppxl/sagemine/transformer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func Test_serverOptions(t *testing.T) {
+	t.Run("should use the run arguments as defaults of all requests", func(t *testing.T) {
+		week, _ := core.ParseISOWeek("2021-W18")
+		args := runArgs{
+			lunchBreakInMin:  45,
+			singlePipelines:  []string{"ACME"},
+			decimalDelimiter: ",",
+			dateRange:        week,
+			cruncherName:     "interleaved",
+			minSlotMinutes:   15,
+			targetHours:      7,
+			holidayState:     "BY",
+			nonWorkingDays:   redsage.NonWorkingDaysRelocate,
+			transformSteps:   []transformer.StepDefinition{{Name: transformer.JoinName}},
+		}
+
+		// when
+		actual, err := serverOptions(args)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 45, actual.Settings.CruncherConfig.LunchBreakInMin)
+		assert.Equal(t, 15, actual.Settings.CruncherConfig.MinSlotMinutes)
+		assert.Equal(t, "interleaved", actual.Settings.CruncherName)
+		assert.Equal(t, []string{"ACME"}, actual.Settings.SinglePipelines)
+		assert.Equal(t, ",", actual.Settings.CSVOptions.DecimalDelimiter)
+		assert.Equal(t, week, actual.Settings.DateRange)
+		assert.Equal(t, 7.0, actual.Settings.TargetHoursPerDay)
+		assert.Equal(t, args.transformSteps, actual.Settings.Steps)
+		assert.Equal(t, redsage.NonWorkingDaysRelocate, actual.Settings.NonWorkingDays)
+		holiday, _ := actual.Settings.Calendar.Describe("2021-06-03")
+		assert.Equal(t, "Fronleichnam", holiday)
+	})
+	t.Run("should fail for unknown transformers", func(t *testing.T) {
+		// when
+		_, err := serverOptions(runArgs{transformSteps: []transformer.StepDefinition{{Name: "shrug"}}})
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown transformer 'shrug'")
+	})
+}
+
+func Test_doServe(t *testing.T) {
+	t.Run("should stop serving", func(t *testing.T) {
+		stop := make(chan struct{})
+		done := make(chan error)
+		go func() {
+			done <- doServe(server.New("127.0.0.1:0", server.Options{}), stop)
+		}()
+
+		// when
+		time.Sleep(20 * time.Millisecond)
+		close(stop)
+
+		// then
+		require.NoError(t, <-done)
+	})
+	t.Run("should fail for invalid addresses", func(t *testing.T) {
+		// when
+		err := doServe(&http.Server{Addr: "127.0.0.1:invalid"}, nil)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "could not serve on 127.0.0.1:invalid")
+	})
+}
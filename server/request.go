@@ -0,0 +1,246 @@
+package server
+
+import (
+	"encoding/json"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/calendar"
+	"github.com/ppxl/sagemine/core"
+	"github.com/ppxl/sagemine/cruncher"
+	"github.com/ppxl/sagemine/output"
+	"github.com/ppxl/sagemine/reader"
+	"github.com/ppxl/sagemine/transformer"
+	"mime"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Query parameters which override the settings of a single request. They are named like the flags of the run command.
+const (
+	paramLunchBreak       = "break"
+	paramLunchStart       = "lunch-start"
+	paramCruncher         = "cruncher"
+	paramSinglePipeline   = "pipeline-single"
+	paramMinSlot          = "min-slot"
+	paramTargetHours      = "target-hours"
+	paramCSVDelimiter     = "csv-column-delimiter"
+	paramDecimalDelimiter = "decimal-delimiter"
+	paramSkipColumn       = "skip-column"
+	paramIgnoreSummary    = "ignore-summary-line"
+	paramFrom             = "from"
+	paramTo               = "to"
+	paramWeek             = "week"
+	paramMonth            = "month"
+	paramFormat           = "format"
+	paramPeriod           = "period"
+)
+
+const (
+	contentTypeJSON = "application/json"
+	// requestSource names the request body in reader errors.
+	requestSource = "request body"
+)
+
+// Settings contain the defaults of all requests.
+type Settings struct {
+	// CruncherName selects a registered cruncher. Empty selects the sequential cruncher.
+	CruncherName   string
+	CruncherConfig cruncher.Config
+	// SinglePipelines are not joined with the other pipelines if no steps are declared.
+	SinglePipelines []string
+	// Steps declare the transformation of the pipelines. Empty joins all pipelines except for the single pipelines.
+	Steps []transformer.StepDefinition
+	// Calendar knows the weekends and holidays. Nil uses a calendar without holidays.
+	Calendar *calendar.Calendar
+	// NonWorkingDays handles work time on weekends and holidays, f. i. redsage.NonWorkingDaysRelocate.
+	NonWorkingDays    string
+	TargetHoursPerDay float64
+	// CSVOptions configure reading CSV request bodies. Filename and Content are set per request.
+	CSVOptions reader.CSVOptions
+	DateRange  core.DateRange
+	// OutputOptions format the time slots and reports, f. i. the decimal separator.
+	OutputOptions output.Options
+}
+
+// requestSettings returns the settings with the query parameters of the request applied.
+func requestSettings(defaults Settings, query url.Values) (Settings, error) {
+	result := defaults
+	// a trace would be shared by concurrent requests
+	result.CruncherConfig.Trace = nil
+
+	var err error
+	result.CruncherConfig.LunchBreakInMin, err = intParam(query, paramLunchBreak, defaults.CruncherConfig.LunchBreakInMin)
+	if err != nil {
+		return Settings{}, err
+	}
+	result.CruncherConfig.MinSlotMinutes, err = intParam(query, paramMinSlot, defaults.CruncherConfig.MinSlotMinutes)
+	if err != nil {
+		return Settings{}, err
+	}
+	result.TargetHoursPerDay, err = floatParam(query, paramTargetHours, defaults.TargetHoursPerDay)
+	if err != nil {
+		return Settings{}, err
+	}
+	result.CSVOptions.SkipSummaryLine, err = boolParam(query, paramIgnoreSummary, defaults.CSVOptions.SkipSummaryLine)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	result.CruncherConfig.LunchStartTime = stringParam(query, paramLunchStart, defaults.CruncherConfig.LunchStartTime)
+	result.CruncherName = stringParam(query, paramCruncher, defaults.CruncherName)
+	result.CSVOptions.CSVDelimiter = stringParam(query, paramCSVDelimiter, defaults.CSVOptions.CSVDelimiter)
+	result.CSVOptions.DecimalDelimiter = stringParam(query, paramDecimalDelimiter, defaults.CSVOptions.DecimalDelimiter)
+	if pipelines, ok := query[paramSinglePipeline]; ok {
+		result.SinglePipelines = pipelines
+	}
+	if columns, ok := query[paramSkipColumn]; ok {
+		result.CSVOptions.SkipColumnNames = columns
+	}
+
+	result.DateRange, err = dateRangeParam(query, defaults.DateRange)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	return result, nil
+}
+
+func stringParam(query url.Values, name string, defaultValue string) string {
+	if _, ok := query[name]; !ok {
+		return defaultValue
+	}
+
+	return query.Get(name)
+}
+
+func intParam(query url.Values, name string, defaultValue int) (int, error) {
+	if _, ok := query[name]; !ok {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.Atoi(query.Get(name))
+	if err != nil || value < 0 {
+		return 0, errors.Errorf("invalid parameter %s '%s' (expected a whole number of zero or more)", name, query.Get(name))
+	}
+
+	return value, nil
+}
+
+func floatParam(query url.Values, name string, defaultValue float64) (float64, error) {
+	if _, ok := query[name]; !ok {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.ParseFloat(query.Get(name), 64)
+	if err != nil {
+		return 0, errors.Errorf("invalid parameter %s '%s' (expected a number)", name, query.Get(name))
+	}
+
+	return value, nil
+}
+
+func boolParam(query url.Values, name string, defaultValue bool) (bool, error) {
+	if _, ok := query[name]; !ok {
+		return defaultValue, nil
+	}
+	if query.Get(name) == "" {
+		return true, nil
+	}
+
+	value, err := strconv.ParseBool(query.Get(name))
+	if err != nil {
+		return false, errors.Errorf("invalid parameter %s '%s' (expected true or false)", name, query.Get(name))
+	}
+
+	return value, nil
+}
+
+// dateRangeParam returns the date range of either week, month or from and to.
+func dateRangeParam(query url.Values, defaultValue core.DateRange) (core.DateRange, error) {
+	from, to := query.Get(paramFrom), query.Get(paramTo)
+	week, month := query.Get(paramWeek), query.Get(paramMonth)
+
+	selected := 0
+	for _, value := range []string{week, month, from + to} {
+		if value != "" {
+			selected++
+		}
+	}
+	if selected > 1 {
+		return core.DateRange{}, errors.Errorf("only one of %s, %s or %s/%s may be used", paramWeek, paramMonth, paramFrom, paramTo)
+	}
+
+	switch {
+	case week != "":
+		return core.ParseISOWeek(week)
+	case month != "":
+		return core.ParseMonth(month)
+	case from != "" || to != "":
+		return core.NewDateRange(from, to)
+	default:
+		return defaultValue, nil
+	}
+}
+
+// isJSON returns true if the request body is JSON instead of CSV.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == contentTypeJSON
+}
+
+// jsonDataReader reads the hours per pipeline and day from a JSON object.
+//
+// Example:
+//  {"Pipeline A": {"2021-05-03": 7.5, "2021-05-04": 6}}
+type jsonDataReader struct {
+	content []byte
+}
+
+func (jr *jsonDataReader) Read() (*core.PipelineData, error) {
+	document := map[string]map[string]float64{}
+	err := json.Unmarshal(jr.content, &document)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse JSON %s (expected hours per pipeline and date)", requestSource)
+	}
+
+	result := core.NewPipelineData()
+	for name, days := range document {
+		workPerDay, err := result.AddPipeline(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read JSON %s", requestSource)
+		}
+		for date, hours := range days {
+			if _, err := time.Parse(core.DateLayout, date); err != nil {
+				return nil, &core.DateError{Date: date, Source: requestSource, Err: err}
+			}
+			workPerDay.PutWorkTime(date, hours)
+		}
+	}
+
+	return result, nil
+}
+
+// checkedReader rejects negative hours because they cannot be placed into time slots. Days with more hours than fit
+// until midnight are rejected by the cruncher.
+type checkedReader struct {
+	reader reader.RedmineDataReader
+}
+
+func (cr *checkedReader) Read() (*core.PipelineData, error) {
+	data, err := cr.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range data.SortedKeys() {
+		workPerDay := data.NamedDayRedmineValues[core.PipelineName(name)]
+		for _, date := range workPerDay.SortedKeys() {
+			hours := workPerDay.WorkTime(date)
+			if hours < 0 {
+				return nil, errors.Errorf("negative work time of %.2f hours for %s on %s in %s", hours, name, date, requestSource)
+			}
+		}
+	}
+
+	return data, nil
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"github.com/ppxl/sagemine/cruncher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"testing"
+)
+
+func Test_requestSettings(t *testing.T) {
+	defaults := Settings{
+		CruncherConfig:    cruncher.Config{LunchBreakInMin: 60, LunchStartTime: "12:00", Trace: cruncher.NewTrace()},
+		SinglePipelines:   []string{"ACME"},
+		TargetHoursPerDay: 8,
+	}
+
+	t.Run("should keep the defaults without query parameters", func(t *testing.T) {
+		// when
+		actual, err := requestSettings(defaults, url.Values{})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 60, actual.CruncherConfig.LunchBreakInMin)
+		assert.Equal(t, []string{"ACME"}, actual.SinglePipelines)
+		assert.Nil(t, actual.CruncherConfig.Trace)
+	})
+	t.Run("should apply the query parameters", func(t *testing.T) {
+		query, _ := url.ParseQuery("break=30&lunch-start=12:30&pipeline-single=Internal&pipeline-single=Support" +
+			"&target-hours=6.5&ignore-summary-line&skip-column=Gesamtzeit&from=2021-05-03&cruncher=interleaved")
+
+		// when
+		actual, err := requestSettings(defaults, query)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 30, actual.CruncherConfig.LunchBreakInMin)
+		assert.Equal(t, "12:30", actual.CruncherConfig.LunchStartTime)
+		assert.Equal(t, []string{"Internal", "Support"}, actual.SinglePipelines)
+		assert.Equal(t, 6.5, actual.TargetHoursPerDay)
+		assert.True(t, actual.CSVOptions.SkipSummaryLine)
+		assert.Equal(t, []string{"Gesamtzeit"}, actual.CSVOptions.SkipColumnNames)
+		assert.Equal(t, "2021-05-03", actual.DateRange.FromDate())
+		assert.Equal(t, "interleaved", actual.CruncherName)
+		assert.Equal(t, 60, defaults.CruncherConfig.LunchBreakInMin)
+	})
+	t.Run("should fail for more than one date range", func(t *testing.T) {
+		query, _ := url.ParseQuery("week=2021-W18&month=2021-05")
+
+		// when
+		_, err := requestSettings(defaults, query)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "only one of week, month or from/to may be used")
+	})
+	t.Run("should fail for invalid numbers", func(t *testing.T) {
+		query, _ := url.ParseQuery("target-hours=eight")
+
+		// when
+		_, err := requestSettings(defaults, query)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid parameter target-hours 'eight' (expected a number)")
+	})
+}
+
+func Test_jsonDataReader_Read(t *testing.T) {
+	t.Run("should read hours per pipeline and date", func(t *testing.T) {
+		sut := &jsonDataReader{content: []byte(`{"Pipeline A": {"2021-05-03": 7.5, "2021-05-04": 6}}`)}
+
+		// when
+		actual, err := sut.Read()
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]float64{"2021-05-03": 7.5, "2021-05-04": 6}, actual.NamedDayRedmineValues["Pipeline A"].WorkPerDay)
+	})
+	t.Run("should fail for invalid dates", func(t *testing.T) {
+		sut := &jsonDataReader{content: []byte(`{"Pipeline A": {"Gesamtzeit": 13.5}}`)}
+
+		// when
+		_, err := sut.Read()
+
+		// then
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, core.ErrInvalidDate))
+	})
+	t.Run("should fail for other JSON documents", func(t *testing.T) {
+		sut := &jsonDataReader{content: []byte(`{"pipelines": []}`)}
+
+		// when
+		_, err := sut.Read()
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "expected hours per pipeline and date")
+	})
+}
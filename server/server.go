@@ -0,0 +1,346 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/calendar"
+	"github.com/ppxl/sagemine/cruncher"
+	"github.com/ppxl/sagemine/logging"
+	"github.com/ppxl/sagemine/output"
+	"github.com/ppxl/sagemine/reader"
+	"github.com/ppxl/sagemine/redsage"
+	"github.com/ppxl/sagemine/report"
+	"github.com/ppxl/sagemine/transformer"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var log = logging.Logger()
+
+const (
+	// DefaultAddress only accepts connections from the local machine.
+	DefaultAddress = "127.0.0.1:8080"
+	// DefaultMaxBodyBytes limits request bodies to 1 MiB.
+	DefaultMaxBodyBytes = 1 << 20
+	// DefaultTimeout limits reading, processing and answering a request.
+	DefaultTimeout = 30 * time.Second
+	// readHeaderTimeout limits reading the request headers independent of the body.
+	readHeaderTimeout = 10 * time.Second
+	idleTimeout       = 2 * time.Minute
+)
+
+// Options contain the limits of the server and the defaults of all requests.
+type Options struct {
+	Settings Settings
+	// MaxBodyBytes limits the size of a request body. Zero uses DefaultMaxBodyBytes.
+	MaxBodyBytes int64
+	// Timeout limits the time of a request. Zero uses DefaultTimeout.
+	Timeout time.Duration
+}
+
+type handler struct {
+	options Options
+}
+
+// validation describes whether a request body can be crunched.
+type validation struct {
+	Valid     bool     `json:"valid"`
+	Error     string   `json:"error,omitempty"`
+	Pipelines []string `json:"pipelines,omitempty"`
+	Days      int      `json:"days,omitempty"`
+	Hours     float64  `json:"hours,omitempty"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewHandler returns the HTTP API. All endpoints except /health take a Redmine CSV export or a JSON object of hours
+// per pipeline and date as POST body and query parameters named like the flags of the run command:
+//
+//  GET  /health    answers "ok"
+//  POST /crunch    returns the time slots as JSON, CSV or text (?format=)
+//  POST /validate  returns whether the body can be crunched
+//  POST /report    returns the summary report as JSON, CSV, text or Markdown (?format=, ?period=)
+func NewHandler(options Options) http.Handler {
+	if options.MaxBodyBytes == 0 {
+		options.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if options.Timeout == 0 {
+		options.Timeout = DefaultTimeout
+	}
+	if options.Settings.Calendar == nil {
+		// a calendar without state cannot fail
+		options.Settings.Calendar, _ = calendar.New("")
+	}
+
+	h := &handler{options: options}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", h.health)
+	mux.HandleFunc("/crunch", h.crunch)
+	mux.HandleFunc("/validate", h.validate)
+	mux.HandleFunc("/report", h.report)
+
+	return http.TimeoutHandler(mux, options.Timeout, `{"error":"request timed out"}`)
+}
+
+// New returns a server for the HTTP API which limits reading the request and writing the response.
+func New(address string, options Options) *http.Server {
+	if options.Timeout == 0 {
+		options.Timeout = DefaultTimeout
+	}
+
+	return &http.Server{
+		Addr:              address,
+		Handler:           NewHandler(options),
+		ReadHeaderTimeout: readHeaderTimeout,
+		ReadTimeout:       options.Timeout,
+		// the response is written after processing which the handler already limits to the timeout
+		WriteTimeout: 2 * options.Timeout,
+		IdleTimeout:  idleTimeout,
+	}
+}
+
+func (h *handler) health(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w, http.MethodGet)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = fmt.Fprintln(w, "ok")
+}
+
+func (h *handler) crunch(w http.ResponseWriter, r *http.Request) {
+	settings, result, ok := h.process(w, r)
+	if !ok {
+		return
+	}
+
+	format := r.URL.Query().Get(paramFormat)
+	if format == "" {
+		format = output.FormatJSON
+	}
+	outputOptions := settings.OutputOptions
+	outputOptions.Days = settings.Calendar
+	writer, err := output.New(format, outputOptions)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	buffer := &bytes.Buffer{}
+	err = writer.Write(buffer, result.Crunched)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeBody(w, contentType(format), buffer.Bytes())
+}
+
+func (h *handler) validate(w http.ResponseWriter, r *http.Request) {
+	if !requirePost(w, r) {
+		return
+	}
+	settings, body, ok := h.readRequest(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := runPipeline(r, settings, body)
+	if err != nil {
+		writeJSON(w, http.StatusOK, validation{Valid: false, Error: err.Error()})
+		return
+	}
+
+	answer := validation{Valid: true, Pipelines: result.Data.SortedKeys()}
+	days := map[string]bool{}
+	for _, workPerDay := range result.Data.NamedDayRedmineValues {
+		for date, hours := range workPerDay.WorkPerDay {
+			days[date] = true
+			answer.Hours += hours
+		}
+	}
+	answer.Days = len(days)
+	writeJSON(w, http.StatusOK, answer)
+}
+
+func (h *handler) report(w http.ResponseWriter, r *http.Request) {
+	settings, result, ok := h.process(w, r)
+	if !ok {
+		return
+	}
+
+	summary, err := report.New(result.Data, result.Crunched, report.Options{
+		TargetHoursPerDay: settings.TargetHoursPerDay,
+		Period:            r.URL.Query().Get(paramPeriod),
+		Days:              settings.Calendar,
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	format := r.URL.Query().Get(paramFormat)
+	if format == "" {
+		format = report.FormatJSON
+	}
+	buffer := &bytes.Buffer{}
+	err = report.Write(buffer, summary, format, report.WriteOptions{
+		DecimalSeparator: settings.OutputOptions.DecimalSeparator,
+		CSVDelimiter:     settings.OutputOptions.CSVDelimiter,
+	})
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeBody(w, contentType(format), buffer.Bytes())
+}
+
+// process reads the request and crunches its body. It writes the error response and returns false if that fails.
+func (h *handler) process(w http.ResponseWriter, r *http.Request) (Settings, *redsage.Result, bool) {
+	if !requirePost(w, r) {
+		return Settings{}, nil, false
+	}
+	settings, body, ok := h.readRequest(w, r)
+	if !ok {
+		return Settings{}, nil, false
+	}
+
+	result, err := runPipeline(r, settings, body)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return Settings{}, nil, false
+	}
+
+	return settings, result, true
+}
+
+// readRequest returns the settings of the request and its body which must not exceed the size limit.
+func (h *handler) readRequest(w http.ResponseWriter, r *http.Request) (Settings, []byte, bool) {
+	settings, err := requestSettings(h.options.Settings, r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return Settings{}, nil, false
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, h.options.MaxBodyBytes))
+	if err != nil && int64(len(body)) >= h.options.MaxBodyBytes {
+		writeError(w, http.StatusRequestEntityTooLarge,
+			errors.Errorf("request body exceeds the limit of %d bytes", h.options.MaxBodyBytes))
+		return Settings{}, nil, false
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, errors.Wrap(err, "could not read request body"))
+		return Settings{}, nil, false
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("request body is empty (expected a Redmine CSV export or JSON)"))
+		return Settings{}, nil, false
+	}
+
+	return settings, body, true
+}
+
+// runPipeline reads, transforms and crunches the request body like the run command. It stops between the stages once
+// the request is cancelled or timed out.
+func runPipeline(r *http.Request, settings Settings, body []byte) (*redsage.Result, error) {
+	var dataReader reader.RedmineDataReader = &jsonDataReader{content: body}
+	if !isJSON(r.Header.Get("Content-Type")) {
+		csvOptions := settings.CSVOptions
+		csvOptions.Filename = requestSource
+		csvOptions.Content = body
+		var err error
+		dataReader, err = reader.Create(reader.FormatCSV, reader.Options{CSVOptions: csvOptions})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// transformers may keep state, so each request gets its own steps
+	steps := redsage.DefaultSteps(settings.SinglePipelines)
+	if len(settings.Steps) > 0 {
+		var err error
+		steps, err = transformer.NewSteps(settings.Steps)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	crunch, err := cruncher.Create(settings.CruncherName)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline, err := redsage.New(redsage.Options{
+		Reader:         &checkedReader{reader: dataReader},
+		Steps:          steps,
+		Calendar:       settings.Calendar,
+		NonWorkingDays: settings.NonWorkingDays,
+		Cruncher:       crunch,
+		CruncherConfig: settings.CruncherConfig,
+		DateRange:      settings.DateRange,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pipeline.Process(r.Context())
+}
+
+func requirePost(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return false
+	}
+
+	return true
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed string) {
+	w.Header().Set("Allow", allowed)
+	writeError(w, http.StatusMethodNotAllowed, errors.Errorf("method not allowed (expected %s)", allowed))
+}
+
+// contentType returns the media type of an output or report format.
+func contentType(format string) string {
+	switch strings.ToLower(format) {
+	case output.FormatJSON:
+		return contentTypeJSON
+	case output.FormatCSV:
+		return "text/csv; charset=utf-8"
+	case report.FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	log.Infof("request failed with status %d: %v", status, err)
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		log.Errorf("could not encode response: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypeJSON)
+	w.WriteHeader(status)
+	_, _ = w.Write(append(body, '\n'))
+}
+
+func writeBody(w http.ResponseWriter, contentType string, body []byte) {
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,300 @@
+package server
+
+import (
+	"encoding/json"
+	"github.com/ppxl/sagemine/calendar"
+	"github.com/ppxl/sagemine/core"
+	"github.com/ppxl/sagemine/cruncher"
+	"github.com/ppxl/sagemine/redsage"
+	"github.com/ppxl/sagemine/transformer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const redmineCSV = `Anforderungspipeline;2021-05-03;2021-05-04
+Pipeline A;7,50;6,00
+`
+
+type slowCruncher struct {
+	delay time.Duration
+}
+
+func (sc *slowCruncher) Crunch(pdata *core.PipelineData, config cruncher.Config) (*core.CrunchedOutput, error) {
+	time.Sleep(sc.delay)
+	return core.NewCrunchedOutput(), nil
+}
+
+func newTestServer(options Options) *httptest.Server {
+	if options.Settings.CruncherConfig.LunchBreakInMin == 0 {
+		options.Settings.CruncherConfig.LunchBreakInMin = 60
+	}
+
+	return httptest.NewServer(NewHandler(options))
+}
+
+func post(t *testing.T, url string, contentType string, body string) (*http.Response, string) {
+	response, err := http.Post(url, contentType, strings.NewReader(body))
+	require.NoError(t, err)
+	defer response.Body.Close()
+	content, err := ioutil.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	return response, string(content)
+}
+
+func TestHandler_crunch(t *testing.T) {
+	server := newTestServer(Options{})
+	defer server.Close()
+
+	t.Run("should crunch a CSV export into JSON time slots", func(t *testing.T) {
+		// when
+		response, body := post(t, server.URL+"/crunch", "text/csv", redmineCSV)
+
+		// then
+		require.Equal(t, http.StatusOK, response.StatusCode, body)
+		assert.Equal(t, contentTypeJSON, response.Header.Get("Content-Type"))
+		assert.Contains(t, body, `"name": "Pipeline A-joined"`)
+		assert.Contains(t, body, `"start": "13:00",
+              "end": "16:30"`)
+	})
+	t.Run("should crunch JSON hours with the options of the query into CSV", func(t *testing.T) {
+		// when
+		response, body := post(t, server.URL+"/crunch?format=csv&break=30&pipeline-single=ACME&week=2021-W18",
+			"application/json; charset=utf-8", `{"ACME": {"2021-05-03": 1}, "Pipeline A": {"2021-05-03": 4, "2021-05-10": 2}}`)
+
+		// then
+		require.Equal(t, http.StatusOK, response.StatusCode, body)
+		assert.Equal(t, "text/csv; charset=utf-8", response.Header.Get("Content-Type"))
+		expected := `Pipeline;Date;Start;End;Hours;Note
+ACME;2021-05-03;08:00;09:00;1.00;
+Pipeline A-joined;2021-05-03;09:00;12:00;3.00;
+Pipeline A-joined;2021-05-03;12:30;13:30;1.00;
+`
+		assert.Equal(t, expected, body)
+	})
+	t.Run("should answer unprocessable input with an error", func(t *testing.T) {
+		// when
+		response, body := post(t, server.URL+"/crunch", "application/json", `{"Pipeline A": {"3rd of May": 1}}`)
+
+		// then
+		assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+		assert.Contains(t, body, `"error":`)
+		assert.Contains(t, body, "3rd of May")
+	})
+	t.Run("should reject negative hours", func(t *testing.T) {
+		// when
+		response, body := post(t, server.URL+"/crunch", "application/json", `{"A": {"2021-05-03": -3}}`)
+
+		// then
+		assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+		assert.Contains(t, body, "negative work time of -3.00 hours for A on 2021-05-03")
+	})
+	t.Run("should reject days which last past midnight", func(t *testing.T) {
+		// when
+		response, body := post(t, server.URL+"/crunch", "text/csv", `Anforderungspipeline;2021-05-03
+Pipeline A;10,00
+Pipeline B;6,50
+`)
+
+		// then
+		assert.Equal(t, http.StatusUnprocessableEntity, response.StatusCode)
+		assert.Contains(t, body, "work time on 2021-05-03 does not fit into the day: its time slots would last until 01:30 on 2021-05-04")
+	})
+	t.Run("should fail for invalid options", func(t *testing.T) {
+		// when
+		response, body := post(t, server.URL+"/crunch?break=long", "text/csv", redmineCSV)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		assert.Contains(t, body, "invalid parameter break 'long'")
+	})
+	t.Run("should fail for negative minutes", func(t *testing.T) {
+		// when
+		response, body := post(t, server.URL+"/crunch?break=-30", "text/csv", redmineCSV)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		assert.Contains(t, body, "invalid parameter break '-30' (expected a whole number of zero or more)")
+	})
+	t.Run("should fail for other methods than POST", func(t *testing.T) {
+		// when
+		response, err := http.Get(server.URL + "/crunch")
+
+		// then
+		require.NoError(t, err)
+		_ = response.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, response.StatusCode)
+		assert.Equal(t, http.MethodPost, response.Header.Get("Allow"))
+	})
+}
+
+func TestHandler_limits(t *testing.T) {
+	t.Run("should reject bodies above the size limit", func(t *testing.T) {
+		server := newTestServer(Options{MaxBodyBytes: 32})
+		defer server.Close()
+
+		// when
+		response, body := post(t, server.URL+"/crunch", "text/csv", redmineCSV)
+
+		// then
+		assert.Equal(t, http.StatusRequestEntityTooLarge, response.StatusCode)
+		assert.Contains(t, body, "request body exceeds the limit of 32 bytes")
+	})
+	t.Run("should fail for empty bodies", func(t *testing.T) {
+		server := newTestServer(Options{})
+		defer server.Close()
+
+		// when
+		response, body := post(t, server.URL+"/crunch", "text/csv", "")
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		assert.Contains(t, body, "request body is empty")
+	})
+	t.Run("should stop requests which take too long", func(t *testing.T) {
+		// the cruncher stays registered because the registry cannot forget names
+		err := cruncher.Register("slow", func() cruncher.Cruncher { return &slowCruncher{delay: 200 * time.Millisecond} })
+		require.NoError(t, err)
+		server := newTestServer(Options{Timeout: 20 * time.Millisecond})
+		defer server.Close()
+
+		// when
+		response, body := post(t, server.URL+"/crunch?cruncher=slow", "text/csv", redmineCSV)
+
+		// then
+		assert.Equal(t, http.StatusServiceUnavailable, response.StatusCode)
+		assert.Contains(t, body, "request timed out")
+	})
+}
+
+func TestHandler_validate(t *testing.T) {
+	server := newTestServer(Options{})
+	defer server.Close()
+
+	t.Run("should describe valid input", func(t *testing.T) {
+		// when
+		response, body := post(t, server.URL+"/validate", "text/csv", redmineCSV)
+
+		// then
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		actual := validation{}
+		require.NoError(t, json.Unmarshal([]byte(body), &actual))
+		assert.Equal(t, validation{Valid: true, Pipelines: []string{"Pipeline A-joined"}, Days: 2, Hours: 13.5}, actual)
+	})
+	t.Run("should describe invalid input", func(t *testing.T) {
+		// when
+		response, body := post(t, server.URL+"/validate?lunch-start=noon", "text/csv", redmineCSV)
+
+		// then
+		require.Equal(t, http.StatusOK, response.StatusCode)
+		actual := validation{}
+		require.NoError(t, json.Unmarshal([]byte(body), &actual))
+		assert.False(t, actual.Valid)
+		assert.Contains(t, actual.Error, "invalid lunch start time 'noon'")
+	})
+	t.Run("should describe hours which do not fit into a day as invalid", func(t *testing.T) {
+		for _, hours := range []string{`{"A": {"2021-05-03": -3}}`, `{"A": {"2021-05-03": 16.5}}`} {
+			// when
+			response, body := post(t, server.URL+"/validate", "application/json", hours)
+
+			// then
+			require.Equal(t, http.StatusOK, response.StatusCode)
+			actual := validation{}
+			require.NoError(t, json.Unmarshal([]byte(body), &actual))
+			assert.False(t, actual.Valid, hours)
+		}
+	})
+}
+
+func TestHandler_settings(t *testing.T) {
+	workCalendar, _ := calendar.New("BY")
+	server := newTestServer(Options{Settings: Settings{
+		Steps:             []transformer.StepDefinition{{Name: transformer.JoinName, Options: map[string]string{"name": "Project"}}},
+		Calendar:          workCalendar,
+		NonWorkingDays:    redsage.NonWorkingDaysRelocate,
+		TargetHoursPerDay: 8,
+	}})
+	defer server.Close()
+	// 2021-06-03 is Fronleichnam in Bavaria
+	hours := `{"Pipeline A": {"2021-06-02": 4, "2021-06-03": 2}}`
+
+	t.Run("should crunch with the configured steps and relocate non-working days", func(t *testing.T) {
+		// when
+		response, body := post(t, server.URL+"/crunch?format=csv", "application/json", hours)
+
+		// then
+		require.Equal(t, http.StatusOK, response.StatusCode, body)
+		expected := `Pipeline;Date;Start;End;Hours;Note
+Project;2021-06-02;08:00;12:00;4.00;
+Project;2021-06-02;13:00;15:00;2.00;
+`
+		assert.Equal(t, expected, body)
+	})
+	t.Run("should report holidays without target hours", func(t *testing.T) {
+		// when
+		response, body := post(t, server.URL+"/report?format=csv", "application/json", hours)
+
+		// then
+		require.Equal(t, http.StatusOK, response.StatusCode, body)
+		assert.Contains(t, body, "Total;6.00;6.00;6.00;8.00;-2.00\n")
+	})
+}
+
+func TestHandler_report(t *testing.T) {
+	server := newTestServer(Options{Settings: Settings{TargetHoursPerDay: 7}})
+	defer server.Close()
+
+	t.Run("should summarize the hours as Markdown", func(t *testing.T) {
+		// when
+		response, body := post(t, server.URL+"/report?format=markdown", "text/csv", redmineCSV)
+
+		// then
+		require.Equal(t, http.StatusOK, response.StatusCode, body)
+		assert.Equal(t, "text/markdown; charset=utf-8", response.Header.Get("Content-Type"))
+		assert.Contains(t, body, "| 2021-05-03 | 7.50 | 7.50 | 7.50 | 7.00 | 0.50 |")
+	})
+	t.Run("should fail for unknown formats", func(t *testing.T) {
+		// when
+		response, body := post(t, server.URL+"/report?format=pdf", "text/csv", redmineCSV)
+
+		// then
+		assert.Equal(t, http.StatusBadRequest, response.StatusCode)
+		assert.Contains(t, body, "unsupported report format 'pdf'")
+	})
+}
+
+func TestHandler_health(t *testing.T) {
+	t.Run("should answer ok", func(t *testing.T) {
+		server := newTestServer(Options{})
+		defer server.Close()
+
+		// when
+		response, err := http.Get(server.URL + "/health")
+
+		// then
+		require.NoError(t, err)
+		defer response.Body.Close()
+		body, _ := ioutil.ReadAll(response.Body)
+		assert.Equal(t, http.StatusOK, response.StatusCode)
+		assert.Equal(t, "ok\n", string(body))
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Run("should limit reading and writing", func(t *testing.T) {
+		// when
+		actual := New(DefaultAddress, Options{Timeout: 5 * time.Second})
+
+		// then
+		assert.Equal(t, DefaultAddress, actual.Addr)
+		assert.Equal(t, readHeaderTimeout, actual.ReadHeaderTimeout)
+		assert.Equal(t, 5*time.Second, actual.ReadTimeout)
+		assert.Equal(t, 10*time.Second, actual.WriteTimeout)
+	})
+}
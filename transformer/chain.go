@@ -0,0 +1,309 @@
+package transformer
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	stepNameSeparator   = ":"
+	stepOptionSeparator = ","
+	stepValueSeparator  = "="
+	stepListSeparator   = "|"
+)
+
+// StepDefinition declares a single transformation step by the transformer's name and its textual options.
+//
+// Example:
+//  def, err := ParseStep("filter:exclude=ACME|Internal")
+type StepDefinition struct {
+	Name    string            `yaml:"name"`
+	Options map[string]string `yaml:"options,omitempty"`
+}
+
+// Step is a configured transformer which is ready to be executed by a chain.
+type Step struct {
+	Name        string
+	Transformer Transformer
+	Config      Config
+}
+
+// Chain executes transformation steps in their declared order. Each step receives the output of its predecessor.
+type Chain struct {
+	steps []Step
+}
+
+// Configurator turns the textual options of a step definition into the transformer's config. It should reject
+// unknown options.
+type Configurator func(options map[string]string) (Config, error)
+
+type registration struct {
+	create    func() Transformer
+	configure Configurator
+}
+
+var registry = map[string]registration{
+	JoinName:         {create: func() Transformer { return &joinTransformer{} }, configure: configureJoin},
+	FilterName:       {create: func() Transformer { return &filterTransformer{} }, configure: configureFilter},
+	RenameName:       {create: func() Transformer { return &renameTransformer{} }, configure: configureRename},
+	RedistributeName: {create: func() Transformer { return &redistributeTransformer{} }, configure: configureRedistribute},
+	ScaleName:        {create: func() Transformer { return &scaleTransformer{} }, configure: configureScale},
+	DropDaysName:     {create: func() Transformer { return &dropDaysTransformer{} }, configure: configureDropDays},
+}
+
+// Register adds a transformer which step definitions select by its name. Third-party transformers register themselves
+// in an init function of their package. A nil configurator accepts all options, which the transformer finds in
+// Config.Options.
+//
+// Example:
+//  err := transformer.Register("round", func() transformer.Transformer { return &roundTransformer{} }, nil)
+func Register(name string, create func() Transformer, configure Configurator) error {
+	if name == "" || create == nil {
+		return errors.New("transformer registration needs a name and a constructor")
+	}
+	if _, ok := registry[name]; ok {
+		return errors.Errorf("transformer '%s' is already registered", name)
+	}
+	if configure == nil {
+		configure = func(options map[string]string) (Config, error) { return Config{}, nil }
+	}
+
+	registry[name] = registration{create: create, configure: configure}
+	return nil
+}
+
+// Names returns the names of all known transformers in lexical order.
+func Names() []string {
+	result := make([]string, 0, len(registry))
+	for name := range registry {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// NewChain creates a chain from the given step definitions.
+func NewChain(definitions []StepDefinition) (*Chain, error) {
+	steps, err := NewSteps(definitions)
+	if err != nil {
+		return nil, err
+	}
+
+	chain := &Chain{}
+	for _, step := range steps {
+		chain.Add(step)
+	}
+
+	return chain, nil
+}
+
+// NewSteps creates the configured steps of the given step definitions, f. i. for a redsage.Pipeline.
+func NewSteps(definitions []StepDefinition) ([]Step, error) {
+	steps := []Step{}
+
+	for i, definition := range definitions {
+		reg, ok := registry[definition.Name]
+		if !ok {
+			return nil, errors.Errorf("unknown transformer '%s' in step %d (known transformers: %s)",
+				definition.Name, i+1, strings.Join(Names(), ", "))
+		}
+
+		config, err := reg.configure(definition.Options)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid options for transformer '%s' in step %d", definition.Name, i+1)
+		}
+
+		config.Options = definition.Options
+		steps = append(steps, Step{Name: definition.Name, Transformer: reg.create(), Config: config})
+	}
+
+	return steps, nil
+}
+
+// Add appends a step to the end of the chain.
+func (c *Chain) Add(step Step) {
+	c.steps = append(c.steps, step)
+}
+
+// Steps returns the chain's steps in execution order.
+func (c *Chain) Steps() []Step {
+	return c.steps
+}
+
+// Transform executes all steps in their order and returns the output of the last one. An empty chain returns the
+// input data.
+func (c *Chain) Transform(pdata *core.PipelineData) (*core.PipelineData, error) {
+	result := pdata
+
+	for i, step := range c.steps {
+		var err error
+		result, err = step.Transformer.Transform(result, step.Config)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error in transformation step %d (%s)", i+1, step.Name)
+		}
+	}
+
+	return result, nil
+}
+
+// ParseStep parses a step definition of the form name[:option=value[,option=value...]]. Options with multiple values
+// separate them by a pipe character, f. i. "join:single=Pipeline A|Pipeline B".
+func ParseStep(spec string) (StepDefinition, error) {
+	parts := strings.SplitN(spec, stepNameSeparator, 2)
+	result := StepDefinition{Name: strings.TrimSpace(parts[0]), Options: map[string]string{}}
+	if result.Name == "" {
+		return StepDefinition{}, errors.Errorf("transformation step '%s' misses a transformer name", spec)
+	}
+
+	if len(parts) == 1 || strings.TrimSpace(parts[1]) == "" {
+		return result, nil
+	}
+
+	for _, option := range strings.Split(parts[1], stepOptionSeparator) {
+		keyValue := strings.SplitN(option, stepValueSeparator, 2)
+		if len(keyValue) != 2 || strings.TrimSpace(keyValue[0]) == "" {
+			return StepDefinition{}, errors.Errorf("option '%s' of transformation step '%s' must look like key=value", option, spec)
+		}
+		result.Options[strings.TrimSpace(keyValue[0])] = strings.TrimSpace(keyValue[1])
+	}
+
+	return result, nil
+}
+
+func configureJoin(options map[string]string) (Config, error) {
+	err := checkOptions(options, "single", "name")
+	if err != nil {
+		return Config{}, err
+	}
+
+	return Config{
+		SinglePipelineNames: splitList(options["single"]),
+		JoinedPipelineName:  options["name"],
+	}, nil
+}
+
+func configureFilter(options map[string]string) (Config, error) {
+	err := checkOptions(options, "include", "exclude")
+	if err != nil {
+		return Config{}, err
+	}
+
+	return Config{
+		IncludePipelineNames: splitList(options["include"]),
+		ExcludePipelineNames: splitList(options["exclude"]),
+	}, nil
+}
+
+func configureRename(options map[string]string) (Config, error) {
+	if len(options) == 0 {
+		return Config{}, errors.New("expected at least one rename of the form 'old name=new name'")
+	}
+
+	renames := map[string]string{}
+	for oldName, newName := range options {
+		if newName == "" {
+			return Config{}, errors.Errorf("new name for pipeline '%s' must not be empty", oldName)
+		}
+		renames[oldName] = newName
+	}
+
+	return Config{PipelineRenames: renames}, nil
+}
+
+func configureRedistribute(options map[string]string) (Config, error) {
+	err := checkOptions(options, "from", "to")
+	if err != nil {
+		return Config{}, err
+	}
+
+	sources := splitList(options["from"])
+	if len(sources) == 0 {
+		return Config{}, errors.New("option 'from' is required")
+	}
+
+	return Config{
+		SourcePipelineNames: sources,
+		TargetPipelineNames: splitList(options["to"]),
+	}, nil
+}
+
+func configureScale(options map[string]string) (Config, error) {
+	err := checkOptions(options, "factor", "pipelines")
+	if err != nil {
+		return Config{}, err
+	}
+
+	factorRaw, ok := options["factor"]
+	if !ok {
+		return Config{}, errors.New("option 'factor' is required")
+	}
+	factor, err := strconv.ParseFloat(factorRaw, 64)
+	if err != nil {
+		return Config{}, errors.Wrapf(err, "could not parse scale factor '%s'", factorRaw)
+	}
+
+	return Config{
+		ScaleFactor:         factor,
+		TargetPipelineNames: splitList(options["pipelines"]),
+	}, nil
+}
+
+func configureDropDays(options map[string]string) (Config, error) {
+	err := checkOptions(options, "dates", "weekdays")
+	if err != nil {
+		return Config{}, err
+	}
+
+	weekdays := []time.Weekday{}
+	for _, weekdayRaw := range splitList(options["weekdays"]) {
+		weekday, err := parseWeekday(weekdayRaw)
+		if err != nil {
+			return Config{}, err
+		}
+		weekdays = append(weekdays, weekday)
+	}
+
+	return Config{
+		DropDates:    splitList(options["dates"]),
+		DropWeekdays: weekdays,
+	}, nil
+}
+
+func checkOptions(options map[string]string, allowed ...string) error {
+	for key := range options {
+		if !core.ContainsString(allowed, key) {
+			return errors.Errorf("unknown option '%s' (allowed options: %s)", key, strings.Join(allowed, ", "))
+		}
+	}
+
+	return nil
+}
+
+func splitList(value string) []string {
+	result := []string{}
+	for _, item := range strings.Split(value, stepListSeparator) {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			result = append(result, item)
+		}
+	}
+
+	return result
+}
+
+func parseWeekday(value string) (time.Weekday, error) {
+	lowerValue := strings.ToLower(value)
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		name := strings.ToLower(weekday.String())
+		if lowerValue == name || lowerValue == name[:3] {
+			return weekday, nil
+		}
+	}
+
+	return time.Sunday, errors.Errorf("unknown weekday '%s'", value)
+}
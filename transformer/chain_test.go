@@ -0,0 +1,142 @@
+package transformer
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseStep(t *testing.T) {
+	t.Run("should parse name without options", func(t *testing.T) {
+		actual, err := ParseStep("join")
+
+		require.NoError(t, err)
+		assert.Equal(t, StepDefinition{Name: "join", Options: map[string]string{}}, actual)
+	})
+	t.Run("should parse options", func(t *testing.T) {
+		actual, err := ParseStep("join:single=Pipeline A|Pipeline B, name=Joined")
+
+		require.NoError(t, err)
+		expected := StepDefinition{Name: "join", Options: map[string]string{"single": "Pipeline A|Pipeline B", "name": "Joined"}}
+		assert.Equal(t, expected, actual)
+	})
+	t.Run("should fail for malformed option", func(t *testing.T) {
+		_, err := ParseStep("filter:exclude")
+
+		require.Error(t, err)
+	})
+	t.Run("should fail for missing name", func(t *testing.T) {
+		_, err := ParseStep(":exclude=ACME")
+
+		require.Error(t, err)
+	})
+}
+
+func TestNewChain(t *testing.T) {
+	t.Run("should configure steps in declared order", func(t *testing.T) {
+		actual, err := NewChain([]StepDefinition{
+			{Name: FilterName, Options: map[string]string{"exclude": "ACME"}},
+			{Name: DropDaysName, Options: map[string]string{"weekdays": "sat|Sunday"}},
+			{Name: JoinName},
+		})
+
+		require.NoError(t, err)
+		require.Len(t, actual.Steps(), 3)
+		assert.Equal(t, FilterName, actual.Steps()[0].Name)
+		assert.Equal(t, []string{"ACME"}, actual.Steps()[0].Config.ExcludePipelineNames)
+		assert.Equal(t, []time.Weekday{time.Saturday, time.Sunday}, actual.Steps()[1].Config.DropWeekdays)
+		assert.Equal(t, JoinName, actual.Steps()[2].Name)
+	})
+	t.Run("should fail for unknown transformer", func(t *testing.T) {
+		_, err := NewChain([]StepDefinition{{Name: "shuffle"}})
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown transformer 'shuffle'")
+	})
+	t.Run("should fail for unknown option", func(t *testing.T) {
+		_, err := NewChain([]StepDefinition{{Name: FilterName, Options: map[string]string{"drop": "ACME"}}})
+
+		require.Error(t, err)
+	})
+	t.Run("should fail for missing scale factor", func(t *testing.T) {
+		_, err := NewChain([]StepDefinition{{Name: ScaleName}})
+
+		require.Error(t, err)
+	})
+}
+
+type optionsTransformer struct {
+	options map[string]string
+}
+
+func (ot *optionsTransformer) Transform(pdata *core.PipelineData, config Config) (*core.PipelineData, error) {
+	ot.options = config.Options
+	return pdata, nil
+}
+
+func TestRegister(t *testing.T) {
+	t.Run("should select a registered transformer by name", func(t *testing.T) {
+		defer delete(registry, "custom")
+		custom := &optionsTransformer{}
+		err := Register("custom", func() Transformer { return custom }, nil)
+		require.NoError(t, err)
+
+		// when
+		chain, err := NewChain([]StepDefinition{{Name: "custom", Options: map[string]string{"precision": "0.25"}}})
+
+		// then
+		require.NoError(t, err)
+		_, err = chain.Transform(core.NewPipelineData())
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"precision": "0.25"}, custom.options)
+		assert.Contains(t, Names(), "custom")
+	})
+	t.Run("should fail for names which are already registered", func(t *testing.T) {
+		// when
+		err := Register(JoinName, func() Transformer { return &optionsTransformer{} }, nil)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "transformer 'join' is already registered")
+	})
+}
+
+func TestChain_Transform(t *testing.T) {
+	t.Run("should pass output of each step to the next one", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime("2021-05-03", 2)
+		pipelineB, _ := input.AddPipeline(pipelineBName)
+		pipelineB.PutWorkTime("2021-05-03", 1)
+		acme, _ := input.AddPipeline("ACME")
+		acme.PutWorkTime("2021-05-03", 4)
+
+		sut, err := NewChain([]StepDefinition{
+			{Name: FilterName, Options: map[string]string{"exclude": "ACME"}},
+			{Name: RenameName, Options: map[string]string{pipelineBName: "Pipeline B"}},
+			{Name: ScaleName, Options: map[string]string{"factor": "2", "pipelines": "Pipeline B"}},
+			{Name: JoinName, Options: map[string]string{"name": "All"}},
+		})
+		require.NoError(t, err)
+
+		// when
+		actual, err := sut.Transform(input)
+
+		// then
+		require.NoError(t, err)
+		expected := core.NewPipelineData()
+		expectedAll, _ := expected.AddPipeline("All")
+		expectedAll.PutWorkTime("2021-05-03", 4)
+		assert.Equal(t, expected, actual)
+	})
+	t.Run("should return input for empty chain", func(t *testing.T) {
+		input := core.NewPipelineData()
+
+		actual, err := (&Chain{}).Transform(input)
+
+		require.NoError(t, err)
+		assert.Same(t, input, actual)
+	})
+}
@@ -0,0 +1,51 @@
+package transformer
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"time"
+)
+
+type dropDaysTransformer struct {
+}
+
+// Transform removes the configured dates and weekdays from all pipelines. Keys which are no dates (f. i. a summary
+// column) are never dropped by weekday.
+func (d *dropDaysTransformer) Transform(pdata *core.PipelineData, config Config) (*core.PipelineData, error) {
+	result := core.NewPipelineData()
+
+	for _, redminePipeline := range pdata.SortedKeys() {
+		pipeline, err := result.AddPipeline(redminePipeline)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while dropping days from time data")
+		}
+
+		for date, worktime := range pdata.NamedDayRedmineValues[core.PipelineName(redminePipeline)].WorkPerDay {
+			if shouldDropDay(date, config) {
+				continue
+			}
+			pipeline.PutWorkTime(date, worktime)
+		}
+	}
+
+	return result, nil
+}
+
+func shouldDropDay(date string, config Config) bool {
+	if core.ContainsString(config.DropDates, date) {
+		return true
+	}
+
+	parsed, err := time.Parse(core.DateLayout, date)
+	if err != nil {
+		return false
+	}
+
+	for _, weekday := range config.DropWeekdays {
+		if parsed.Weekday() == weekday {
+			return true
+		}
+	}
+
+	return false
+}
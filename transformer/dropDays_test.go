@@ -0,0 +1,28 @@
+package transformer
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func Test_dropDaysTransformer_Transform(t *testing.T) {
+	t.Run("should drop dates and weekdays but keep non-date keys", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime("2021-05-07", 2)
+		pipelineA.PutWorkTime("2021-05-08", 1)
+		pipelineA.PutWorkTime("2021-05-10", 3)
+		pipelineA.PutWorkTime("Gesamtzeit", 6)
+		sut := &dropDaysTransformer{}
+
+		// when
+		actual, err := sut.Transform(input, Config{DropDates: []string{"2021-05-07"}, DropWeekdays: []time.Weekday{time.Saturday}})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"2021-05-10", "Gesamtzeit"}, actual.NamedDayRedmineValues[pipelineAName].SortedKeys())
+	})
+}
@@ -0,0 +1,31 @@
+package transformer
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+)
+
+type filterTransformer struct {
+}
+
+// Transform keeps only the included pipelines (or all if none are included) and removes the excluded ones afterwards.
+func (f *filterTransformer) Transform(pdata *core.PipelineData, config Config) (*core.PipelineData, error) {
+	result := core.NewPipelineData()
+
+	for _, redminePipeline := range pdata.SortedKeys() {
+		if len(config.IncludePipelineNames) > 0 && !core.ContainsString(config.IncludePipelineNames, redminePipeline) {
+			continue
+		}
+		if core.ContainsString(config.ExcludePipelineNames, redminePipeline) {
+			continue
+		}
+
+		pipeline, err := result.AddPipeline(redminePipeline)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while filtering time data")
+		}
+		copyPipeline(pipeline, pdata.NamedDayRedmineValues[core.PipelineName(redminePipeline)])
+	}
+
+	return result, nil
+}
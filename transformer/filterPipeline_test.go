@@ -0,0 +1,33 @@
+package transformer
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_filterTransformer_Transform(t *testing.T) {
+	input := core.NewPipelineData()
+	pipelineA, _ := input.AddPipeline(pipelineAName)
+	pipelineA.PutWorkTime("2021-05-03", 2)
+	pipelineB, _ := input.AddPipeline(pipelineBName)
+	pipelineB.PutWorkTime("2021-05-03", 1)
+	pipelineC, _ := input.AddPipeline("ACME")
+	pipelineC.PutWorkTime("2021-05-03", 0.5)
+	sut := &filterTransformer{}
+
+	t.Run("should keep included pipelines", func(t *testing.T) {
+		actual, err := sut.Transform(input, Config{IncludePipelineNames: []string{pipelineAName, "ACME"}})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"ACME", pipelineAName}, actual.SortedKeys())
+		assert.Equal(t, 2.0, actual.NamedDayRedmineValues[pipelineAName].WorkTime("2021-05-03"))
+	})
+	t.Run("should remove excluded pipelines", func(t *testing.T) {
+		actual, err := sut.Transform(input, Config{ExcludePipelineNames: []string{"ACME"}})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{pipelineBName, pipelineAName}, actual.SortedKeys())
+	})
+}
@@ -5,40 +5,49 @@ import (
 	"github.com/ppxl/sagemine/core"
 )
 
-// Config contains configuration values that modify the joining behaviour.
-type Config struct {
-	SinglePipelineNames []string
-}
-
-type Transformer interface {
-	Transform(pdata *core.PipelineData, config Config) (*core.PipelineData, error)
-}
-
-func New() *joinTransformer {
-	return &joinTransformer{}
-}
+const joinedPipelineSuffix = "-joined"
 
 type joinTransformer struct {
 }
 
+// Transform joins all pipelines into a single pseudo-pipeline, except for the configured single pipelines which are
+// taken over as they are. Unless configured otherwise the pseudo-pipeline is named after the first joined pipeline in
+// lexical order so that the name does not depend on the work time of the read period.
 func (j *joinTransformer) Transform(pdata *core.PipelineData, config Config) (*core.PipelineData, error) {
 	result := core.NewPipelineData()
-	var err error
-	var joinedPipeline *core.RedmineWorkPerDay
+	toBeJoined := []string{}
 
-	for redminePipeline, workPerDay := range pdata.NamedDayRedmineValues {
-		if joinedPipeline == nil {
-			pipelineName := string(redminePipeline) + "-joined"
-			joinedPipeline, err = result.AddPipeline(pipelineName)
+	for _, redminePipeline := range pdata.SortedKeys() {
+		workPerDay := pdata.NamedDayRedmineValues[core.PipelineName(redminePipeline)]
 
-			if err != nil {
-				return nil, errors.Wrap(err, "error while crunching time data")
-			}
+		if !core.ContainsString(config.SinglePipelineNames, redminePipeline) {
+			toBeJoined = append(toBeJoined, redminePipeline)
+			continue
 		}
 
-		for date, worktime := range workPerDay.WorkPerDay {
-			joinedPipeline.PutWorkTime(date, worktime)
+		singlePipeline, err := result.AddPipeline(redminePipeline)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while joining time data")
 		}
+		copyPipeline(singlePipeline, workPerDay)
+	}
+
+	if len(toBeJoined) == 0 {
+		return result, nil
+	}
+
+	pipelineName := config.JoinedPipelineName
+	if pipelineName == "" {
+		pipelineName = toBeJoined[0] + joinedPipelineSuffix
+	}
+
+	joinedPipeline, err := result.AddPipeline(pipelineName)
+	if err != nil {
+		return nil, errors.Wrap(err, "error while joining time data")
+	}
+
+	for _, redminePipeline := range toBeJoined {
+		copyPipeline(joinedPipeline, pdata.NamedDayRedmineValues[core.PipelineName(redminePipeline)])
 	}
 
 	return result, nil
@@ -35,7 +35,7 @@ func Test_joinTransformer_Transform(t *testing.T) {
 		require.NoError(t, err)
 
 		expected := core.NewPipelineData()
-		pipelineAJoined, _ := expected.AddPipeline("Pipeline A-joined")
+		pipelineAJoined, _ := expected.AddPipeline("Pipeline 2/B-joined")
 		pipelineAJoined.PutWorkTime("2021-05-03", 0)
 		pipelineAJoined.PutWorkTime("2021-05-04", 1)
 		pipelineAJoined.PutWorkTime("2021-05-05", 3)
@@ -43,4 +43,67 @@ func Test_joinTransformer_Transform(t *testing.T) {
 		pipelineAJoined.PutWorkTime("2021-05-07", 4)
 		assert.Equal(t, expected, actual)
 	})
+	t.Run("should name the joined pipeline independent of the work time", func(t *testing.T) {
+		week := core.NewPipelineData()
+		weekA, _ := week.AddPipeline(pipelineAName)
+		weekA.PutWorkTime("2021-05-03", 1)
+		weekB, _ := week.AddPipeline(pipelineBName)
+		weekB.PutWorkTime("2021-05-03", 7)
+		month := core.NewPipelineData()
+		monthA, _ := month.AddPipeline(pipelineAName)
+		monthA.PutWorkTime("2021-05-03", 1)
+		monthA.PutWorkTime("2021-05-10", 8)
+		monthB, _ := month.AddPipeline(pipelineBName)
+		monthB.PutWorkTime("2021-05-03", 7)
+		sut := &joinTransformer{}
+
+		// when
+		actualWeek, err := sut.Transform(week, Config{})
+		require.NoError(t, err)
+		actualMonth, err := sut.Transform(month, Config{})
+		require.NoError(t, err)
+
+		// then
+		assert.Equal(t, []string{"Pipeline 2/B-joined"}, actualWeek.SortedKeys())
+		assert.Equal(t, actualWeek.SortedKeys(), actualMonth.SortedKeys())
+	})
+}
+
+func Test_joinTransformer_TransformWithSinglePipelines(t *testing.T) {
+	t.Run("should keep single pipelines apart", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime("2021-05-03", 2)
+		pipelineB, _ := input.AddPipeline(pipelineBName)
+		pipelineB.PutWorkTime("2021-05-03", 1)
+		pipelineC, _ := input.AddPipeline("ACME")
+		pipelineC.PutWorkTime("2021-05-03", 0.5)
+		sut := &joinTransformer{}
+
+		// when
+		actual, err := sut.Transform(input, Config{SinglePipelineNames: []string{pipelineAName}})
+
+		// then
+		require.NoError(t, err)
+
+		expected := core.NewPipelineData()
+		expectedA, _ := expected.AddPipeline(pipelineAName)
+		expectedA.PutWorkTime("2021-05-03", 2)
+		expectedJoined, _ := expected.AddPipeline("ACME-joined")
+		expectedJoined.PutWorkTime("2021-05-03", 1.5)
+		assert.Equal(t, expected, actual)
+	})
+	t.Run("should use configured name for joined pipeline", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime("2021-05-03", 2)
+		sut := &joinTransformer{}
+
+		// when
+		actual, err := sut.Transform(input, Config{JoinedPipelineName: "Everything"})
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, 2.0, actual.NamedDayRedmineValues["Everything"].WorkTime("2021-05-03"))
+	})
 }
@@ -0,0 +1,80 @@
+package transformer
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"math"
+)
+
+type redistributeTransformer struct {
+}
+
+// Transform removes the source pipelines and spreads their work time per day over the target pipelines. Each target
+// receives a share proportional to its own work time on that day, or an equal share if no target worked that day.
+// Shares are rounded to full minutes while the last target receives the remainder so that the day total is kept.
+func (r *redistributeTransformer) Transform(pdata *core.PipelineData, config Config) (*core.PipelineData, error) {
+	if len(config.SourcePipelineNames) == 0 {
+		return nil, errors.New("redistribution needs at least one source pipeline")
+	}
+
+	result := core.NewPipelineData()
+	targets := []string{}
+	surplusPerDay := map[string]float64{}
+
+	for _, redminePipeline := range pdata.SortedKeys() {
+		workPerDay := pdata.NamedDayRedmineValues[core.PipelineName(redminePipeline)]
+
+		if core.ContainsString(config.SourcePipelineNames, redminePipeline) {
+			for date, worktime := range workPerDay.WorkPerDay {
+				surplusPerDay[date] += worktime
+			}
+			continue
+		}
+
+		pipeline, err := result.AddPipeline(redminePipeline)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while redistributing time data")
+		}
+		copyPipeline(pipeline, workPerDay)
+
+		if len(config.TargetPipelineNames) == 0 || core.ContainsString(config.TargetPipelineNames, redminePipeline) {
+			targets = append(targets, redminePipeline)
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, errors.Errorf("found no target pipelines to redistribute work time of %v", config.SourcePipelineNames)
+	}
+
+	for date, surplus := range surplusPerDay {
+		distribute(result, targets, date, surplus)
+	}
+
+	return result, nil
+}
+
+func distribute(pdata *core.PipelineData, targets []string, date string, surplus float64) {
+	targetSum := 0.0
+	for _, target := range targets {
+		targetSum += pdata.NamedDayRedmineValues[core.PipelineName(target)].WorkTime(date)
+	}
+
+	surplusMinutes := math.Round(surplus * 60)
+	distributedMinutes := 0.0
+
+	for i, target := range targets {
+		pipeline := pdata.NamedDayRedmineValues[core.PipelineName(target)]
+
+		shareMinutes := surplusMinutes - distributedMinutes
+		if i < len(targets)-1 {
+			ratio := 1 / float64(len(targets))
+			if targetSum > 0 {
+				ratio = pipeline.WorkTime(date) / targetSum
+			}
+			shareMinutes = math.Round(surplusMinutes * ratio)
+		}
+
+		distributedMinutes += shareMinutes
+		pipeline.PutWorkTime(date, shareMinutes/60)
+	}
+}
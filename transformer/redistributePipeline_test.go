@@ -0,0 +1,43 @@
+package transformer
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_redistributeTransformer_Transform(t *testing.T) {
+	input := core.NewPipelineData()
+	pipelineA, _ := input.AddPipeline(pipelineAName)
+	pipelineA.PutWorkTime("2021-05-03", 3)
+	pipelineB, _ := input.AddPipeline(pipelineBName)
+	pipelineB.PutWorkTime("2021-05-03", 1)
+	acme, _ := input.AddPipeline("ACME")
+	acme.PutWorkTime("2021-05-03", 2)
+	acme.PutWorkTime("2021-05-04", 1)
+	sut := &redistributeTransformer{}
+
+	t.Run("should redistribute proportionally and evenly on days without target work", func(t *testing.T) {
+		actual, err := sut.Transform(input, Config{SourcePipelineNames: []string{"ACME"}})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{pipelineBName, pipelineAName}, actual.SortedKeys())
+		assert.Equal(t, 4.5, actual.NamedDayRedmineValues[pipelineAName].WorkTime("2021-05-03"))
+		assert.Equal(t, 1.5, actual.NamedDayRedmineValues[pipelineBName].WorkTime("2021-05-03"))
+		assert.Equal(t, 0.5, actual.NamedDayRedmineValues[pipelineAName].WorkTime("2021-05-04"))
+		assert.Equal(t, 0.5, actual.NamedDayRedmineValues[pipelineBName].WorkTime("2021-05-04"))
+	})
+	t.Run("should redistribute only to targets", func(t *testing.T) {
+		actual, err := sut.Transform(input, Config{SourcePipelineNames: []string{"ACME"}, TargetPipelineNames: []string{pipelineBName}})
+
+		require.NoError(t, err)
+		assert.Equal(t, 3.0, actual.NamedDayRedmineValues[pipelineAName].WorkTime("2021-05-03"))
+		assert.Equal(t, 3.0, actual.NamedDayRedmineValues[pipelineBName].WorkTime("2021-05-03"))
+	})
+	t.Run("should fail without targets", func(t *testing.T) {
+		_, err := sut.Transform(input, Config{SourcePipelineNames: []string{"ACME"}, TargetPipelineNames: []string{"unknown"}})
+
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,33 @@
+package transformer
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+)
+
+type renameTransformer struct {
+}
+
+// Transform renames pipelines. Work time of pipelines which end up with the same name is summed up.
+func (r *renameTransformer) Transform(pdata *core.PipelineData, config Config) (*core.PipelineData, error) {
+	result := core.NewPipelineData()
+
+	for _, redminePipeline := range pdata.SortedKeys() {
+		newName, ok := config.PipelineRenames[redminePipeline]
+		if !ok {
+			newName = redminePipeline
+		}
+
+		pipeline, ok := result.NamedDayRedmineValues[core.PipelineName(newName)]
+		if !ok {
+			var err error
+			pipeline, err = result.AddPipeline(newName)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error while renaming pipeline %s", redminePipeline)
+			}
+		}
+		copyPipeline(pipeline, pdata.NamedDayRedmineValues[core.PipelineName(redminePipeline)])
+	}
+
+	return result, nil
+}
@@ -0,0 +1,31 @@
+package transformer
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_renameTransformer_Transform(t *testing.T) {
+	t.Run("should rename pipeline and sum up collisions", func(t *testing.T) {
+		input := core.NewPipelineData()
+		pipelineA, _ := input.AddPipeline(pipelineAName)
+		pipelineA.PutWorkTime("2021-05-03", 2)
+		pipelineB, _ := input.AddPipeline(pipelineBName)
+		pipelineB.PutWorkTime("2021-05-03", 1)
+		pipelineB.PutWorkTime("2021-05-04", 3)
+		sut := &renameTransformer{}
+
+		// when
+		actual, err := sut.Transform(input, Config{PipelineRenames: map[string]string{pipelineBName: pipelineAName}})
+
+		// then
+		require.NoError(t, err)
+		expected := core.NewPipelineData()
+		expectedA, _ := expected.AddPipeline(pipelineAName)
+		expectedA.PutWorkTime("2021-05-03", 3)
+		expectedA.PutWorkTime("2021-05-04", 3)
+		assert.Equal(t, expected, actual)
+	})
+}
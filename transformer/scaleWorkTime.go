@@ -0,0 +1,36 @@
+package transformer
+
+import (
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+)
+
+type scaleTransformer struct {
+}
+
+// Transform multiplies the work time of the target pipelines (or all if none are configured) with the scale factor.
+func (s *scaleTransformer) Transform(pdata *core.PipelineData, config Config) (*core.PipelineData, error) {
+	if config.ScaleFactor < 0 {
+		return nil, errors.Errorf("scale factor must not be negative: %f", config.ScaleFactor)
+	}
+
+	result := core.NewPipelineData()
+
+	for _, redminePipeline := range pdata.SortedKeys() {
+		pipeline, err := result.AddPipeline(redminePipeline)
+		if err != nil {
+			return nil, errors.Wrap(err, "error while scaling time data")
+		}
+
+		factor := 1.0
+		if len(config.TargetPipelineNames) == 0 || core.ContainsString(config.TargetPipelineNames, redminePipeline) {
+			factor = config.ScaleFactor
+		}
+
+		for date, worktime := range pdata.NamedDayRedmineValues[core.PipelineName(redminePipeline)].WorkPerDay {
+			pipeline.PutWorkTime(date, worktime*factor)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,37 @@
+package transformer
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func Test_scaleTransformer_Transform(t *testing.T) {
+	input := core.NewPipelineData()
+	pipelineA, _ := input.AddPipeline(pipelineAName)
+	pipelineA.PutWorkTime("2021-05-03", 2)
+	pipelineB, _ := input.AddPipeline(pipelineBName)
+	pipelineB.PutWorkTime("2021-05-03", 1)
+	sut := &scaleTransformer{}
+
+	t.Run("should scale all pipelines", func(t *testing.T) {
+		actual, err := sut.Transform(input, Config{ScaleFactor: 0.5})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1.0, actual.NamedDayRedmineValues[pipelineAName].WorkTime("2021-05-03"))
+		assert.Equal(t, 0.5, actual.NamedDayRedmineValues[pipelineBName].WorkTime("2021-05-03"))
+	})
+	t.Run("should scale only target pipelines", func(t *testing.T) {
+		actual, err := sut.Transform(input, Config{ScaleFactor: 2, TargetPipelineNames: []string{pipelineBName}})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2.0, actual.NamedDayRedmineValues[pipelineAName].WorkTime("2021-05-03"))
+		assert.Equal(t, 2.0, actual.NamedDayRedmineValues[pipelineBName].WorkTime("2021-05-03"))
+	})
+	t.Run("should fail for negative factor", func(t *testing.T) {
+		_, err := sut.Transform(input, Config{ScaleFactor: -1})
+
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,66 @@
+package transformer
+
+import (
+	"github.com/ppxl/sagemine/core"
+	"time"
+)
+
+const (
+	// JoinName identifies the transformer that joins pipelines into a single pseudo-pipeline.
+	JoinName = "join"
+	// FilterName identifies the transformer that keeps or removes pipelines by name.
+	FilterName = "filter"
+	// RenameName identifies the transformer that renames pipelines.
+	RenameName = "rename"
+	// RedistributeName identifies the transformer that spreads the work time of pipelines over other pipelines.
+	RedistributeName = "redistribute"
+	// ScaleName identifies the transformer that multiplies work times by a factor.
+	ScaleName = "scale"
+	// DropDaysName identifies the transformer that removes whole days.
+	DropDaysName = "drop-days"
+)
+
+// Config contains configuration values that modify the transforming behaviour. Each transformer only evaluates the
+// fields that are relevant for it.
+type Config struct {
+	// SinglePipelineNames contains pipelines that will not be joined into the pseudo-pipeline (join).
+	SinglePipelineNames []string
+	// JoinedPipelineName overrides the name of the joined pseudo-pipeline (join).
+	JoinedPipelineName string
+	// IncludePipelineNames contains the only pipelines that are kept. Empty means all pipelines (filter).
+	IncludePipelineNames []string
+	// ExcludePipelineNames contains pipelines that are removed (filter).
+	ExcludePipelineNames []string
+	// PipelineRenames maps the current pipeline name to a new one (rename).
+	PipelineRenames map[string]string
+	// SourcePipelineNames contains pipelines whose work time will be moved to other pipelines (redistribute).
+	SourcePipelineNames []string
+	// TargetPipelineNames contains the pipelines that receive redistributed work time or that are scaled. Empty means
+	// all remaining pipelines (redistribute, scale).
+	TargetPipelineNames []string
+	// ScaleFactor is multiplied with every work time, f. i. 0.5 halves all values (scale).
+	ScaleFactor float64
+	// DropDates contains dates in the format YYYY-MM-DD which will be removed (drop-days).
+	DropDates []string
+	// DropWeekdays contains weekdays which will be removed (drop-days).
+	DropWeekdays []time.Weekday
+	// Options contains the textual options of the step definition, f. i. for registered third-party transformers.
+	Options map[string]string
+}
+
+// Transformer reshapes Redmine pipeline data before they are crunched.
+type Transformer interface {
+	// Transform returns a modified copy of the given pipeline data.
+	Transform(pdata *core.PipelineData, config Config) (*core.PipelineData, error)
+}
+
+// New returns a transformer that joins pipelines into a single pseudo-pipeline.
+func New() Transformer {
+	return &joinTransformer{}
+}
+
+func copyPipeline(target *core.RedmineWorkPerDay, source *core.RedmineWorkPerDay) {
+	for date, worktime := range source.WorkPerDay {
+		target.PutWorkTime(date, worktime)
+	}
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/calendar"
+	"github.com/ppxl/sagemine/core"
+	"github.com/ppxl/sagemine/cruncher"
+	"github.com/ppxl/sagemine/output"
+	"github.com/urfave/cli/v2"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const tuiPrompt = "> "
+
+const tuiHelp = `Commands:
+  order DATE N [N...]  place the numbered pipelines first on DATE, f. i. 'order 2021-05-03 2 1'
+  lunch HH:MM          start the lunch break at HH:MM
+  break MINUTES        set the length of the lunch break
+  format NAME          select the output format: %s
+  show                 show the week again
+  reset                undo all changes
+  write [FILE]         write the week in the selected format to FILE or the terminal and quit
+  quit                 quit without writing
+`
+
+func tuiCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "tui",
+		Usage:     "review the crunched week, reorder pipelines per day, move the lunch break and write the result",
+		Action:    doCliTui,
+		ArgsUsage: runArgsUsage,
+		Flags:     runFlags(),
+	}
+}
+
+func doCliTui(cliCtx *cli.Context) error {
+	args, err := buildRunArgs(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	data, workCalendar, err := prepareRedmineData(args)
+	if err != nil {
+		return err
+	}
+
+	session, err := newReviewSession(args, data, workCalendar, os.Stdin, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	return session.run()
+}
+
+// reviewSession lets the user adjust the schedule of the crunched week line by line. Every change re-runs the
+// cruncher.
+type reviewSession struct {
+	initialArgs  runArgs
+	args         runArgs
+	data         *core.PipelineData
+	workCalendar *calendar.Calendar
+	// pipelines contains the pipeline names which are referenced by their 1-based position.
+	pipelines []string
+	crunched  *core.CrunchedOutput
+	in        *bufio.Scanner
+	out       io.Writer
+}
+
+func newReviewSession(args runArgs, data *core.PipelineData, workCalendar *calendar.Calendar, in io.Reader, out io.Writer) (*reviewSession, error) {
+	args.pipelineOrder = copyPipelineOrder(args.pipelineOrder)
+	session := &reviewSession{
+		initialArgs:  args,
+		args:         args,
+		data:         data,
+		workCalendar: workCalendar,
+		pipelines:    data.SortedKeys(),
+		in:           bufio.NewScanner(in),
+		out:          out,
+	}
+
+	crunched, err := crunch(data, args)
+	if err != nil {
+		return nil, err
+	}
+	session.crunched = crunched
+
+	return session, nil
+}
+
+// run shows the week and executes commands until the week was written or the user quits.
+func (rs *reviewSession) run() error {
+	rs.show()
+
+	for {
+		rs.printf(tuiPrompt)
+		if !rs.in.Scan() {
+			rs.printf("\n")
+			return rs.in.Err()
+		}
+
+		fields := strings.Fields(rs.in.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		done, err := rs.execute(fields[0], fields[1:])
+		if err != nil {
+			rs.printf("error: %s\n", err)
+			continue
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// execute runs a single command and returns true if the session is finished.
+func (rs *reviewSession) execute(command string, parameters []string) (bool, error) {
+	switch command {
+	case "order":
+		return false, rs.order(parameters)
+	case "lunch":
+		if len(parameters) != 1 {
+			return false, errors.New("usage: lunch HH:MM")
+		}
+		return false, rs.recrunch(func(args *runArgs) error {
+			args.lunchStartTime = parameters[0]
+			return nil
+		})
+	case "break":
+		if len(parameters) != 1 {
+			return false, errors.New("usage: break MINUTES")
+		}
+		return false, rs.recrunch(func(args *runArgs) error {
+			minutes, err := strconv.Atoi(parameters[0])
+			if err != nil || minutes < 0 {
+				return errors.Errorf("invalid lunch break length '%s'", parameters[0])
+			}
+			args.lunchBreakInMin = minutes
+			return nil
+		})
+	case "format":
+		if len(parameters) != 1 {
+			return false, errors.New("usage: format NAME")
+		}
+		if _, err := output.New(parameters[0], rs.args.outputOptions); err != nil {
+			return false, err
+		}
+		rs.args.outputFormat = parameters[0]
+		rs.show()
+		return false, nil
+	case "show":
+		rs.show()
+		return false, nil
+	case "reset":
+		rs.args = rs.initialArgs
+		rs.args.pipelineOrder = copyPipelineOrder(rs.initialArgs.pipelineOrder)
+		return false, rs.recrunch(func(args *runArgs) error { return nil })
+	case "write":
+		return true, rs.write(parameters)
+	case "quit", "exit":
+		return true, nil
+	case "help", "?":
+		rs.printf(tuiHelp, strings.Join(output.Formats(), ", "))
+		return false, nil
+	default:
+		return false, errors.Errorf("unknown command '%s', type 'help' for a list of commands", command)
+	}
+}
+
+func (rs *reviewSession) order(parameters []string) error {
+	if len(parameters) < 2 {
+		return errors.New("usage: order DATE N [N...]")
+	}
+
+	date := parameters[0]
+	if _, err := time.Parse(core.DateLayout, date); err != nil {
+		return errors.Errorf("invalid date '%s' (expected format: YYYY-MM-DD)", date)
+	}
+
+	order := []string{}
+	for _, parameter := range parameters[1:] {
+		position, err := strconv.Atoi(parameter)
+		if err != nil || position < 1 || position > len(rs.pipelines) {
+			return errors.Errorf("unknown pipeline number '%s'", parameter)
+		}
+		order = append(order, rs.pipelines[position-1])
+	}
+
+	return rs.recrunch(func(args *runArgs) error {
+		args.pipelineOrder[date] = order
+		return nil
+	})
+}
+
+// recrunch applies the change to a copy of the current arguments and crunches again. The change is discarded if
+// crunching fails.
+func (rs *reviewSession) recrunch(change func(args *runArgs) error) error {
+	args := rs.args
+	args.pipelineOrder = copyPipelineOrder(rs.args.pipelineOrder)
+
+	err := change(&args)
+	if err != nil {
+		return err
+	}
+
+	crunched, err := crunch(rs.data, args)
+	if err != nil {
+		return err
+	}
+
+	rs.args = args
+	rs.crunched = crunched
+	rs.show()
+
+	return nil
+}
+
+func (rs *reviewSession) write(parameters []string) error {
+	if len(parameters) == 0 {
+		return writeResults(rs.out, rs.crunched, rs.workCalendar, rs.args)
+	}
+
+	file, err := os.Create(parameters[0])
+	if err != nil {
+		return errors.Wrapf(err, "could not create %s", parameters[0])
+	}
+	defer file.Close()
+
+	err = writeResults(file, rs.crunched, rs.workCalendar, rs.args)
+	if err != nil {
+		return err
+	}
+	rs.printf("written to %s\n", parameters[0])
+
+	return nil
+}
+
+// show prints the pipelines and a grid of days and their time slots ordered by start time.
+func (rs *reviewSession) show() {
+	writer := tabwriter.NewWriter(rs.out, 0, 0, 2, ' ', 0)
+
+	_, _ = fmt.Fprintln(writer, "#\tPipeline")
+	for index, pipeline := range rs.pipelines {
+		_, _ = fmt.Fprintf(writer, "%d\t%s\n", index+1, pipeline)
+	}
+	_ = writer.Flush()
+
+	lunchStartTime := rs.args.lunchStartTime
+	if lunchStartTime == "" {
+		lunchStartTime = cruncher.DefaultLunchStartTime
+	}
+	rs.printf("\nLunch at %s for %d min, output format %s\n\n", lunchStartTime, rs.args.lunchBreakInMin, rs.outputFormat())
+
+	rows := rs.gridRows()
+	columns := 0
+	for _, row := range rows {
+		if len(row)-1 > columns {
+			columns = len(row) - 1
+		}
+	}
+
+	header := []string{"Date"}
+	for column := 1; column <= columns; column++ {
+		header = append(header, fmt.Sprintf("Slot %d", column))
+	}
+	_, _ = fmt.Fprintln(writer, strings.Join(header, "\t"))
+	for _, row := range rows {
+		_, _ = fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	_ = writer.Flush()
+	rs.printf("\nType 'help' for a list of commands.\n")
+}
+
+// gridRows returns one row per day which starts with the date, followed by the slots like "2 08:00-12:00".
+func (rs *reviewSession) gridRows() [][]string {
+	type numberedSlot struct {
+		number int
+		slot   core.TimeSlot
+	}
+
+	slotsPerDay := map[string][]numberedSlot{}
+	for index, pipeline := range rs.pipelines {
+		workPerDay, ok := rs.crunched.NamedDaySageValues[core.PipelineName(pipeline)]
+		if !ok {
+			continue
+		}
+		for _, day := range workPerDay.SortedKeys() {
+			for _, slot := range workPerDay.TimeSlots(day) {
+				if !slot.IsEmpty() {
+					slotsPerDay[day] = append(slotsPerDay[day], numberedSlot{number: index + 1, slot: slot})
+				}
+			}
+			if _, ok := slotsPerDay[day]; !ok {
+				slotsPerDay[day] = []numberedSlot{}
+			}
+		}
+	}
+
+	days := make([]string, 0, len(slotsPerDay))
+	for day := range slotsPerDay {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	result := [][]string{}
+	for _, day := range days {
+		slots := slotsPerDay[day]
+		sort.SliceStable(slots, func(i, j int) bool { return slots[i].slot.Start < slots[j].slot.Start })
+
+		row := []string{day}
+		for _, slot := range slots {
+			row = append(row, fmt.Sprintf("%d %s-%s", slot.number, slot.slot.Start, slot.slot.End))
+		}
+		result = append(result, row)
+	}
+
+	return result
+}
+
+func (rs *reviewSession) outputFormat() string {
+	if rs.args.outputFormat == "" {
+		return output.FormatText
+	}
+	return rs.args.outputFormat
+}
+
+func (rs *reviewSession) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(rs.out, format, args...)
+}
+
+func copyPipelineOrder(pipelineOrder map[string][]string) map[string][]string {
+	result := map[string][]string{}
+	for date, order := range pipelineOrder {
+		result[date] = append([]string{}, order...)
+	}
+
+	return result
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"github.com/ppxl/sagemine/calendar"
+	"github.com/ppxl/sagemine/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func createReviewSession(t *testing.T, script string) (*reviewSession, *bytes.Buffer) {
+	data := core.NewPipelineData()
+	acme, _ := data.AddPipeline("ACME")
+	acme.PutWorkTime("2021-05-04", 1.5)
+	pipelineA, _ := data.AddPipeline("Pipeline A")
+	pipelineA.PutWorkTime("2021-05-04", 6)
+	workCalendar, _ := calendar.New("")
+	out := &bytes.Buffer{}
+
+	session, err := newReviewSession(runArgs{lunchBreakInMin: 60, outputFormat: "csv"}, data, workCalendar,
+		strings.NewReader(script), out)
+	require.NoError(t, err)
+
+	return session, out
+}
+
+func Test_reviewSession_run(t *testing.T) {
+	t.Run("should show the week as a grid", func(t *testing.T) {
+		sut, out := createReviewSession(t, "quit\n")
+
+		// when
+		err := sut.run()
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "1  ACME\n2  Pipeline A\n")
+		assert.Contains(t, out.String(), "2021-05-04  1 08:00-09:30  2 09:30-12:00  2 13:00-16:30\n")
+	})
+	t.Run("should reorder pipelines, move the lunch break and write the result", func(t *testing.T) {
+		sut, out := createReviewSession(t, "order 2021-05-04 2\nlunch 11:30\nbreak 30\nwrite\n")
+
+		// when
+		err := sut.run()
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "Lunch at 11:30 for 30 min")
+		assert.Contains(t, out.String(), "Pipeline A;2021-05-04;08:00;11:30;3.50;\nPipeline A;2021-05-04;12:00;14:30;2.50;\n")
+		assert.Contains(t, out.String(), "ACME;2021-05-04;14:30;16:00;1.50;\n")
+	})
+	t.Run("should keep the schedule after invalid commands", func(t *testing.T) {
+		sut, out := createReviewSession(t, "lunch noon\norder 2021-05-04 7\nfly\nreset\n")
+
+		// when
+		err := sut.run()
+
+		// then
+		require.NoError(t, err)
+		assert.Contains(t, out.String(), "invalid lunch start time 'noon'")
+		assert.Contains(t, out.String(), "error: unknown pipeline number '7'")
+		assert.Contains(t, out.String(), "error: unknown command 'fly'")
+		assert.Equal(t, "", sut.args.lunchStartTime)
+	})
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/pkg/errors"
+	"github.com/ppxl/sagemine/core"
+	"github.com/ppxl/sagemine/history"
+	"github.com/urfave/cli/v2"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+const (
+	flagWatchLong         = "watch"
+	flagWatchIntervalLong = "watch-interval"
+	defaultWatchInterval  = 2 * time.Second
+)
+
+// ANSI colors which highlight the changes since the previous crunch on terminals.
+var changeColors = map[string]string{
+	history.ChangeAdded:   "\033[32m",
+	history.ChangeRemoved: "\033[31m",
+	history.ChangeChanged: "\033[33m",
+}
+
+const colorReset = "\033[0m"
+
+func watchFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:  flagWatchLong,
+			Usage: "keep running and crunch the input files again whenever they change (optional)",
+		},
+		&cli.DurationFlag{
+			Name:  flagWatchIntervalLong,
+			Usage: "how often --" + flagWatchLong + " checks the input files for changes (optional)",
+			Value: defaultWatchInterval,
+		},
+	}
+}
+
+// fileState contains what is compared to find changed input files.
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime time.Time
+}
+
+func (fs fileState) equals(other fileState) bool {
+	return fs.exists == other.exists && fs.size == other.size && fs.modTime.Equal(other.modTime)
+}
+
+func doCliWatch(cliCtx *cli.Context, args runArgs) error {
+	stop := make(chan struct{})
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	go func() {
+		<-interrupt
+		close(stop)
+	}()
+
+	return doWatch(os.Stdout, args, cliCtx.Duration(flagWatchIntervalLong), isTerminal(os.Stdout), stop)
+}
+
+// doWatch crunches the input files and polls them until stop is closed. Each time a file changes and then stays
+// unchanged for an interval they are crunched again and the time slots are written together with the changes since
+// the previous crunch. Errors of a single crunch are logged and do not stop watching.
+func doWatch(w io.Writer, args runArgs, interval time.Duration, color bool, stop <-chan struct{}) error {
+	if len(args.filenames) == 0 {
+		return errors.Errorf("--%s needs input files, the Redmine REST API cannot be watched", flagWatchLong)
+	}
+	if interval <= 0 {
+		return errors.Errorf("invalid --%s of %s (expected a positive duration)", flagWatchIntervalLong, interval)
+	}
+
+	states := watchedFileStates(args.filenames)
+	pending := states
+	previous := watchCrunch(w, args, nil, color)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C:
+			current := watchedFileStates(args.filenames)
+			// files which are still being written are crunched once they did not change for a whole interval
+			stillChanging := len(changedFiles(args.filenames, pending, current)) > 0
+			pending = current
+			changed := changedFiles(args.filenames, states, current)
+			if stillChanging || len(changed) == 0 {
+				continue
+			}
+			states = current
+
+			_, err := fmt.Fprintf(w, "\n%s changed at %s\n", strings.Join(changed, ", "), time.Now().Format("15:04:05"))
+			if err != nil {
+				return err
+			}
+			if crunched := watchCrunch(w, args, previous, color); crunched != nil {
+				previous = crunched
+			}
+		}
+	}
+}
+
+// watchCrunch writes the time slots and the changes since the previous crunch. It returns nil if crunching failed.
+func watchCrunch(w io.Writer, args runArgs, previous *core.CrunchedOutput, color bool) *core.CrunchedOutput {
+	pipeline, result, err := processRedmineData(args)
+	if err != nil {
+		log.Errorf("%v", err)
+		return nil
+	}
+	crunched := result.Crunched
+
+	err = pipeline.Write(context.Background(), w, crunched)
+	if err != nil {
+		log.Errorf("%v", err)
+		return nil
+	}
+	if previous == nil {
+		return crunched
+	}
+
+	_, err = fmt.Fprintln(w, "Changes since the previous crunch:")
+	if err == nil {
+		err = writeHighlightedChanges(w, history.Diff(previous, crunched), color)
+	}
+	if err != nil {
+		log.Errorf("%v", err)
+	}
+
+	return crunched
+}
+
+// writeHighlightedChanges writes the changes and colors each line by the kind of change if color is set.
+func writeHighlightedChanges(w io.Writer, changes []history.Change, color bool) error {
+	if !color {
+		return history.WriteChanges(w, changes)
+	}
+
+	buffer := &bytes.Buffer{}
+	err := history.WriteChanges(buffer, changes)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(buffer)
+	for scanner.Scan() {
+		line := scanner.Text()
+		kind := strings.SplitN(line, " ", 2)[0]
+		if changeColor, ok := changeColors[kind]; ok {
+			line = changeColor + line + colorReset
+		}
+		_, err = fmt.Fprintln(w, line)
+		if err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+func watchedFileStates(filenames []string) map[string]fileState {
+	result := map[string]fileState{}
+	for _, filename := range filenames {
+		info, err := os.Stat(filename)
+		if err != nil {
+			result[filename] = fileState{}
+			continue
+		}
+		result[filename] = fileState{exists: true, size: info.Size(), modTime: info.ModTime()}
+	}
+
+	return result
+}
+
+// changedFiles returns the files whose state differs in the given order.
+func changedFiles(filenames []string, before, after map[string]fileState) []string {
+	result := []string{}
+	for _, filename := range filenames {
+		if !before[filename].equals(after[filename]) {
+			result = append(result, filename)
+		}
+	}
+
+	return result
+}
+
+// isTerminal returns true if the file is a terminal and not redirected into a file or a pipe.
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
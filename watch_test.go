@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"github.com/ppxl/sagemine/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a buffer which the watch loop and the test use at the same time.
+type syncBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	return sb.buffer.Write(p)
+}
+
+func (sb *syncBuffer) String() string {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	return sb.buffer.String()
+}
+
+func Test_doWatch(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "watch-")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "export.csv")
+	_ = ioutil.WriteFile(path, []byte(`Anforderungspipeline;2021-05-03;2021-05-04
+Pipeline A;7,50;6,00
+`), 0600)
+	args := runArgs{
+		lunchBreakInMin:  60,
+		filenames:        []string{path},
+		csvDelimiter:     ";",
+		decimalDelimiter: ",",
+	}
+
+	t.Run("should crunch again when the input changes", func(t *testing.T) {
+		buffer := &syncBuffer{}
+		stop := make(chan struct{})
+		done := make(chan error)
+		go func() {
+			done <- doWatch(buffer, args, 10*time.Millisecond, false, stop)
+		}()
+		require.Eventually(t, func() bool { return strings.Contains(buffer.String(), "2021-05-04") }, time.Second, 5*time.Millisecond)
+
+		// when
+		_ = ioutil.WriteFile(path, []byte(`Anforderungspipeline;2021-05-03;2021-05-04
+Pipeline A;7,50;5,00
+`), 0600)
+
+		// then
+		require.Eventually(t, func() bool { return strings.Contains(buffer.String(), "Changes since the previous crunch:") },
+			2*time.Second, 5*time.Millisecond)
+		close(stop)
+		require.NoError(t, <-done)
+		assert.Contains(t, buffer.String(), path+" changed at ")
+		assert.Contains(t, buffer.String(), "~ Pipeline A-joined  2021-05-04  08:00-12:00, 13:00-15:00 -> 08:00-12:00, 13:00-14:00\n")
+	})
+	t.Run("should keep watching after errors", func(t *testing.T) {
+		buffer := &syncBuffer{}
+		stop := make(chan struct{})
+		done := make(chan error)
+		broken := args
+		broken.filenames = []string{filepath.Join(dir, "broken.csv")}
+		_ = ioutil.WriteFile(broken.filenames[0], []byte(`Anforderungspipeline;2021-13-03
+Pipeline A;7,5
+`), 0600)
+		go func() {
+			done <- doWatch(buffer, broken, 10*time.Millisecond, false, stop)
+		}()
+
+		// when
+		// replace the file at once so that the watcher never reads a partially written file
+		fixed := filepath.Join(dir, "fixed.csv")
+		_ = ioutil.WriteFile(fixed, []byte(`Anforderungspipeline;2021-05-03
+Pipeline A;7,50
+`), 0600)
+		require.NoError(t, os.Rename(fixed, broken.filenames[0]))
+
+		// then
+		require.Eventually(t, func() bool { return strings.Contains(buffer.String(), "2021-05-03") }, 2*time.Second, 5*time.Millisecond)
+		close(stop)
+		require.NoError(t, <-done)
+		assert.NotContains(t, buffer.String(), "Changes since the previous crunch:")
+	})
+	t.Run("should fail without input files", func(t *testing.T) {
+		// when
+		err := doWatch(&syncBuffer{}, runArgs{}, time.Second, false, nil)
+
+		// then
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "--watch needs input files")
+	})
+}
+
+func Test_writeHighlightedChanges(t *testing.T) {
+	changes := []history.Change{
+		{Kind: history.ChangeAdded, Pipeline: "Pipeline B", Date: "2021-05-04", After: nil},
+	}
+
+	t.Run("should color the changes", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := writeHighlightedChanges(buffer, changes, true)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "\033[32m+ Pipeline B  2021-05-04  \033[0m\n", buffer.String())
+	})
+	t.Run("should not color that nothing changed", func(t *testing.T) {
+		buffer := &bytes.Buffer{}
+
+		// when
+		err := writeHighlightedChanges(buffer, nil, true)
+
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "no changes\n", buffer.String())
+	})
+}